@@ -1,20 +1,59 @@
 package main
 
 import (
+	"encoding/json"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
 
 	"APIGetaway/pkg/api"
+	"APIGetaway/shared/logging"
+	"APIGetaway/shared/tracing"
 )
 
+// конфигурация приложения
+type config struct {
+	CensorPolicies api.PolicyConfig `json:"censor_policies"`
+	Log            logging.Config   `json:"log"`
+	Trace          tracing.Config   `json:"trace"`
+}
+
 func main() {
+	// чтение и раскодирование файла конфигурации
+	b, err := os.ReadFile("./config.json")
+	if err != nil {
+		log.Fatal(err)
+	}
+	var config config
+	err = json.Unmarshal(b, &config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Журнал сервиса в stdout и файл с ротацией, SIGHUP открывает файл заново
+	logger, logFile, err := logging.Open("gateway", config.Log)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer logFile.Close()
+	slog.SetDefault(logger)
+
+	// Трассировка OpenTelemetry, экспортер можно заменить переменной OTEL_TRACES_EXPORTER
+	tracer, err := tracing.Open("gateway", config.Trace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer tracer.Close()
+
 	// Создаем новый API
-	api := api.New()
+	api := api.New(config.CensorPolicies)
 
 	// Запуск HTTP сервера
-	log.Println("Сервер запущен на http://localhost:8080")
-	err := http.ListenAndServe(":8080", api.Router())
+	slog.Info("Сервер запущен", "addr", ":8080")
+	err = http.ListenAndServe(":8080", api.Router())
 	if err != nil {
-		log.Fatalf("Ошибка при запуске сервера: %v", err)
+		slog.Error("Ошибка при запуске сервера", logging.Err(err))
+		os.Exit(1)
 	}
 }
@@ -2,12 +2,18 @@ package api
 
 import (
 	"APIGetaway/pkg/models"
+	"APIGetaway/shared/logging"
+	"APIGetaway/shared/metrics"
+	"APIGetaway/shared/problem"
+	"APIGetaway/shared/tracing"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -19,15 +25,43 @@ const (
 	commentsServiceURL = "http://localhost:8082" // адрес сервиса комментариев
 )
 
+// PolicyHeader - заголовок с именем политики цензуры для сервиса комментариев.
+// Политику выбирает только шлюз, заголовок из запроса клиента удаляется.
+const PolicyHeader = "X-Censor-Policy"
+
+// PolicyConfig - выбор политики цензуры по разделу новости.
+type PolicyConfig struct {
+	Default    string            `json:"default"`    // политика для разделов без явного сопоставления
+	Categories map[string]string `json:"categories"` // раздел новости -> имя политики
+}
+
+// policyFor возвращает имя политики для раздела новости.
+func (c PolicyConfig) policyFor(category string) string {
+	if policy, ok := c.Categories[category]; ok {
+		return policy
+	}
+	return c.Default
+}
+
 // API структура.
 type API struct {
-	r *chi.Mux
+	r          *chi.Mux
+	policies   PolicyConfig
+	metrics    *metrics.Metrics
+	upstream   *upstreamMetrics
+	categories *categoryCache // разделы новостей для выбора политики цензуры
 }
 
 // Конструктор API.
-func New() *API {
-	initLogger()
-	a := API{r: chi.NewRouter()}
+func New(policies PolicyConfig) *API {
+	a := API{
+		r:          chi.NewRouter(),
+		policies:   policies,
+		metrics:    metrics.New(),
+		upstream:   newUpstreamMetrics(),
+		categories: newCategoryCache(),
+	}
+	a.metrics.MustRegister(a.upstream.collectors()...)
 	a.endpoints()
 	return &a
 }
@@ -40,153 +74,105 @@ func (api *API) Router() *chi.Mux {
 
 // Регистрация методов API в маршрутизаторе запросов.
 func (api *API) endpoints() {
+	api.r.Use(tracing.Middleware)
 	api.r.Use(RequestIDMiddleware)
 	api.r.Use(LoggingMiddleware)
+	api.r.Use(api.metrics.Middleware)
 	api.r.Use(middleware.Recoverer)
+	api.r.Use(stripPolicyHeader)
+	api.r.NotFound(problem.NotFound)
+	api.r.MethodNotAllowed(problem.MethodNotAllowed)
 
 	api.r.Get("/news", api.getAllNews)
-	api.r.Get("/news/filter", api.filterNews)
+	api.r.Get("/news/filter", api.getAllNews) // прежний адрес поиска новостей
 	api.r.Get("/news/{id}", api.getNewsByID)
 	api.r.Post("/news/{id}/comment", api.addComment)
+	api.r.Get("/comments/search", api.searchComments)
+	api.r.Method(http.MethodGet, metrics.Path, api.metrics.Handler())
 }
 
-// Получить список всех новостей с пагинацией и фильтрацией.
+// Получить список новостей с пагинацией и поиском по заголовку (параметр s).
+// Ответ сервиса новостей оборачивается в конверт Page.
 func (api *API) getAllNews(w http.ResponseWriter, r *http.Request) {
-	// Получаем параметры для фильтрации и пагинации
-	searchTerm := r.URL.Query().Get("s") // Параметр для поиска
-	pageStr := r.URL.Query().Get("page") // Параметр для страницы
-	page := 1
-	if pageStr != "" {
-		var err error
-		page, err = strconv.Atoi(pageStr)
-		if err != nil || page <= 0 {
-			http.Error(w, "Invalid page parameter", http.StatusBadRequest)
-			return
-		}
+	page, pageSize, ok := pageParams(w, r)
+	if !ok {
+		return
 	}
-
-	// Формируем базовый URL для запроса
-	baseURL := fmt.Sprintf("%s/news", newsServiceURL)
-
-	// Добавляем параметры в строку запроса, если они заданы
-	url := baseURL
-	if searchTerm != "" {
-		url += fmt.Sprintf("?s=%s", searchTerm)
-	}
-	if page > 0 {
-		if searchTerm != "" {
-			url += fmt.Sprintf("&page=%d", page)
-		} else {
-			url += fmt.Sprintf("?page=%d", page)
-		}
+	query := url.Values{}
+	if s := r.URL.Query().Get("s"); s != "" {
+		query.Set("s", s)
 	}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("page_size", strconv.Itoa(pageSize))
 
 	// Создание запроса к новостному сервису
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/news?%s", newsServiceURL, query.Encode()), nil)
 	if err != nil {
-		http.Error(w, "Не удалось создать запрос для получения списка новостей", http.StatusInternalServerError)
+		problem.Write(w, r, unavailableProblem(r.Context(), newsService, err))
 		return
 	}
+	requestID, _ := r.Context().Value(RequestIDKey{}).(string)
+	req.Header.Set("request_id", requestID)
+	forwardLanguage(req, r)
 
-	// Инициализация HTTP-клиента
-	client := &http.Client{}
-	respChan := make(chan *http.Response, 1)
-	errChan := make(chan error, 1)
-
-	go func() {
-		resp, err := client.Do(req)
-		if err != nil {
-			errChan <- err
-			return
-		}
-		respChan <- resp
-	}()
-
-	select {
-	case err := <-errChan:
-		http.Error(w, fmt.Sprintf("Не удалось получить список новостей: %v", err), http.StatusInternalServerError)
+	resp, err := api.doUpstream(r, newsService, req)
+	if err != nil {
+		problem.Write(w, r, unavailableProblem(r.Context(), newsService, err))
 		return
-	case resp := <-respChan:
-		defer resp.Body.Close()
-		body, _ := ioutil.ReadAll(resp.Body)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(resp.StatusCode)
-		w.Write(body)
 	}
-}
-
-// Фильтрация новостей.
-func (api *API) filterNews(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query().Encode()
-
-	// Создание запроса к новостному сервису с параметрами фильтрации
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/news?%s", newsServiceURL, query), nil) // Изменение на /news
-	if err != nil {
-		http.Error(w, "Не удалось создать запрос для фильтрации новостей", http.StatusInternalServerError)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		problem.Write(w, r, upstreamProblem(r.Context(), newsService, resp))
 		return
 	}
 
-	// Добавляем request_id в заголовок запроса
-	requestID, _ := r.Context().Value(RequestIDKey{}).(string)
-	req.Header.Set("request_id", requestID)
-
-	// Инициализация HTTP-клиента
-	client := &http.Client{}
-	respChan := make(chan *http.Response, 1)
-	errChan := make(chan error, 1)
-
-	go func() {
-		resp, err := client.Do(req)
-		if err != nil {
-			errChan <- err
-			return
-		}
-		respChan <- resp
-	}()
-
-	select {
-	case err := <-errChan:
-		http.Error(w, fmt.Sprintf("Не удалось отфильтровать новости: %v", err), http.StatusInternalServerError)
+	news := []models.NewsShortDetailed{}
+	if err := json.NewDecoder(resp.Body).Decode(&news); err != nil {
+		problem.Write(w, r, badResponseProblem(r.Context(), newsService, err))
 		return
-	case resp := <-respChan:
-		defer resp.Body.Close()
-		body, _ := ioutil.ReadAll(resp.Body)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(resp.StatusCode)
-		w.Write(body)
 	}
+	// Без заголовка с общим числом считаем, что список заканчивается на этой странице
+	total, err := strconv.ParseInt(resp.Header.Get(totalCountHeader), 10, 64)
+	if err != nil {
+		total = int64((page-1)*pageSize + len(news))
+	}
+	writePage(w, newPage(r, news, page, pageSize, total))
 }
 
 // Получить детальную информацию о новости по ID.
 func (api *API) getNewsByID(w http.ResponseWriter, r *http.Request) {
 	newsID := chi.URLParam(r, "id")
+	requestID, _ := r.Context().Value(RequestIDKey{}).(string)
 
 	// Канал для получения результатов
-	newsCh := make(chan models.NewsFullDetailed)
-	commentsCh := make(chan []models.Comment)
-	errCh := make(chan error, 2) // Канал для ошибок
+	newsCh := make(chan models.NewsFullDetailed, 1)
+	commentsCh := make(chan []models.Comment, 1)
+	errCh := make(chan *problem.Problem, 2) // Канал для ошибок
 
 	// Функция для получения новости
 	go func() {
-		reqNews, err := http.NewRequest("GET", fmt.Sprintf("%s/news/%s", newsServiceURL, newsID), nil)
+		reqNews, err := http.NewRequest("GET", fmt.Sprintf("%s/news/%s", newsServiceURL, url.PathEscape(newsID)), nil)
 		if err != nil {
-			errCh <- fmt.Errorf("не удалось создать запрос для получения новости: %w", err)
+			errCh <- unavailableProblem(r.Context(), newsService, err)
 			return
 		}
-		requestID, _ := r.Context().Value(RequestIDKey{}).(string)
 		reqNews.Header.Set("request_id", requestID)
+		forwardLanguage(reqNews, r)
 
-		client := &http.Client{}
-		respNews, err := client.Do(reqNews)
-		if err != nil || respNews.StatusCode != http.StatusOK {
-			errCh <- fmt.Errorf("не удалось получить новость: %w", err)
+		respNews, err := api.doUpstream(r, newsService, reqNews)
+		if err != nil {
+			errCh <- unavailableProblem(r.Context(), newsService, err)
 			return
 		}
 		defer respNews.Body.Close()
+		if respNews.StatusCode != http.StatusOK {
+			errCh <- upstreamProblem(r.Context(), newsService, respNews)
+			return
+		}
 
 		var news models.NewsFullDetailed
 		if err := json.NewDecoder(respNews.Body).Decode(&news); err != nil {
-			errCh <- fmt.Errorf("ошибка при декодировании новости: %w", err)
+			errCh <- badResponseProblem(r.Context(), newsService, err)
 			return
 		}
 		newsCh <- news // Отправляем новость в канал
@@ -194,23 +180,28 @@ func (api *API) getNewsByID(w http.ResponseWriter, r *http.Request) {
 
 	// Функция для получения комментариев
 	go func() {
-		reqComments, err := http.NewRequest("GET", fmt.Sprintf("%s/comments?news_id=%s", commentsServiceURL, newsID), nil)
+		reqComments, err := http.NewRequest("GET", fmt.Sprintf("%s/comments?news_id=%s", commentsServiceURL, url.QueryEscape(newsID)), nil)
 		if err != nil {
-			errCh <- fmt.Errorf("не удалось создать запрос для получения комментариев: %w", err)
+			errCh <- unavailableProblem(r.Context(), commentsService, err)
 			return
 		}
+		reqComments.Header.Set("request_id", requestID)
+		forwardLanguage(reqComments, r)
 
-		client := &http.Client{}
-		respComments, err := client.Do(reqComments)
-		if err != nil || respComments.StatusCode != http.StatusOK {
-			errCh <- fmt.Errorf("не удалось получить комментарии: %w", err)
+		respComments, err := api.doUpstream(r, commentsService, reqComments)
+		if err != nil {
+			errCh <- unavailableProblem(r.Context(), commentsService, err)
 			return
 		}
 		defer respComments.Body.Close()
+		if respComments.StatusCode != http.StatusOK {
+			errCh <- upstreamProblem(r.Context(), commentsService, respComments)
+			return
+		}
 
 		var comments []models.Comment
 		if err := json.NewDecoder(respComments.Body).Decode(&comments); err != nil {
-			errCh <- fmt.Errorf("ошибка при декодировании комментариев: %w", err)
+			errCh <- badResponseProblem(r.Context(), commentsService, err)
 			return
 		}
 		commentsCh <- comments // Отправляем комментарии в канал
@@ -227,13 +218,13 @@ func (api *API) getNewsByID(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
 			json.NewEncoder(w).Encode(news)
-		case err := <-errCh:
+		case p := <-errCh:
 			// Ошибка при получении комментариев
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			problem.Write(w, r, p)
 		}
-	case err := <-errCh:
+	case p := <-errCh:
 		// Ошибка при получении новости
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		problem.Write(w, r, p)
 	}
 }
 
@@ -243,12 +234,15 @@ func (api *API) addComment(w http.ResponseWriter, r *http.Request) {
 	var comment models.Comment
 
 	if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
-		http.Error(w, "неверный формат запроса", http.StatusBadRequest)
+		problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "")
 		return
 	}
+	if comment.Author != "" {
+		logging.AddFields(r.Context(), logging.KeyUser, comment.Author)
+	}
 	id, err := strconv.Atoi(newsID) // Преобразование строки в int
 	if err != nil {
-		http.Error(w, "Invalid news ID", http.StatusBadRequest)
+		problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "format", "id")
 		return
 	}
 
@@ -258,45 +252,126 @@ func (api *API) addComment(w http.ResponseWriter, r *http.Request) {
 	// Мы повторно создаем тело запроса с нужным форматом
 	commentBody, err := json.Marshal(comment)
 	if err != nil {
-		http.Error(w, "Ошибка при обработке комментария", http.StatusInternalServerError)
+		problem.Error(w, r, http.StatusInternalServerError, problem.CodeInternal, "")
 		return
 	}
 
 	req, err := http.NewRequest("POST", fmt.Sprintf("%s/comments", commentsServiceURL), strings.NewReader(string(commentBody)))
 	if err != nil {
-		http.Error(w, "Не удалось создать запрос для добавления комментария", http.StatusInternalServerError)
+		problem.Write(w, r, unavailableProblem(r.Context(), commentsService, err))
 		return
 	}
 
 	// Добавляем request_id в заголовок запроса
 	requestID, _ := r.Context().Value(RequestIDKey{}).(string)
 	req.Header.Set("request_id", requestID)
+	forwardLanguage(req, r)
 	// Установка заголовков
 	req.Header.Set("Content-Type", "application/json")
+	if policy := api.policies.policyFor(api.newsCategory(r, newsID)); policy != "" {
+		req.Header.Set(PolicyHeader, policy)
+	}
 
-	// Инициализация HTTP-клиента
-	client := &http.Client{}
-	respChan := make(chan *http.Response, 1)
-	errChan := make(chan error, 1)
+	resp, err := api.doUpstream(r, commentsService, req)
+	if err != nil {
+		problem.Write(w, r, unavailableProblem(r.Context(), commentsService, err))
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		problem.Write(w, r, upstreamProblem(r.Context(), commentsService, resp))
+		return
+	}
+	body, _ := io.ReadAll(resp.Body)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.StatusCode)
+	w.Write(body)
+}
 
-	go func() {
-		resp, err := client.Do(req)
-		if err != nil {
-			errChan <- err
-			return
-		}
-		respChan <- resp
-	}()
+// Полнотекстовый поиск комментариев: параметры q и news_id передаются сервису
+// комментариев, ответ оборачивается в конверт Page.
+func (api *API) searchComments(w http.ResponseWriter, r *http.Request) {
+	page, pageSize, ok := pageParams(w, r)
+	if !ok {
+		return
+	}
+	query := url.Values{}
+	query.Set("q", r.URL.Query().Get("q"))
+	if newsID := r.URL.Query().Get("news_id"); newsID != "" {
+		query.Set("news_id", newsID)
+	}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("page_size", strconv.Itoa(pageSize))
 
-	select {
-	case err := <-errChan:
-		http.Error(w, fmt.Sprintf("Не удалось добавить комментарий: %v", err), http.StatusInternalServerError)
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/comments/search?%s", commentsServiceURL, query.Encode()), nil)
+	if err != nil {
+		problem.Write(w, r, unavailableProblem(r.Context(), commentsService, err))
+		return
+	}
+
+	// Добавляем request_id в заголовок запроса
+	requestID, _ := r.Context().Value(RequestIDKey{}).(string)
+	req.Header.Set("request_id", requestID)
+	forwardLanguage(req, r)
+
+	resp, err := api.doUpstream(r, commentsService, req)
+	if err != nil {
+		problem.Write(w, r, unavailableProblem(r.Context(), commentsService, err))
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		problem.Write(w, r, upstreamProblem(r.Context(), commentsService, resp))
+		return
+	}
+
+	var result struct {
+		Items json.RawMessage `json:"items"`
+		Total int64           `json:"total"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || result.Items == nil {
+		problem.Write(w, r, badResponseProblem(r.Context(), commentsService, fmt.Errorf("нет списка items: %v", err)))
 		return
-	case resp := <-respChan:
-		defer resp.Body.Close()
-		body, _ := ioutil.ReadAll(resp.Body)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(resp.StatusCode)
-		w.Write(body)
 	}
+	writePage(w, newPage(r, result.Items, page, pageSize, result.Total))
+}
+
+// stripPolicyHeader удаляет из запроса клиента заголовок выбора политики
+// цензуры, чтобы клиент не мог ослабить проверку своего комментария.
+func stripPolicyHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Del(PolicyHeader)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// newsCategory возвращает раздел новости. Раздел запрашивается у сервиса
+// новостей один раз и затем берется из кэша. Если новость получить не удалось,
+// возвращается пустая строка и применяется политика по умолчанию.
+func (api *API) newsCategory(r *http.Request, newsID string) string {
+	if category, ok := api.categories.get(newsID, time.Now()); ok {
+		return category
+	}
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/news/%s", newsServiceURL, url.PathEscape(newsID)), nil)
+	if err != nil {
+		return ""
+	}
+	requestID, _ := r.Context().Value(RequestIDKey{}).(string)
+	req.Header.Set("request_id", requestID)
+
+	resp, err := api.doUpstream(r, newsService, req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+
+	var news models.NewsFullDetailed
+	if err := json.NewDecoder(resp.Body).Decode(&news); err != nil {
+		return ""
+	}
+	api.categories.put(newsID, news.Category, time.Now())
+	return news.Category
 }
@@ -0,0 +1,27 @@
+package api
+
+import "testing"
+
+func TestPolicyConfig_policyFor(t *testing.T) {
+	cfg := PolicyConfig{
+		Default:    "default",
+		Categories: map[string]string{"politics": "strict", "sport": "relaxed"},
+	}
+
+	tests := []struct {
+		name     string
+		category string
+		want     string
+	}{
+		{name: "Раздел с отдельной политикой", category: "politics", want: "strict"},
+		{name: "Раздел без сопоставления", category: "science", want: "default"},
+		{name: "Раздел неизвестен", category: "", want: "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cfg.policyFor(tt.category); got != tt.want {
+				t.Errorf("Неверная политика: ожидается %q, получено %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// Параметры кэша разделов новостей
+const (
+	categoryTTL       = 10 * time.Minute // время, в течение которого раздел новости берется из кэша
+	categoryCacheSize = 10000            // наибольшее число новостей в кэше
+)
+
+// categoryEntry - раздел новости в кэше.
+type categoryEntry struct {
+	category string
+	expires  time.Time
+}
+
+// categoryCache хранит разделы новостей, чтобы при публикации комментария
+// не запрашивать новость у сервиса новостей каждый раз. Раздел новости
+// меняется редко, поэтому устаревание на время categoryTTL допустимо.
+type categoryCache struct {
+	mu      sync.Mutex
+	entries map[string]categoryEntry
+}
+
+func newCategoryCache() *categoryCache {
+	return &categoryCache{entries: make(map[string]categoryEntry)}
+}
+
+// get возвращает раздел новости, если он есть в кэше и не устарел.
+func (c *categoryCache) get(newsID string, now time.Time) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[newsID]
+	if !ok || now.After(e.expires) {
+		return "", false
+	}
+	return e.category, true
+}
+
+// put запоминает раздел новости. Если кэш заполнен, из него удаляются
+// устаревшие записи, а если их нет - все записи.
+func (c *categoryCache) put(newsID, category string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[newsID]; !ok && len(c.entries) >= categoryCacheSize {
+		for id, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, id)
+			}
+		}
+		if len(c.entries) >= categoryCacheSize {
+			c.entries = make(map[string]categoryEntry)
+		}
+	}
+	c.entries[newsID] = categoryEntry{category: category, expires: now.Add(categoryTTL)}
+}
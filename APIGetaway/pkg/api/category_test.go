@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCategoryCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := newCategoryCache()
+	c.put("1", "kids", now)
+	c.put("2", "", now)
+
+	tests := []struct {
+		name   string
+		newsID string
+		at     time.Time
+		want   string
+		wantOk bool
+	}{
+		{"Раздел из кэша", "1", now.Add(time.Minute), "kids", true},
+		{"Новость без раздела", "2", now, "", true},
+		{"Новости нет в кэше", "3", now, "", false},
+		{"Запись устарела", "1", now.Add(categoryTTL + time.Second), "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := c.get(tt.newsID, tt.at)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("get(%s) = %q, %v; ожидается %q, %v", tt.newsID, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+
+	// Заполненный кэш не растет дальше предельного размера
+	for i := 0; i < categoryCacheSize+10; i++ {
+		c.put(strconv.Itoa(i+10), "news", now)
+	}
+	if len(c.entries) > categoryCacheSize {
+		t.Errorf("Размер кэша %d превышает %d", len(c.entries), categoryCacheSize)
+	}
+}
+
+func TestStripPolicyHeader(t *testing.T) {
+	var got string
+	h := stripPolicyHeader(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(PolicyHeader)
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/news/1/comment", nil)
+	req.Header.Set(PolicyHeader, "relaxed")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if got != "" {
+		t.Errorf("Заголовок %s клиента не удален: %q", PolicyHeader, got)
+	}
+}
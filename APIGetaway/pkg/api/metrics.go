@@ -0,0 +1,55 @@
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Виды ошибок вызова сервиса в метрике upstream_errors_total
+const (
+	upstreamErrCanceled    = "canceled"     // запрос клиента завершен до ответа сервиса
+	upstreamErrUnavailable = "unavailable"  // сервис недоступен
+	upstreamErrServer      = "server_error" // сервис ответил статусом 5xx
+)
+
+// upstreamMetrics - метрики вызовов сервисов новостей и комментариев.
+type upstreamMetrics struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+// newUpstreamMetrics создает метрики вызовов сервисов.
+func newUpstreamMetrics() *upstreamMetrics {
+	return &upstreamMetrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "upstream_request_duration_seconds",
+			Help:    "Длительность вызовов сервисов по сервису, методу и статусу ответа.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"upstream", "method", "status"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "upstream_errors_total",
+			Help: "Число неудачных вызовов сервисов по сервису и виду ошибки.",
+		}, []string{"upstream", "kind"}),
+	}
+}
+
+// collectors возвращает метрики для регистрации в реестре.
+func (m *upstreamMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.duration, m.errors}
+}
+
+// observe учитывает вызов сервиса. status - 0, если ответ не получен.
+func (m *upstreamMetrics) observe(service, method string, status int, d time.Duration) {
+	label := strconv.Itoa(status)
+	if status == 0 {
+		label = "error"
+	}
+	m.duration.WithLabelValues(service, method, label).Observe(d.Seconds())
+}
+
+// fail учитывает ошибку вызова сервиса вида kind.
+func (m *upstreamMetrics) fail(service, kind string) {
+	m.errors.WithLabelValues(service, kind).Inc()
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestDoUpstreamMetrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	defer srv.Close()
+
+	api := New(PolicyConfig{})
+	for _, url := range []string{srv.URL + "/ok", srv.URL + "/fail", closed.URL} {
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		resp, err := api.doUpstream(httptest.NewRequest(http.MethodGet, "/news", nil), newsService, req)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}
+
+	tests := []struct {
+		name   string
+		metric float64
+		want   float64
+	}{
+		{"Ошибка сервиса", testutil.ToFloat64(api.upstream.errors.WithLabelValues(newsService, upstreamErrServer)), 1},
+		{"Сервис недоступен", testutil.ToFloat64(api.upstream.errors.WithLabelValues(newsService, upstreamErrUnavailable)), 1},
+		{"Отмененных вызовов нет", testutil.ToFloat64(api.upstream.errors.WithLabelValues(newsService, upstreamErrCanceled)), 0},
+	}
+	for _, tt := range tests {
+		if tt.metric != tt.want {
+			t.Errorf("%s: ожидается %v, получено %v", tt.name, tt.want, tt.metric)
+		}
+	}
+	if got := testutil.CollectAndCount(api.upstream.duration); got != 3 {
+		t.Errorf("Рядов длительности вызовов: ожидается 3, получено %d", got)
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"APIGetaway/shared/logging"
+	"APIGetaway/shared/tracing"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestLoggingMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	h, _ := logging.NewHandler(&buf, logging.Config{Level: "debug"})
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(h))
+	defer otel.SetTextMapPropagator(otel.GetTextMapPropagator())
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	r := chi.NewRouter()
+	r.Use(tracing.Middleware)
+	r.Use(RequestIDMiddleware)
+	r.Use(LoggingMiddleware)
+	r.Post("/news/{id}/comment", func(w http.ResponseWriter, r *http.Request) {
+		logging.AddFields(r.Context(), logging.KeyUser, "Иван")
+		upstreamLogger(r.Context(), commentsService).Debug("Вызов сервиса")
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/news/1/comment", nil)
+	req.Header.Set("request_id", "abc123")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	var records []map[string]interface{}
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var rec map[string]interface{}
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			t.Fatalf("Ошибка при распаковке JSON: %v", err)
+		}
+		records = append(records, rec)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Ожидается 2 записи, получено %d", len(records))
+	}
+
+	upstream, access := records[0], records[1]
+	if upstream[logging.KeyUpstream] != commentsService || upstream[logging.KeyRoute] != "/news/{id}/comment" {
+		t.Errorf("Неверные поля записи обработчика: %v", upstream)
+	}
+	want := map[string]interface{}{
+		logging.KeyRequestID: "abc123",
+		logging.KeyTraceID:   "4bf92f3577b34da6a3ce929d0e0e4736",
+		logging.KeyRoute:     "/news/{id}/comment",
+		logging.KeyPath:      "/news/1/comment",
+		logging.KeyMethod:    http.MethodPost,
+		logging.KeyStatus:    float64(http.StatusCreated),
+		logging.KeyUser:      "Иван",
+	}
+	for k, v := range want {
+		if access[k] != v {
+			t.Errorf("Поле %s: ожидается %v, получено %v", k, v, access[k])
+		}
+	}
+	if _, ok := access[logging.KeyDuration]; !ok {
+		t.Errorf("Нет поля %s", logging.KeyDuration)
+	}
+}
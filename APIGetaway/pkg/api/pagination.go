@@ -0,0 +1,100 @@
+package api
+
+import (
+	"APIGetaway/shared/problem"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// Размер страницы списков
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
+// MaxPage - наибольший номер страницы, ограничивает смещение выборки.
+const MaxPage = 10000
+
+// totalCountHeader - заголовок ответа сервиса новостей с общим числом найденных новостей.
+const totalCountHeader = "X-Total-Count"
+
+// Page - конверт постраничного ответа шлюза.
+type Page struct {
+	Items      interface{} `json:"items"`
+	Page       int         `json:"page"`
+	PageSize   int         `json:"page_size"`
+	Total      int64       `json:"total"`
+	TotalPages int         `json:"total_pages"`
+	Next       *string     `json:"next"` // ссылка на следующую страницу, null на последней
+	Prev       *string     `json:"prev"` // ссылка на предыдущую страницу, null на первой
+}
+
+// pageParams читает параметры page и page_size. При ошибке отправляет ответ 400 и возвращает false.
+func pageParams(w http.ResponseWriter, r *http.Request) (page, pageSize int, ok bool) {
+	page, ok = intParam(w, r, "page", 1, 1, MaxPage)
+	if !ok {
+		return 0, 0, false
+	}
+	pageSize, ok = intParam(w, r, "page_size", DefaultPageSize, 1, MaxPageSize)
+	return page, pageSize, ok
+}
+
+// intParam читает целый параметр запроса name в границах [lo, hi] (hi 0 - без верхней границы).
+// При ошибке отправляет ответ 400 и возвращает false.
+func intParam(w http.ResponseWriter, r *http.Request, name string, def, lo, hi int) (int, bool) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return def, true
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < lo || (hi > 0 && v > hi) {
+		if hi == 0 {
+			problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "min", name, lo)
+		} else {
+			problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "range", name, lo, hi)
+		}
+		return 0, false
+	}
+	return v, true
+}
+
+// newPage собирает конверт страницы. Ссылки на соседние страницы повторяют
+// исходный запрос r с другим номером страницы.
+func newPage(r *http.Request, items interface{}, page, pageSize int, total int64) Page {
+	p := Page{
+		Items:      items,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}
+	if page < p.TotalPages {
+		next := pageLink(r, page+1, pageSize)
+		p.Next = &next
+	}
+	if page > 1 {
+		// С последней существующей страницы, если запрошена страница за концом списка
+		prev := pageLink(r, min(page-1, max(p.TotalPages, 1)), pageSize)
+		p.Prev = &prev
+	}
+	return p
+}
+
+// pageLink возвращает путь запроса r с номером страницы page.
+func pageLink(r *http.Request, page, pageSize int) string {
+	q := r.URL.Query()
+	q.Set("page", strconv.Itoa(page))
+	q.Set("page_size", strconv.Itoa(pageSize))
+	return r.URL.Path + "?" + q.Encode()
+}
+
+// writePage отправляет конверт страницы в формате JSON.
+func writePage(w http.ResponseWriter, p Page) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false) // & в ссылках на страницы не экранируется
+	enc.Encode(p)
+}
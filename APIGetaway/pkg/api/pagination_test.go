@@ -0,0 +1,77 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewPage(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		page       int
+		pageSize   int
+		total      int64
+		totalPages int
+		next       string
+		prev       string
+	}{
+		{name: "Первая страница", url: "/news?s=go", page: 1, pageSize: 10, total: 25, totalPages: 3, next: "/news?page=2&page_size=10&s=go"},
+		{name: "Средняя страница", url: "/news?page=2&page_size=10", page: 2, pageSize: 10, total: 25, totalPages: 3,
+			next: "/news?page=3&page_size=10", prev: "/news?page=1&page_size=10"},
+		{name: "Последняя страница", url: "/news?page=3", page: 3, pageSize: 10, total: 25, totalPages: 3, prev: "/news?page=2&page_size=10"},
+		{name: "За концом списка", url: "/news?page=7", page: 7, pageSize: 10, total: 25, totalPages: 3, prev: "/news?page=3&page_size=10"},
+		{name: "Пустой список", url: "/news", page: 1, pageSize: 10, total: 0, totalPages: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPage(httptest.NewRequest(http.MethodGet, tt.url, nil), []int{}, tt.page, tt.pageSize, tt.total)
+			if p.TotalPages != tt.totalPages {
+				t.Errorf("Неверное число страниц: ожидается %d, получено %d", tt.totalPages, p.TotalPages)
+			}
+			if got := link(p.Next); got != tt.next {
+				t.Errorf("Неверная ссылка на следующую страницу: ожидается %q, получено %q", tt.next, got)
+			}
+			if got := link(p.Prev); got != tt.prev {
+				t.Errorf("Неверная ссылка на предыдущую страницу: ожидается %q, получено %q", tt.prev, got)
+			}
+		})
+	}
+}
+
+func TestPageParams(t *testing.T) {
+	tests := []struct {
+		url            string
+		expectedStatus int
+		page, pageSize int
+	}{
+		{url: "/news", expectedStatus: http.StatusOK, page: 1, pageSize: DefaultPageSize},
+		{url: "/news?page=2&page_size=100", expectedStatus: http.StatusOK, page: 2, pageSize: 100},
+		{url: "/news?page_size=101", expectedStatus: http.StatusBadRequest},
+		{url: "/news?page_size=0", expectedStatus: http.StatusBadRequest},
+		{url: "/news?page=-1", expectedStatus: http.StatusBadRequest},
+		{url: "/news?page=10000&page_size=100", expectedStatus: http.StatusOK, page: MaxPage, pageSize: 100},
+		{url: "/news?page=10001", expectedStatus: http.StatusBadRequest},
+		{url: "/news?page=9223372036854775807&page_size=100", expectedStatus: http.StatusBadRequest},
+		{url: "/news?page=abc", expectedStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		page, pageSize, ok := pageParams(rr, httptest.NewRequest(http.MethodGet, tt.url, nil))
+		if ok != (tt.expectedStatus == http.StatusOK) || rr.Code != tt.expectedStatus {
+			t.Errorf("%s: неверный статус %d", tt.url, rr.Code)
+			continue
+		}
+		if ok && (page != tt.page || pageSize != tt.pageSize) {
+			t.Errorf("%s: ожидается страница %d по %d, получено %d по %d", tt.url, tt.page, tt.pageSize, page, pageSize)
+		}
+	}
+}
+
+func link(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
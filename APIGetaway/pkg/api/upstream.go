@@ -0,0 +1,125 @@
+package api
+
+import (
+	"APIGetaway/shared/logging"
+	"APIGetaway/shared/problem"
+	"APIGetaway/shared/tracing"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Имена сервисов в поле upstream журнала и ключах сообщений об ошибках
+const (
+	newsService     = "news"
+	commentsService = "comments"
+)
+
+// upstreamClient - клиент запросов к сервисам, передающий им контекст трассировки.
+var upstreamClient = &http.Client{Transport: tracing.Transport(nil)}
+
+// maxProblemSize - наибольший размер ответа сервиса с ошибкой, который разбирает шлюз.
+const maxProblemSize = 64 << 10
+
+// unavailableProblem описывает ошибку соединения с сервисом.
+// Текст ошибки Go записывается только в журнал.
+func unavailableProblem(ctx context.Context, service string, err error) *problem.Problem {
+	if ctx.Err() != nil {
+		// Запрос клиента завершен или отменен, ответ сервиса больше не нужен
+		upstreamLogger(ctx, service).Debug("Запрос к сервису отменен", logging.Err(err))
+	} else {
+		upstreamLogger(ctx, service).Error("Ошибка запроса к сервису", logging.Err(err))
+	}
+	return problem.New(http.StatusBadGateway, problem.CodeUpstreamUnavailable, service)
+}
+
+// badResponseProblem описывает ответ сервиса, который шлюз не смог разобрать.
+func badResponseProblem(ctx context.Context, service string, err error) *problem.Problem {
+	upstreamLogger(ctx, service).Error("Некорректный ответ сервиса", logging.Err(err))
+	return problem.New(http.StatusBadGateway, problem.CodeUpstreamError, service+"_response")
+}
+
+// upstreamProblem переводит ответ сервиса с ошибкой в ошибку шлюза.
+// Ошибки запроса (4xx) в формате problem+json передаются клиенту с кодом,
+// описанием и дополнительными полями сервиса. Внутренние ошибки сервиса (5xx)
+// и ответы в другом формате заменяются общим описанием без подробностей,
+// а подробности записываются в журнал.
+func upstreamProblem(ctx context.Context, service string, resp *http.Response) *problem.Problem {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxProblemSize))
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		upstreamLogger(ctx, service).Error("Ошибка сервиса",
+			logging.KeyStatus, resp.StatusCode, "body", strings.TrimSpace(string(body)))
+		return problem.New(http.StatusBadGateway, problem.CodeUpstreamError, service)
+	}
+
+	if resp.StatusCode < http.StatusBadRequest {
+		return badResponseProblem(ctx, service, fmt.Errorf("неожиданный статус %d", resp.StatusCode))
+	}
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), problem.ContentType) {
+		var p problem.Problem
+		if err := json.Unmarshal(body, &p); err == nil && p.Code != "" {
+			// Описание уже на языке клиента: шлюз передает сервису Accept-Language
+			out := problem.New(resp.StatusCode, p.Code, "")
+			out.Detail = p.Detail
+			out.Extensions = p.Extensions
+			return out
+		}
+	}
+
+	upstreamLogger(ctx, service).Warn("Ошибка сервиса",
+		logging.KeyStatus, resp.StatusCode, "body", strings.TrimSpace(string(body)))
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return problem.New(resp.StatusCode, problem.CodeNotFound, "")
+	case http.StatusConflict:
+		return problem.New(resp.StatusCode, problem.CodeConflict, "")
+	default:
+		return problem.New(resp.StatusCode, problem.CodeInvalidRequest, "")
+	}
+}
+
+// forwardLanguage передает сервису язык клиента из запроса r, чтобы описания
+// ошибок сервиса были на том же языке, что и ответы шлюза.
+func forwardLanguage(req, r *http.Request) {
+	if lang := r.Header.Get("Accept-Language"); lang != "" {
+		req.Header.Set("Accept-Language", lang)
+	}
+}
+
+// doUpstream выполняет запрос req к сервису service в контексте запроса клиента r,
+// записывает вызов в журнал запроса на уровне debug и учитывает его в метриках.
+func (api *API) doUpstream(r *http.Request, service string, req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := upstreamClient.Do(req.WithContext(r.Context()))
+	if err != nil {
+		api.upstream.observe(service, req.Method, 0, time.Since(start))
+		if r.Context().Err() != nil {
+			api.upstream.fail(service, upstreamErrCanceled)
+		} else {
+			api.upstream.fail(service, upstreamErrUnavailable)
+		}
+		return nil, err
+	}
+	api.upstream.observe(service, req.Method, resp.StatusCode, time.Since(start))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		api.upstream.fail(service, upstreamErrServer)
+	}
+	upstreamLogger(r.Context(), service).Debug("Вызов сервиса",
+		logging.KeyMethod, req.Method,
+		logging.KeyPath, req.URL.Path,
+		logging.KeyStatus, resp.StatusCode,
+		logging.KeyDuration, durationMs(time.Since(start)))
+	return resp, nil
+}
+
+// upstreamLogger возвращает журнал запроса с именем сервиса service.
+func upstreamLogger(ctx context.Context, service string) *slog.Logger {
+	return requestLogger(ctx).With(logging.KeyUpstream, service)
+}
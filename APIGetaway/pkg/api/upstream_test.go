@@ -0,0 +1,85 @@
+package api
+
+import (
+	"APIGetaway/shared/problem"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUpstreamProblem(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		lang        string
+		wantStatus  int
+		wantCode    string
+		wantDetail  string
+		wantVerdict bool
+	}{
+		{
+			name: "Ошибка запроса передается клиенту", status: http.StatusBadRequest, contentType: problem.ContentType,
+			body:       `{"type":"urn:apigetaway:problem:comment_rejected","title":"x","status":400,"detail":"Комментарий содержит недопустимые слова","code":"comment_rejected","instance":"/comments","request_id":"abc","verdict":{"decision":"deny"}}`,
+			wantStatus: http.StatusBadRequest, wantCode: problem.CodeCommentRejected, wantDetail: "Комментарий содержит недопустимые слова", wantVerdict: true,
+		},
+		{
+			name: "Внутренняя ошибка скрывается", status: http.StatusInternalServerError, contentType: problem.ContentType,
+			body:       `{"status":500,"code":"internal_error","detail":"pq: password authentication failed"}`,
+			wantStatus: http.StatusBadGateway, wantCode: problem.CodeUpstreamError, wantDetail: "Сервис новостей не смог обработать запрос",
+		},
+		{
+			name: "Описание шлюза на языке клиента", status: http.StatusBadGateway, contentType: "text/html", lang: "en-US,en;q=0.9",
+			body:       "<html>bad gateway</html>",
+			wantStatus: http.StatusBadGateway, wantCode: problem.CodeUpstreamError, wantDetail: "News service failed to process the request",
+		},
+		{
+			name: "Ответ не в формате problem+json", status: http.StatusNotFound, contentType: "text/plain",
+			body:       "404 page not found at /internal/path",
+			wantStatus: http.StatusNotFound, wantCode: problem.CodeNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := httptest.NewRecorder()
+			upstream.Header().Set("Content-Type", tt.contentType)
+			upstream.WriteHeader(tt.status)
+			upstream.WriteString(tt.body)
+
+			p := upstreamProblem(context.Background(), newsService, upstream.Result())
+
+			rr := httptest.NewRecorder()
+			rr.Header().Set("request_id", "gw-1")
+			req := httptest.NewRequest(http.MethodGet, "/news/1", nil)
+			req.Header.Set("Accept-Language", tt.lang)
+			problem.Write(rr, req, p)
+
+			if rr.Code != tt.wantStatus || rr.Header().Get("Content-Type") != problem.ContentType {
+				t.Fatalf("Неверный ответ: статус %d, тип %q", rr.Code, rr.Header().Get("Content-Type"))
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatalf("Ошибка при распаковке JSON: %v", err)
+			}
+			if got["code"] != tt.wantCode || got["type"] != problem.TypePrefix+tt.wantCode {
+				t.Errorf("Неверный код: ожидается %s, получено %v (%v)", tt.wantCode, got["code"], got["type"])
+			}
+			if detail, _ := got["detail"].(string); detail != tt.wantDetail {
+				t.Errorf("Неверное описание: ожидается %q, получено %q", tt.wantDetail, detail)
+			}
+			if got["instance"] != "/news/1" || got["request_id"] != "gw-1" {
+				t.Errorf("Ожидаются путь и request_id шлюза, получено %v и %v", got["instance"], got["request_id"])
+			}
+			if _, ok := got["verdict"]; ok != tt.wantVerdict {
+				t.Errorf("Поле verdict: ожидается %v, получено %v", tt.wantVerdict, ok)
+			}
+			if strings.Contains(rr.Body.String(), "pq:") || strings.Contains(rr.Body.String(), "/internal/") {
+				t.Errorf("Ответ раскрывает внутренние подробности: %s", rr.Body.String())
+			}
+		})
+	}
+}
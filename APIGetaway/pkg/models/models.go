@@ -16,6 +16,7 @@ type NewsFullDetailed struct {
 	Title    string    `json:"title"`
 	Content  string    `json:"content"`
 	Author   string    `json:"author"`
+	Category string    `json:"category"` // раздел новости
 	Comments []Comment `json:"comments"` // Добавляем поле для комментариев
 }
 
@@ -23,6 +24,8 @@ type Comment struct {
 	ID        int64     `json:"id"`
 	NewsID    int64     `json:"news_id"`
 	ParentID  *int64    `json:"parent_id,omitempty"`
+	Author    string    `json:"author,omitempty"`
 	Text      string    `json:"text"`
+	Status    string    `json:"status,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 }
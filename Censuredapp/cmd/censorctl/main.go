@@ -0,0 +1,197 @@
+// Утилита censorctl для обучения и оценки классификатора токсичности
+// и проверки правил цензуры на золотом корпусе.
+//
+// Использование:
+//
+//	censorctl train -data ../data/toxicity.csv -out ../data/toxicity_model.json
+//	censorctl eval -model ../data/toxicity_model.json -data ../data/toxicity.csv
+//	censorctl corpus -config ../cmd/config.json -baseline old_config.json -max-regression 0.02
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"APIGetaway/pkg/censor"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "train":
+		train(os.Args[2:])
+	case "eval":
+		eval(os.Args[2:])
+	case "corpus":
+		corpus(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "использование: censorctl <train|eval|corpus> [флаги]")
+	os.Exit(2)
+}
+
+// train обучает классификатор на размеченной выборке и сохраняет модель.
+func train(args []string) {
+	fs := flag.NewFlagSet("train", flag.ExitOnError)
+	data := fs.String("data", "../data/toxicity.csv", "CSV с колонками text,label")
+	out := fs.String("out", "../data/toxicity_model.json", "файл для сохранения модели")
+	testRatio := fs.Float64("test-ratio", 0.2, "доля выборки для оценки качества")
+	threshold := fs.Float64("threshold", 0.5, "порог вероятности токсичности")
+	fs.Parse(args)
+
+	samples := readSamples(*data)
+	trainSet, testSet := split(samples, *testRatio)
+	nb := censor.TrainNaiveBayes(trainSet)
+	if len(testSet) > 0 {
+		log.Printf("Оценка на отложенной выборке (%d примеров):", len(testSet))
+		report(censor.Evaluate(nb, testSet, *threshold))
+	}
+
+	// Итоговая модель обучается на всей выборке
+	nb = censor.TrainNaiveBayes(samples)
+	if err := nb.Save(*out); err != nil {
+		log.Fatalf("Ошибка сохранения модели: %v", err)
+	}
+	log.Printf("Модель обучена на %d примерах и сохранена в %s", len(samples), *out)
+}
+
+// eval оценивает сохраненную модель на размеченной выборке.
+func eval(args []string) {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	model := fs.String("model", "../data/toxicity_model.json", "файл модели")
+	data := fs.String("data", "../data/toxicity.csv", "CSV с колонками text,label")
+	threshold := fs.Float64("threshold", 0.5, "порог вероятности токсичности")
+	fs.Parse(args)
+
+	nb, err := censor.LoadNaiveBayes(*model)
+	if err != nil {
+		log.Fatal(err)
+	}
+	samples := readSamples(*data)
+	log.Printf("Оценка на %d примерах:", len(samples))
+	report(censor.Evaluate(nb, samples, *threshold))
+}
+
+// corpus прогоняет золотой корпус по правилам из конфигурации и, если задана
+// базовая конфигурация, завершается с ошибкой при падении точности, полноты
+// или доли точно совпавших решений какой-либо политики больше допустимого.
+func corpus(args []string) {
+	fs := flag.NewFlagSet("corpus", flag.ExitOnError)
+	configPath := fs.String("config", "../cmd/config.json", "проверяемая конфигурация сервиса")
+	baselinePath := fs.String("baseline", "", "базовая конфигурация для сравнения")
+	corpusPath := fs.String("corpus", "../pkg/censor/testdata/corpus.jsonl", "золотой корпус в формате JSON Lines")
+	maxRegression := fs.Float64("max-regression", 0.02, "допустимое падение точности, полноты и доли точных решений")
+	verbose := fs.Bool("v", false, "выводить расхождения с ожидаемыми решениями")
+	fs.Parse(args)
+
+	f, err := os.Open(*corpusPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	entries, err := censor.ReadCorpus(f)
+	f.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("Проверяемая конфигурация %s:", *configPath)
+	reports := runCorpus(*configPath, entries, *verbose)
+	if *baselinePath == "" {
+		return
+	}
+	log.Printf("Базовая конфигурация %s:", *baselinePath)
+	baseline := runCorpus(*baselinePath, entries, false)
+
+	failed := false
+	for _, rep := range reports {
+		base, ok := baseline[rep.Policy]
+		if !ok {
+			continue
+		}
+		dp := base.Metrics.Precision() - rep.Metrics.Precision()
+		dr := base.Metrics.Recall() - rep.Metrics.Recall()
+		de := base.ExactRate() - rep.ExactRate()
+		if dp > *maxRegression || dr > *maxRegression || de > *maxRegression {
+			log.Printf("Регрессия политики %s: точность %.3f -> %.3f, полнота %.3f -> %.3f, точных решений %.3f -> %.3f",
+				rep.Policy, base.Metrics.Precision(), rep.Metrics.Precision(), base.Metrics.Recall(), rep.Metrics.Recall(),
+				base.ExactRate(), rep.ExactRate())
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+	log.Println("Регрессий не обнаружено")
+}
+
+// runCorpus прогоняет корпус по конфигурации и печатает отчет по политикам.
+func runCorpus(configPath string, entries []censor.CorpusEntry, verbose bool) map[string]*censor.CorpusReport {
+	cfg, err := censor.LoadConfig(configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	c, err := censor.New(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	reports, err := censor.RunCorpus(c, entries)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	res := make(map[string]*censor.CorpusReport, len(reports))
+	for _, rep := range reports {
+		res[rep.Policy] = rep
+		log.Printf("  %s: precision=%.3f recall=%.3f exact=%d/%d", rep.Policy,
+			rep.Metrics.Precision(), rep.Metrics.Recall(), rep.Exact, rep.Total())
+		if verbose {
+			for _, f := range rep.Failures {
+				log.Printf("    ожидалось %s, получено %s: %q", f.Entry.Expected, f.Got, f.Entry.Text)
+			}
+		}
+	}
+	return res
+}
+
+func readSamples(path string) []censor.Sample {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	samples, err := censor.ReadSamples(f)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return samples
+}
+
+// split детерминированно делит выборку: каждый n-й пример попадает в тестовую часть.
+func split(samples []censor.Sample, testRatio float64) (train, test []censor.Sample) {
+	if testRatio <= 0 {
+		return samples, nil
+	}
+	step := int(1 / testRatio)
+	for i, s := range samples {
+		if step > 0 && i%step == step-1 {
+			test = append(test, s)
+			continue
+		}
+		train = append(train, s)
+	}
+	return train, test
+}
+
+func report(m censor.Metrics) {
+	log.Printf("  accuracy=%.3f precision=%.3f recall=%.3f f1=%.3f (tp=%d fp=%d tn=%d fn=%d)",
+		m.Accuracy(), m.Precision(), m.Recall(), m.F1(),
+		m.TruePositive, m.FalsePositive, m.TrueNegative, m.FalseNegative)
+}
@@ -1,19 +1,113 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"io/fs"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 
 	"APIGetaway/pkg/api"
+	"APIGetaway/pkg/audit"
+	"APIGetaway/pkg/censor"
+	"APIGetaway/shared/logging"
+	"APIGetaway/shared/tracing"
 )
 
+// configPath - файл конфигурации сервиса.
+const configPath = "./config.json"
+
+// defaultGRPCAddr - адрес gRPC-сервера, если файла конфигурации нет.
+const defaultGRPCAddr = ":9083"
+
+// конфигурация приложения
+type config struct {
+	Censor censor.Config   `json:"censor"`
+	Batch  api.BatchConfig `json:"batch"`
+	Audit  audit.Config    `json:"audit"` // журнал решений, отключен если не задан файл
+	GRPC   struct {
+		Addr string `json:"addr"` // адрес gRPC-сервера, пустой - сервер не запускается
+	} `json:"grpc"`
+	Log   logging.Config `json:"log"`
+	Trace tracing.Config `json:"trace"`
+}
+
 func main() {
+	// чтение и раскодирование файла конфигурации
+	var config config
+	b, err := os.ReadFile(configPath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		// без файла конфигурации сервис работает со встроенными настройками
+		log.Println("Файл config.json не найден, используются настройки по умолчанию")
+		config.GRPC.Addr = defaultGRPCAddr
+	case err != nil:
+		log.Fatal(err)
+	default:
+		if err := json.Unmarshal(b, &config); err != nil {
+			log.Fatal(err)
+		}
+	}
+	// Журнал сервиса в stdout и файл с ротацией, SIGHUP открывает файл заново
+	logger, logFile, err := logging.Open("censor", config.Log)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer logFile.Close()
+	slog.SetDefault(logger)
+
+	// Трассировка OpenTelemetry, экспортер можно заменить переменной OTEL_TRACES_EXPORTER
+	tracer, err := tracing.Open("censor", config.Trace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer tracer.Close()
+
+	// если политики не описаны, используется встроенный список запрещенных слов
+	if len(config.Censor.Policies) == 0 {
+		config.Censor = censor.DefaultConfig()
+	}
+	// путь к модели классификатора отсчитывается от каталога файла конфигурации
+	config.Censor.ResolvePaths(filepath.Dir(configPath))
+
+	c, err := censor.New(config.Censor)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var auditLog *audit.Log
+	if config.Audit.Path != "" {
+		auditLog, err = audit.Open(config.Audit)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer auditLog.Close()
+	}
+
 	// Создаем новый API
-	api := api.New()
+	api := api.New(c, config.Batch, auditLog)
+
+	// Запуск gRPC сервера рядом с HTTP
+	if config.GRPC.Addr != "" {
+		lis, err := net.Listen("tcp", config.GRPC.Addr)
+		if err != nil {
+			log.Fatalf("Ошибка при запуске gRPC сервера: %v", err)
+		}
+		go func() {
+			slog.Info("gRPC сервер запущен", "addr", config.GRPC.Addr)
+			if err := api.GRPCServer().Serve(lis); err != nil {
+				log.Fatalf("Ошибка gRPC сервера: %v", err)
+			}
+		}()
+	}
 
 	// Запуск HTTP сервера
-	log.Println("Сервер запущен на http://localhost:8083")
-	err := http.ListenAndServe(":8083", api.Router())
+	slog.Info("Сервер запущен", "addr", ":8083")
+	err = http.ListenAndServe(":8083", api.Router())
 	if err != nil {
 		log.Fatalf("Ошибка при запуске сервера: %v", err)
 	}
@@ -2,27 +2,50 @@ package api
 
 import (
 	"encoding/json"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
-	"strings"
+	"time"
+
+	"APIGetaway/pkg/audit"
+	"APIGetaway/pkg/censor"
+	"APIGetaway/shared/logging"
+	"APIGetaway/shared/metrics"
+	"APIGetaway/shared/problem"
+	"APIGetaway/shared/tracing"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
-// Список запрещенных слов
-var forbiddenWords = []string{"qwerty", "йцукен", "zxvbnm"}
+// PolicyHeader - заголовок для выбора политики цензуры.
+const PolicyHeader = "X-Censor-Policy"
 
 // API приложения GoNews.
 type API struct {
-	r *chi.Mux
+	r      *chi.Mux
+	censor *censor.Censor
+	batch  BatchConfig
+	audit  *audit.Log // журнал решений, nil - журнал отключен
+
+	metrics   *metrics.Metrics
+	decisions *prometheus.CounterVec // решения цензора по политике и решению
 }
 
 // Конструктор API.
-func New() *API {
-	// Инициализируем логгер
-	initLogger()
-
-	a := API{r: chi.NewRouter()}
+func New(c *censor.Censor, batch BatchConfig, auditLog *audit.Log) *API {
+	if batch.Workers <= 0 {
+		batch.Workers = defaultBatchWorkers
+	}
+	if batch.MaxItems <= 0 {
+		batch.MaxItems = defaultBatchMaxItems
+	}
+	a := API{r: chi.NewRouter(), censor: c, batch: batch, audit: auditLog, metrics: metrics.New()}
+	a.decisions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "censor_verdicts_total",
+		Help: "Число решений цензора по политике и решению.",
+	}, []string{"policy", "decision"})
+	a.metrics.MustRegister(a.decisions)
 	a.endpoints()
 	return &a
 }
@@ -36,48 +59,160 @@ func (api *API) Router() *chi.Mux {
 // Регистрация методов API в маршрутизаторе запросов.
 func (api *API) endpoints() {
 
-	// Middleware для логирования запросов
+	// Middleware для трассировки и логирования запросов
+	api.r.Use(tracing.Middleware)
 	api.r.Use(RequestIDMiddleware) // Добавляем middleware для request_id
 	api.r.Use(LoggingMiddleware)   // Добавляем middleware для логирования
+	api.r.Use(api.metrics.Middleware)
+
+	// Ошибки маршрутизации тоже возвращаются в формате problem+json
+	api.r.NotFound(problem.NotFound)
+	api.r.MethodNotAllowed(problem.MethodNotAllowed)
 
 	api.r.Post("/comments", api.Censored)
+	api.r.Post("/check", api.Check)
+	api.r.Post("/check/batch", api.CheckBatch)
+	api.r.Get("/policies", api.policies)
+	api.r.Get("/verdicts/{request_id}", api.verdicts)
+	api.r.Post("/rules/test", api.testRules)
+	api.r.Method(http.MethodGet, metrics.Path, api.metrics.Handler())
 }
 
 // Censored - обработчик POST запроса для добавления комментария.
+// Возвращает вердикт цензора; если комментарий отклонен, статус ответа 400
+// и ошибка comment_rejected с вердиктом в поле verdict.
 func (api *API) Censored(w http.ResponseWriter, r *http.Request) {
+	verdict, ok := api.check(w, r)
+	if !ok {
+		return
+	}
+
+	if verdict.Decision == censor.DecisionDeny {
+		// Комментарий не прошел валидацию
+		problem.Write(w, r, problem.New(http.StatusBadRequest, problem.CodeCommentRejected, "words").With("verdict", verdict))
+		return
+	}
+	writeJSON(w, http.StatusOK, verdict)
+}
+
+// Check - обработчик POST запроса для получения вердикта по тексту.
+// В отличие от Censored всегда отвечает 200, решение передается в теле ответа.
+func (api *API) Check(w http.ResponseWriter, r *http.Request) {
+	verdict, ok := api.check(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, verdict)
+}
+
+// testRules - обработчик POST запроса для проверки правил модераторами.
+// Возвращает вердикт со сработавшими и подавленными совпадениями,
+// решение не записывается в журнал.
+func (api *API) testRules(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Text   string `json:"text"`
+		Policy string `json:"policy"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "")
+		return
+	}
+	verdict, err := api.censor.Check(req.Policy, req.Text)
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, problem.CodeUnknownPolicy, "name", req.Policy)
+		return
+	}
+	writeJSON(w, http.StatusOK, verdict)
+}
+
+// policies - обработчик GET запроса для получения списка политик.
+func (api *API) policies(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, api.censor.Policies())
+}
+
+// check разбирает запрос и проверяет текст комментария.
+// Если запрос некорректен, ответ уже отправлен и возвращается false.
+func (api *API) check(w http.ResponseWriter, r *http.Request) (censor.Verdict, bool) {
 	// Чтение тела запроса (комментарий)
 	var requestData map[string]string
 	err := json.NewDecoder(r.Body).Decode(&requestData)
 	if err != nil {
-		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
-		return
+		problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "")
+		return censor.Verdict{}, false
 	}
 
 	// Проверка на наличие текста комментария
 	text, exists := requestData["text"]
 	if !exists || text == "" {
-		http.Error(w, "Текст комментария не может быть пустым", http.StatusBadRequest)
-		return
+		problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "text")
+		return censor.Verdict{}, false
 	}
 
-	// Валидация: проверка на наличие запрещенных слов
-	if isValidComment(text) {
-		// Успешная валидация, возвращаем статус 200
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("Комментарий добавлен"))
-	} else {
-		// Комментарий не прошел валидацию
-		http.Error(w, "Комментарий содержит недопустимые слова", http.StatusBadRequest)
+	// Политика выбирается полем policy или заголовком X-Censor-Policy
+	policy := requestData["policy"]
+	if policy == "" {
+		policy = r.Header.Get(PolicyHeader)
+	}
+	requestID, _ := r.Context().Value(RequestIDKey{}).(string)
+	verdict, err := api.checkText(requestID, "", policy, text)
+	if errors.Is(err, censor.ErrUnknownPolicy) {
+		problem.Error(w, r, http.StatusBadRequest, problem.CodeUnknownPolicy, "name", policy)
+		return censor.Verdict{}, false
+	}
+	if verdict.Decision != censor.DecisionAllow {
+		requestLogger(r.Context()).Info("Комментарий не допущен",
+			"decision", verdict.Decision, "policy", verdict.Policy, "rules", verdict.Rules)
 	}
+	return verdict, true
 }
 
-// Функция для проверки комментария на наличие запрещенных слов
-func isValidComment(text string) bool {
-	for _, word := range forbiddenWords {
-		if strings.Contains(strings.ToLower(text), word) {
-			log.Printf("Комментарий содержит недопустимое слово: %s", word)
-			return false
+// checkText проверяет текст, учитывает решение в метриках и записывает его в журнал.
+func (api *API) checkText(requestID, itemID, policy, text string) (censor.Verdict, error) {
+	start := time.Now()
+	verdict, err := api.censor.Check(policy, text)
+	if err != nil {
+		return verdict, err
+	}
+	api.decisions.WithLabelValues(verdict.Policy, string(verdict.Decision)).Inc()
+	if api.audit == nil {
+		return verdict, nil
+	}
+	rec := audit.NewRecord(requestID, itemID, text, verdict, time.Since(start))
+	if err := api.audit.Write(rec); err != nil {
+		slog.Error("Не удалось записать решение в журнал", logging.KeyRequestID, requestID, logging.Err(err))
+	}
+	return verdict, nil
+}
+
+// verdicts - обработчик GET запроса для получения решений, принятых в рамках запроса.
+func (api *API) verdicts(w http.ResponseWriter, r *http.Request) {
+	if api.audit == nil {
+		problem.Error(w, r, http.StatusNotFound, problem.CodeNotFound, "audit")
+		return
+	}
+	records, err := api.audit.ByRequestID(chi.URLParam(r, "request_id"))
+	if err != nil {
+		requestLogger(r.Context()).Error("Не удалось прочитать журнал решений", logging.Err(err))
+		problem.Error(w, r, http.StatusInternalServerError, problem.CodeInternal, "")
+		return
+	}
+	if len(records) == 0 {
+		// Решения, вышедшие за пределы поиска, остаются только в файлах журнала:
+		// отдельное описание показывает, что запрос мог быть, но поиск его уже не охватывает
+		if since := api.audit.RetainedSince(); !since.IsZero() {
+			problem.Write(w, r, problem.New(http.StatusNotFound, problem.CodeNotFound, "verdicts_expired",
+				since.Format(time.RFC3339)).With("retained_since", since))
+			return
 		}
+		problem.Error(w, r, http.StatusNotFound, problem.CodeNotFound, "verdicts")
+		return
 	}
-	return true
+	writeJSON(w, http.StatusOK, records)
+}
+
+// writeJSON отправляет ответ в формате JSON с указанным статусом.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
 }
@@ -1,95 +1,399 @@
-// // API приложения GoNews.
-
 package api
 
-// import (
-// 	"GoNews/pkg/storage"
-// 	"encoding/json"
-// 	"net/http"
-// 	"net/http/httptest"
-// 	"testing"
-// )
-
-// // MockDB представляет собой моки для базы данных.
-// type MockDB struct{}
-
-// // News возвращает фиксированный набор новостей для тестирования.
-// func (mdb *MockDB) News(n int) ([]storage.Post, error) {
-// 	if n <= 0 {
-// 		return []storage.Post{}, nil // Возвращаем пустой срез для невалидного n
-// 	}
-// 	return []storage.Post{
-// 		{Title: "Test News 1", Content: "Content for test news 1"},
-// 		{Title: "Test News 2", Content: "Content for test news 2"},
-// 	}, nil
-// }
-
-// // StoreNews реализует метод интерфейса, но не делает ничего для моков.
-// func (mdb *MockDB) StoreNews(news []storage.Post) error {
-// 	// Можно ничего не делать или возвращать nil, так как мы тестируем только чтение
-// 	return nil
-// }
-// func TestAPI_posts(t *testing.T) {
-// 	// Инициализируем API с моками базы данных
-// 	mockDB := &MockDB{}
-// 	api := New(mockDB)
-
-// 	tests := []struct {
-// 		name              string
-// 		url               string
-// 		expectedStatus    int
-// 		expectedNewsCount int
-// 	}{
-// 		{
-// 			name:              "Valid request with n=2",
-// 			url:               "/news/2",
-// 			expectedStatus:    http.StatusOK,
-// 			expectedNewsCount: 2,
-// 		},
-// 		{
-// 			name:              "Valid request with n=0",
-// 			url:               "/news/0",
-// 			expectedStatus:    http.StatusOK,
-// 			expectedNewsCount: 0, // Ожидаем пустой массив
-// 		},
-// 		{
-// 			name:              "Invalid request with n=-1",
-// 			url:               "/news/-1",
-// 			expectedStatus:    http.StatusOK,
-// 			expectedNewsCount: 0, // Ожидаем пустой массив
-// 		},
-// 		{
-// 			name:              "Invalid request with n=not-a-number",
-// 			url:               "/news/not-a-number",
-// 			expectedStatus:    http.StatusBadRequest,
-// 			expectedNewsCount: 0,
-// 		},
-// 	}
-
-// 	for _, tt := range tests {
-// 		t.Run(tt.name, func(t *testing.T) {
-// 			// Создаем новый HTTP-запрос
-// 			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
-// 			rec := httptest.NewRecorder()
-
-// 			// Вызываем обработчик
-// 			api.Router().ServeHTTP(rec, req)
-
-// 			// Проверяем статус-код ответа
-// 			if status := rec.Code; status != tt.expectedStatus {
-// 				t.Errorf("Неверный статус-код: ожидается %v, получен %v", tt.expectedStatus, status)
-// 			}
-
-// 			// Проверяем содержимое ответа, только если ожидается статус 200
-// 			if tt.expectedStatus == http.StatusOK {
-// 				var news []storage.Post
-// 				if err := json.Unmarshal(rec.Body.Bytes(), &news); err != nil {
-// 					t.Fatalf("Ошибка при распаковке JSON: %v", err)
-// 				}
-// 				if len(news) != tt.expectedNewsCount {
-// 					t.Errorf("Ожидалось %v новостей, получено %v", tt.expectedNewsCount, len(news))
-// 				}
-// 			}
-// 		})
-// 	}
-// }
+import (
+	"APIGetaway/pkg/audit"
+	"APIGetaway/pkg/censor"
+	"APIGetaway/shared/problem"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestAPI создает API со встроенным словарем и журналом решений во временном каталоге.
+func newTestAPI(t *testing.T) *API {
+	t.Helper()
+	c, err := censor.New(censor.DefaultConfig())
+	if err != nil {
+		t.Fatalf("Ошибка создания цензора: %v", err)
+	}
+	auditLog, err := audit.Open(audit.Config{Path: t.TempDir() + "/verdicts.log"})
+	if err != nil {
+		t.Fatalf("Ошибка открытия журнала: %v", err)
+	}
+	t.Cleanup(func() { auditLog.Close() })
+	return New(c, BatchConfig{MaxItems: 3}, auditLog)
+}
+
+func TestAPI_check(t *testing.T) {
+	api := newTestAPI(t)
+
+	tests := []struct {
+		name           string
+		url            string
+		header         string
+		body           string
+		expectedStatus int
+		expectedResult censor.Decision
+		expectedCode   string // код ошибки problem+json
+	}{
+		{name: "Допустимый комментарий", url: "/comments", body: `{"text": "Отличная статья"}`, expectedStatus: http.StatusOK, expectedResult: censor.DecisionAllow},
+		{name: "Недопустимый комментарий", url: "/comments", body: `{"text": "qwerty"}`, expectedStatus: http.StatusBadRequest, expectedResult: censor.DecisionDeny, expectedCode: problem.CodeCommentRejected},
+		{name: "Вердикт без ошибки", url: "/check", body: `{"text": "qwerty"}`, expectedStatus: http.StatusOK, expectedResult: censor.DecisionDeny},
+		{name: "Пустой текст", url: "/check", body: `{"text": ""}`, expectedStatus: http.StatusBadRequest, expectedCode: problem.CodeInvalidParameter},
+		{name: "Неверный формат", url: "/check", body: `not json`, expectedStatus: http.StatusBadRequest, expectedCode: problem.CodeInvalidRequest},
+		{name: "Политика в теле запроса", url: "/check", body: `{"text": "qwerty", "policy": "default"}`, expectedStatus: http.StatusOK, expectedResult: censor.DecisionDeny},
+		{name: "Политика в заголовке", url: "/check", header: "default", body: `{"text": "qwerty"}`, expectedStatus: http.StatusOK, expectedResult: censor.DecisionDeny},
+		{name: "Неизвестная политика", url: "/check", body: `{"text": "qwerty", "policy": "unknown"}`, expectedStatus: http.StatusBadRequest, expectedCode: problem.CodeUnknownPolicy},
+		{name: "Неизвестная политика в заголовке", url: "/check", header: "unknown", body: `{"text": "qwerty"}`, expectedStatus: http.StatusBadRequest, expectedCode: problem.CodeUnknownPolicy},
+		{name: "Неизвестный путь", url: "/unknown", body: `{}`, expectedStatus: http.StatusNotFound, expectedCode: problem.CodeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+			if tt.header != "" {
+				req.Header.Set(PolicyHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			api.Router().ServeHTTP(rec, req)
+
+			if status := rec.Code; status != tt.expectedStatus {
+				t.Errorf("Неверный статус-код: ожидается %v, получен %v", tt.expectedStatus, status)
+			}
+			var v censor.Verdict
+			if tt.expectedCode != "" {
+				// Ошибка в формате problem+json, вердикт отклоненного комментария в поле verdict
+				var p struct {
+					Code    string          `json:"code"`
+					Status  int             `json:"status"`
+					Verdict *censor.Verdict `json:"verdict"`
+				}
+				if ct := rec.Header().Get("Content-Type"); ct != problem.ContentType {
+					t.Errorf("Неверный тип ответа об ошибке: %q", ct)
+				}
+				if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+					t.Fatalf("Ошибка при распаковке JSON: %v", err)
+				}
+				if p.Code != tt.expectedCode || p.Status != tt.expectedStatus {
+					t.Errorf("Неверная ошибка: ожидается %s (%d), получено %s (%d)", tt.expectedCode, tt.expectedStatus, p.Code, p.Status)
+				}
+				if p.Verdict != nil {
+					v = *p.Verdict
+				}
+			} else if tt.expectedResult != "" {
+				if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+					t.Fatalf("Ошибка при распаковке JSON: %v", err)
+				}
+			}
+			if tt.expectedResult == "" {
+				return
+			}
+			if v.Decision != tt.expectedResult {
+				t.Errorf("Неверное решение: ожидается %v, получено %v", tt.expectedResult, v.Decision)
+			}
+		})
+	}
+}
+
+func TestAPI_policies(t *testing.T) {
+	api := newTestAPI(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/policies", nil)
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Неверный статус-код: ожидается %v, получен %v", http.StatusOK, rec.Code)
+	}
+	var names []string
+	if err := json.Unmarshal(rec.Body.Bytes(), &names); err != nil {
+		t.Fatalf("Ошибка при распаковке JSON: %v", err)
+	}
+	if len(names) != 1 || names[0] != "default" {
+		t.Errorf("Неверный список политик: %v", names)
+	}
+}
+
+func TestAPI_checkBatch(t *testing.T) {
+	api := newTestAPI(t)
+
+	body := `{"items": [{"id": "a", "text": "qwerty"}, {"id": "b", "text": "привет"}, {"id": "c", "text": ""}]}`
+	req := httptest.NewRequest(http.MethodPost, "/check/batch", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Неверный статус-код: ожидается %v, получен %v", http.StatusOK, rec.Code)
+	}
+
+	var resp struct {
+		Results []batchResult `json:"results"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Ошибка при распаковке JSON: %v", err)
+	}
+	if len(resp.Results) != 3 {
+		t.Fatalf("Ожидалось 3 результата, получено %v", len(resp.Results))
+	}
+	if r := resp.Results[0]; r.ID != "a" || r.Verdict == nil || r.Verdict.Decision != censor.DecisionDeny {
+		t.Errorf("Неверный результат для a: %+v", r)
+	}
+	if r := resp.Results[1]; r.ID != "b" || r.Verdict == nil || r.Verdict.Decision != censor.DecisionAllow {
+		t.Errorf("Неверный результат для b: %+v", r)
+	}
+	if r := resp.Results[2]; r.ID != "c" || r.Error == "" {
+		t.Errorf("Ожидалась ошибка для c: %+v", r)
+	}
+}
+
+func TestAPI_checkBatch_errors(t *testing.T) {
+	api := newTestAPI(t)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "Слишком много элементов", body: `{"items": [{"text": "1"}, {"text": "2"}, {"text": "3"}, {"text": "4"}]}`, expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "Неверный формат", body: `not json`, expectedStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			api.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/check/batch", strings.NewReader(tt.body)))
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Неверный статус-код: ожидается %v, получен %v", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
+
+// postStream отправляет NDJSON-запрос на сервер и возвращает результаты по идентификаторам.
+// Длина тела не передается, запрос отправляется частями (chunked), как поток клиента.
+func postStream(t *testing.T, url string, body io.Reader) map[string]batchResult {
+	t.Helper()
+	resp, err := http.Post(url+"/check/batch", ndjsonContentType, struct{ io.Reader }{body})
+	if err != nil {
+		t.Fatalf("Ошибка запроса: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Неверный статус-код: %v", resp.StatusCode)
+	}
+	results := map[string]batchResult{}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var r batchResult
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("Ошибка при распаковке строки: %v", err)
+		}
+		results[r.ID] = r
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Ошибка чтения ответа: %v", err)
+	}
+	return results
+}
+
+// TestAPI_checkBatchStream проверяет потоковую пакетную проверку на настоящем
+// сервере HTTP/1.1: результаты отправляются, пока сервер еще читает запрос.
+func TestAPI_checkBatchStream(t *testing.T) {
+	srv := httptest.NewServer(newTestAPI(t).Router())
+	defer srv.Close()
+
+	t.Run("Разбор строк", func(t *testing.T) {
+		body := "{\"id\": \"a\", \"text\": \"qwerty\"}\n{broken\n\n{\"id\": \"c\", \"text\": \"привет\", \"policy\": \"unknown\"}\n"
+		results := postStream(t, srv.URL, strings.NewReader(body))
+		if len(results) != 3 {
+			t.Fatalf("Ожидалось 3 результата, получено %v", len(results))
+		}
+		if results["line:2"].Error == "" {
+			t.Errorf("Ожидалась ошибка для неверной строки: %+v", results["line:2"])
+		}
+		if v := results["a"].Verdict; v == nil || v.Decision != censor.DecisionDeny {
+			t.Errorf("Неверный результат для a: %+v", results["a"])
+		}
+		if r := results["c"]; r.Verdict != nil || r.Error == "" {
+			t.Errorf("Ожидалась ошибка неизвестной политики для c: %+v", r)
+		}
+	})
+
+	t.Run("Запрос больше буфера сервера", func(t *testing.T) {
+		const n = 20000
+		var sb strings.Builder
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&sb, "{\"id\": \"%d\", \"text\": \"Комментарий номер %d к новости\"}\n", i, i)
+		}
+		results := postStream(t, srv.URL, strings.NewReader(sb.String()))
+		if len(results) != n {
+			t.Fatalf("Ожидалось %d результатов, получено %d", n, len(results))
+		}
+		for id, r := range results {
+			if r.Error != "" || r.Verdict == nil {
+				t.Fatalf("Неверный результат %s: %+v", id, r)
+			}
+		}
+	})
+
+	t.Run("Слишком длинная строка", func(t *testing.T) {
+		body := "{\"id\": \"a\", \"text\": \"привет\"}\n" +
+			"{\"id\": \"b\", \"text\": \"" + strings.Repeat("x", maxNDJSONLine) + "\"}\n" +
+			"{\"id\": \"c\", \"text\": \"привет\"}\n"
+		results := postStream(t, srv.URL, strings.NewReader(body))
+		if results["a"].Verdict == nil {
+			t.Errorf("Нет результата для строки до ошибки: %+v", results["a"])
+		}
+		if r, ok := results["line:2"]; !ok || !strings.Contains(r.Error, "Строка 2") {
+			t.Errorf("Ожидалась ошибка длинной строки, получено %+v", results)
+		}
+		if _, ok := results["c"]; ok {
+			t.Errorf("Строки после ошибки не должны проверяться")
+		}
+	})
+}
+
+func TestAPI_verdicts(t *testing.T) {
+	api := newTestAPI(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/check", strings.NewReader(`{"text": "qwerty"}`))
+	req.Header.Set("request_id", "req123")
+	api.Router().ServeHTTP(httptest.NewRecorder(), req)
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{name: "Существующий запрос", url: "/verdicts/req123", expectedStatus: http.StatusOK, expectedCount: 1},
+		{name: "Неизвестный запрос", url: "/verdicts/unknown", expectedStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			api.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Неверный статус-код: ожидается %v, получен %v", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var records []audit.Record
+			if err := json.Unmarshal(rec.Body.Bytes(), &records); err != nil {
+				t.Fatalf("Ошибка при распаковке JSON: %v", err)
+			}
+			if len(records) != tt.expectedCount {
+				t.Fatalf("Ожидалось %v записей, получено %v", tt.expectedCount, len(records))
+			}
+			if records[0].Decision != censor.DecisionDeny || records[0].Rules[0] != "word-qwerty" {
+				t.Errorf("Неверная запись журнала: %+v", records[0])
+			}
+		})
+	}
+}
+
+func TestAPI_verdicts_expired(t *testing.T) {
+	c, err := censor.New(censor.DefaultConfig())
+	if err != nil {
+		t.Fatalf("Ошибка создания цензора: %v", err)
+	}
+	auditLog, err := audit.Open(audit.Config{Path: t.TempDir() + "/verdicts.log", MaxRecords: 1})
+	if err != nil {
+		t.Fatalf("Ошибка открытия журнала: %v", err)
+	}
+	defer auditLog.Close()
+	api := New(c, BatchConfig{}, auditLog)
+
+	for _, id := range []string{"req1", "req2"} {
+		req := httptest.NewRequest(http.MethodPost, "/check", strings.NewReader(`{"text": "qwerty"}`))
+		req.Header.Set("request_id", id)
+		api.Router().ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Решение req1 вытеснено из поиска решением req2 и осталось только в файле журнала
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/verdicts/req1", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("Неверный статус-код: ожидается %v, получен %v", http.StatusNotFound, rec.Code)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Ошибка при распаковке JSON: %v", err)
+	}
+	if body["code"] != problem.CodeNotFound || body["retained_since"] == nil {
+		t.Errorf("Ожидается ответ с полем retained_since, получено %v", body)
+	}
+}
+
+func TestAPI_testRules(t *testing.T) {
+	cfg := censor.DefaultConfig()
+	cfg.Allowlist = []string{"qwertyuiop"}
+	c, err := censor.New(cfg)
+	if err != nil {
+		t.Fatalf("Ошибка создания цензора: %v", err)
+	}
+	api := New(c, BatchConfig{}, nil)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		wantSuppressed int
+	}{
+		{name: "Подавленное совпадение", body: `{"text": "клавиши qwertyuiop"}`, expectedStatus: http.StatusOK, wantSuppressed: 1},
+		{name: "Без подавленных совпадений", body: `{"text": "qwerty"}`, expectedStatus: http.StatusOK},
+		{name: "Неизвестная политика", body: `{"text": "qwerty", "policy": "unknown"}`, expectedStatus: http.StatusBadRequest},
+		{name: "Неверный формат", body: `not json`, expectedStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			api.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/rules/test", strings.NewReader(tt.body)))
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Неверный статус-код: ожидается %v, получен %v", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var v censor.Verdict
+			if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+				t.Fatalf("Ошибка при распаковке JSON: %v", err)
+			}
+			if len(v.Suppressed) != tt.wantSuppressed {
+				t.Errorf("Неверные подавленные совпадения: %+v", v.Suppressed)
+			}
+		})
+	}
+}
+
+func TestAPI_localizedErrors(t *testing.T) {
+	api := newTestAPI(t)
+
+	tests := []struct {
+		lang           string
+		body           string
+		expectedDetail string
+	}{
+		{lang: "", body: `{"text": "qwerty"}`, expectedDetail: "Комментарий содержит недопустимые слова"},
+		{lang: "en-US,en;q=0.9,ru;q=0.8", body: `{"text": "qwerty"}`, expectedDetail: "The comment contains prohibited words"},
+		{lang: "en", body: `{"text": "текст", "policy": "nope"}`, expectedDetail: `Unknown censorship policy "nope"`},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/comments", strings.NewReader(tt.body))
+		req.Header.Set("Accept-Language", tt.lang)
+		rec := httptest.NewRecorder()
+		api.Router().ServeHTTP(rec, req)
+
+		var p problem.Problem
+		if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+			t.Fatalf("Ошибка при распаковке JSON: %v", err)
+		}
+		if p.Detail != tt.expectedDetail {
+			t.Errorf("%q: ожидается описание %q, получено %q", tt.lang, tt.expectedDetail, p.Detail)
+		}
+	}
+}
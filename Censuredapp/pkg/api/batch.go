@@ -0,0 +1,230 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"APIGetaway/pkg/censor"
+	"APIGetaway/shared/logging"
+	"APIGetaway/shared/problem"
+)
+
+// Параметры пакетной проверки по умолчанию
+const (
+	defaultBatchWorkers  = 4
+	defaultBatchMaxItems = 1000
+	maxNDJSONLine        = 1 << 20 // максимальная длина строки NDJSON
+)
+
+// ndjsonContentType - тип содержимого для потоковой пакетной проверки.
+const ndjsonContentType = "application/x-ndjson"
+
+// BatchConfig - параметры пакетной проверки.
+type BatchConfig struct {
+	Workers  int `json:"workers"`   // число параллельных обработчиков
+	MaxItems int `json:"max_items"` // максимальное число текстов в JSON-запросе
+}
+
+// batchItem - текст для пакетной проверки.
+type batchItem struct {
+	ID     string `json:"id"` // идентификатор, назначенный клиентом
+	Text   string `json:"text"`
+	Policy string `json:"policy,omitempty"`
+
+	index int    // порядковый номер в запросе
+	err   string // ключ сообщения об ошибке разбора элемента
+}
+
+// batchResult - результат проверки одного текста.
+type batchResult struct {
+	ID      string          `json:"id"`
+	Verdict *censor.Verdict `json:"verdict,omitempty"`
+	Error   string          `json:"error,omitempty"`
+
+	index int
+}
+
+// batchRequest - тело JSON-запроса пакетной проверки.
+type batchRequest struct {
+	Policy string      `json:"policy"` // политика для элементов без собственной политики
+	Items  []batchItem `json:"items"`
+}
+
+// CheckBatch - обработчик POST запроса для пакетной проверки текстов.
+// Принимает JSON {"policy": ..., "items": [...]} и возвращает {"results": [...]}
+// в порядке элементов запроса, либо NDJSON (по одному элементу в строке),
+// и тогда результаты передаются потоком по мере готовности.
+func (api *API) CheckBatch(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), ndjsonContentType) {
+		api.checkBatchStream(w, r)
+		return
+	}
+
+	var req batchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "")
+		return
+	}
+	if len(req.Items) > api.batch.MaxItems {
+		problem.Error(w, r, http.StatusRequestEntityTooLarge, problem.CodeTooManyItems, "max", api.batch.MaxItems)
+		return
+	}
+	if req.Policy == "" {
+		req.Policy = r.Header.Get(PolicyHeader)
+	}
+
+	jobs := make(chan batchItem)
+	go func() {
+		defer close(jobs)
+		for i, item := range req.Items {
+			item.index = i
+			if item.Policy == "" {
+				item.Policy = req.Policy
+			}
+			select {
+			case jobs <- item:
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}()
+
+	results := make([]batchResult, len(req.Items))
+	for res := range api.runBatch(r.Context(), problem.Lang(r), jobs) {
+		results[res.index] = res
+	}
+	if r.Context().Err() != nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"results": results})
+}
+
+// checkBatchStream обрабатывает пакетную проверку в формате NDJSON.
+// Результаты отправляются клиенту, пока он еще передает строки запроса.
+// Если запрос не удалось дочитать (слишком длинная строка, обрыв соединения),
+// последней строкой ответа передается результат с описанием ошибки.
+func (api *API) checkBatchStream(w http.ResponseWriter, r *http.Request) {
+	defaultPolicy := r.Header.Get(PolicyHeader)
+	lang := problem.Lang(r)
+
+	// Сервер HTTP/1.1 перестает читать тело запроса после начала ответа,
+	// если не включен полнодуплексный режим. В HTTP/2 режим не нужен
+	// и включение возвращает ошибку http.ErrNotSupported.
+	rc := http.NewResponseController(w)
+	_ = rc.EnableFullDuplex()
+
+	var (
+		line    int   // номер последней прочитанной строки
+		scanErr error // ошибка чтения тела запроса
+	)
+	jobs := make(chan batchItem)
+	go func() {
+		defer close(jobs)
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+		for scanner.Scan() {
+			line++
+			if strings.TrimSpace(scanner.Text()) == "" {
+				continue
+			}
+			var item batchItem
+			if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+				item = batchItem{err: "invalid_request.item"}
+			}
+			if item.ID == "" {
+				item.ID = fmt.Sprintf("line:%d", line)
+			}
+			if item.Policy == "" {
+				item.Policy = defaultPolicy
+			}
+			select {
+			case jobs <- item:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		scanErr = scanner.Err()
+	}()
+
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	for res := range api.runBatch(r.Context(), lang, jobs) {
+		if err := enc.Encode(res); err != nil {
+			// Клиент отключился, дочитываем результаты, чтобы завершить обработчики
+			continue
+		}
+		rc.Flush()
+	}
+
+	// Канал результатов закрыт после закрытия jobs, поэтому line и scanErr
+	// уже не изменяются
+	if scanErr == nil {
+		return
+	}
+	res := batchResult{ID: fmt.Sprintf("line:%d", line+1)}
+	if errors.Is(scanErr, bufio.ErrTooLong) {
+		res.Error = problem.Message(lang, "invalid_request.line_too_long", line+1, maxNDJSONLine)
+	} else {
+		res.Error = problem.Message(lang, "invalid_request.body", line)
+	}
+	requestLogger(r.Context()).Warn("Ошибка чтения пакетного запроса",
+		"line", line+1, logging.Err(scanErr))
+	enc.Encode(res)
+	rc.Flush()
+}
+
+// runBatch проверяет тексты из jobs ограниченным числом обработчиков.
+// Ошибки элементов описываются на языке lang.
+// Канал результатов закрывается после обработки всех элементов.
+func (api *API) runBatch(ctx context.Context, lang string, jobs <-chan batchItem) <-chan batchResult {
+	requestID, _ := ctx.Value(RequestIDKey{}).(string)
+	results := make(chan batchResult)
+	var wg sync.WaitGroup
+	for i := 0; i < api.batch.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range jobs {
+				select {
+				case results <- api.checkItem(requestID, lang, item):
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+// checkItem проверяет один элемент пакета.
+func (api *API) checkItem(requestID, lang string, item batchItem) batchResult {
+	res := batchResult{ID: item.ID, index: item.index}
+	switch {
+	case item.err != "":
+		res.Error = problem.Message(lang, item.err)
+	case item.Text == "":
+		res.Error = problem.Message(lang, "invalid_parameter.text")
+	default:
+		verdict, err := api.checkText(requestID, item.ID, item.Policy, item.Text)
+		if errors.Is(err, censor.ErrUnknownPolicy) {
+			res.Error = problem.Message(lang, "unknown_policy.name", item.Policy)
+			break
+		}
+		if err != nil {
+			res.Error = err.Error()
+			break
+		}
+		res.Verdict = &verdict
+	}
+	return res
+}
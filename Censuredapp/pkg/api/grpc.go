@@ -0,0 +1,269 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"APIGetaway/pkg/censor"
+	"APIGetaway/pkg/censorpb"
+	"APIGetaway/shared/logging"
+	"APIGetaway/shared/problem"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// MetadataRequestID - ключ метаданных gRPC с идентификатором запроса.
+const MetadataRequestID = "request_id"
+
+// MetadataLanguage - ключ метаданных gRPC с предпочтительным языком сообщений, как Accept-Language.
+const MetadataLanguage = "accept-language"
+
+// grpcServer реализует сервис Censor поверх тех же проверок, что и HTTP API.
+type grpcServer struct {
+	censorpb.UnimplementedCensorServer
+	api *API
+}
+
+// GRPCServer возвращает gRPC-сервер с зарегистрированным сервисом Censor.
+func (api *API) GRPCServer() *grpc.Server {
+	s := grpc.NewServer(
+		// Спаны вызовов продолжают трассу клиента из метаданных traceparent
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(api.unaryInterceptor),
+		grpc.ChainStreamInterceptor(api.streamInterceptor),
+	)
+	censorpb.RegisterCensorServer(s, &grpcServer{api: api})
+	return s
+}
+
+// Check проверяет один текст.
+func (s *grpcServer) Check(ctx context.Context, req *censorpb.CheckRequest) (*censorpb.Verdict, error) {
+	if req.GetText() == "" {
+		return nil, status.Error(codes.InvalidArgument, problem.Message(grpcLang(ctx), "invalid_parameter.text"))
+	}
+	requestID, _ := ctx.Value(RequestIDKey{}).(string)
+	verdict, err := s.api.checkText(requestID, "", req.GetPolicy(), req.GetText())
+	if errors.Is(err, censor.ErrUnknownPolicy) {
+		return nil, status.Error(codes.InvalidArgument, problem.Message(grpcLang(ctx), "unknown_policy.name", req.GetPolicy()))
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return toProtoVerdict(verdict), nil
+}
+
+// CheckBatch проверяет набор текстов, результаты возвращаются в порядке запроса.
+func (s *grpcServer) CheckBatch(ctx context.Context, req *censorpb.CheckBatchRequest) (*censorpb.CheckBatchResponse, error) {
+	if len(req.GetItems()) > s.api.batch.MaxItems {
+		return nil, status.Error(codes.InvalidArgument, problem.Message(grpcLang(ctx), "too_many_items.max", s.api.batch.MaxItems))
+	}
+
+	jobs := make(chan batchItem)
+	go func() {
+		defer close(jobs)
+		for i, item := range req.GetItems() {
+			bi := fromProtoItem(item)
+			bi.index = i
+			if bi.Policy == "" {
+				bi.Policy = req.GetPolicy()
+			}
+			select {
+			case jobs <- bi:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	results := make([]*censorpb.CheckResult, len(req.GetItems()))
+	for res := range s.api.runBatch(ctx, grpcLang(ctx), jobs) {
+		results[res.index] = toProtoResult(res)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	return &censorpb.CheckBatchResponse{Results: results}, nil
+}
+
+// CheckStream проверяет поток текстов, результаты отправляются по мере готовности.
+func (s *grpcServer) CheckStream(stream censorpb.Censor_CheckStreamServer) error {
+	ctx := stream.Context()
+
+	var recvErr error
+	jobs := make(chan batchItem)
+	go func() {
+		defer close(jobs)
+		for n := 1; ; n++ {
+			item, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				recvErr = err
+				return
+			}
+			bi := fromProtoItem(item)
+			if bi.ID == "" {
+				bi.ID = fmt.Sprintf("item:%d", n)
+			}
+			select {
+			case jobs <- bi:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var sendErr error
+	for res := range s.api.runBatch(ctx, grpcLang(ctx), jobs) {
+		if sendErr != nil {
+			// Клиент отключился, дочитываем результаты, чтобы завершить обработчики
+			continue
+		}
+		sendErr = stream.Send(toProtoResult(res))
+	}
+	if sendErr != nil {
+		return sendErr
+	}
+	return recvErr
+}
+
+// unaryInterceptor добавляет request_id в контекст, журналирует вызов и учитывает его в метриках.
+func (api *API) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	api.metrics.InFlight().Inc()
+	defer api.metrics.InFlight().Dec()
+	ctx = withRequestID(ctx)
+	resp, err := handler(ctx, req)
+	api.observeCall(ctx, info.FullMethod, err, start)
+	return resp, err
+}
+
+// streamInterceptor добавляет request_id в контекст потока, журналирует вызов и учитывает его в метриках.
+func (api *API) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	api.metrics.InFlight().Inc()
+	defer api.metrics.InFlight().Dec()
+	ctx := withRequestID(ss.Context())
+	err := handler(srv, &requestIDStream{ServerStream: ss, ctx: ctx})
+	api.observeCall(ctx, info.FullMethod, err, start)
+	return err
+}
+
+// withRequestID получает request_id из метаданных вызова или генерирует новый,
+// возвращает его клиенту в заголовке ответа и добавляет в контекст вместе с журналом вызова.
+func withRequestID(ctx context.Context) context.Context {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(MetadataRequestID); len(v) > 0 {
+			requestID = v[0]
+		}
+	}
+	if requestID == "" {
+		requestID = generateRandomString(6)
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(MetadataRequestID, requestID))
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String(logging.KeyRequestID, requestID))
+	ctx = context.WithValue(ctx, RequestIDKey{}, requestID)
+	return logging.NewContext(ctx, requestIDLogger(ctx, requestID))
+}
+
+// grpcLang выбирает язык сообщений об ошибках по метаданным accept-language вызова.
+func grpcLang(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return problem.ParseLang(strings.Join(md.Get(MetadataLanguage), ","))
+}
+
+// observeCall записывает gRPC-вызов в журнал запросов и метрики запросов.
+// Полное имя метода записывается в поле route, как шаблон маршрута HTTP.
+func (api *API) observeCall(ctx context.Context, method string, err error, start time.Time) {
+	code := status.Code(err)
+	api.metrics.Observe("gRPC", method, code.String(), time.Since(start))
+	level := slog.LevelInfo
+	if code == codes.Internal || code == codes.Unknown {
+		level = slog.LevelError
+	}
+	logging.FromContext(ctx).LogAttrs(ctx, level, "Вызов обработан",
+		slog.String(logging.KeyMethod, "gRPC"),
+		slog.String(logging.KeyRoute, method),
+		slog.String(logging.KeyStatus, code.String()),
+		slog.Float64(logging.KeyDuration, durationMs(time.Since(start))),
+	)
+}
+
+// requestIDStream подменяет контекст потока контекстом с request_id.
+type requestIDStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context возвращает контекст потока с request_id.
+func (s *requestIDStream) Context() context.Context {
+	return s.ctx
+}
+
+// fromProtoItem преобразует элемент запроса в элемент пакетной проверки.
+func fromProtoItem(item *censorpb.CheckItem) batchItem {
+	return batchItem{ID: item.GetId(), Text: item.GetText(), Policy: item.GetPolicy()}
+}
+
+// toProtoResult преобразует результат пакетной проверки в сообщение gRPC.
+func toProtoResult(res batchResult) *censorpb.CheckResult {
+	out := &censorpb.CheckResult{Id: res.ID, Error: res.Error}
+	if res.Verdict != nil {
+		out.Verdict = toProtoVerdict(*res.Verdict)
+	}
+	return out
+}
+
+// toProtoVerdict преобразует вердикт цензора в сообщение gRPC.
+func toProtoVerdict(v censor.Verdict) *censorpb.Verdict {
+	out := &censorpb.Verdict{
+		Policy:            v.Policy,
+		DictionaryVersion: v.Version,
+		Language:          v.Language,
+		Decision:          string(v.Decision),
+		Score:             v.Score,
+		Rules:             v.Rules,
+		Categories:        v.Categories,
+		Action:            string(v.Action),
+		SanitizedText:     v.SanitizedText,
+	}
+	for _, m := range v.Matches {
+		out.Matches = append(out.Matches, toProtoMatch(m))
+	}
+	for _, s := range v.Signals {
+		out.Signals = append(out.Signals, &censorpb.Signal{
+			Detector: s.Detector,
+			Score:    s.Score,
+			Weight:   s.Weight,
+			Reason:   s.Reason,
+		})
+	}
+	for _, s := range v.Suppressed {
+		out.Suppressed = append(out.Suppressed, &censorpb.Suppressed{Match: toProtoMatch(s.Match), By: s.By})
+	}
+	return out
+}
+
+// toProtoMatch преобразует совпадение с правилом в сообщение gRPC.
+func toProtoMatch(m censor.Match) *censorpb.Match {
+	return &censorpb.Match{
+		RuleId:   m.RuleID,
+		Category: m.Category,
+		Fragment: m.Fragment,
+		Start:    int32(m.Start),
+		End:      int32(m.End),
+	}
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"APIGetaway/pkg/censorpb"
+	"APIGetaway/shared/logging"
+	"APIGetaway/shared/metrics"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient запускает gRPC-сервер в памяти и возвращает клиента к нему.
+func newTestClient(t *testing.T, api *API) censorpb.CensorClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := api.GRPCServer()
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Ошибка подключения к gRPC-серверу: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return censorpb.NewCensorClient(conn)
+}
+
+func TestGRPC_check(t *testing.T) {
+	api := newTestAPI(t)
+	client := newTestClient(t, api)
+
+	tests := []struct {
+		name     string
+		req      *censorpb.CheckRequest
+		decision string
+		code     codes.Code
+	}{
+		{name: "Допустимый комментарий", req: &censorpb.CheckRequest{Text: "Отличная статья"}, decision: "allow"},
+		{name: "Недопустимый комментарий", req: &censorpb.CheckRequest{Text: "qwerty"}, decision: "deny"},
+		{name: "Пустой текст", req: &censorpb.CheckRequest{}, code: codes.InvalidArgument},
+		{name: "Неизвестная политика", req: &censorpb.CheckRequest{Text: "текст", Policy: "nope"}, code: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			v, err := client.Check(ctx, tt.req)
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("Неверный код ответа: ожидается %v, получен %v", tt.code, code)
+			}
+			if err == nil && v.GetDecision() != tt.decision {
+				t.Errorf("Неверное решение: ожидается %v, получено %v", tt.decision, v.GetDecision())
+			}
+		})
+	}
+}
+
+func TestGRPC_requestID(t *testing.T) {
+	api := newTestAPI(t)
+	client := newTestClient(t, api)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), MetadataRequestID, "grpc-req")
+	var header metadata.MD
+	if _, err := client.Check(ctx, &censorpb.CheckRequest{Text: "qwerty"}, grpc.Header(&header)); err != nil {
+		t.Fatalf("Ошибка вызова Check: %v", err)
+	}
+	if got := header.Get(MetadataRequestID); len(got) != 1 || got[0] != "grpc-req" {
+		t.Errorf("Неверный request_id в ответе: %v", got)
+	}
+	records, err := api.audit.ByRequestID("grpc-req")
+	if err != nil || len(records) != 1 {
+		t.Errorf("Ожидается одна запись журнала для запроса, получено %d (%v)", len(records), err)
+	}
+}
+
+func TestGRPC_checkBatch(t *testing.T) {
+	client := newTestClient(t, newTestAPI(t))
+
+	resp, err := client.CheckBatch(context.Background(), &censorpb.CheckBatchRequest{Items: []*censorpb.CheckItem{
+		{Id: "a", Text: "Отличная статья"},
+		{Id: "b", Text: "qwerty"},
+		{Id: "c"},
+	}})
+	if err != nil {
+		t.Fatalf("Ошибка вызова CheckBatch: %v", err)
+	}
+	results := resp.GetResults()
+	if len(results) != 3 {
+		t.Fatalf("Ожидается 3 результата, получено %d", len(results))
+	}
+	if results[0].GetId() != "a" || results[0].GetVerdict().GetDecision() != "allow" {
+		t.Errorf("Неверный результат для a: %v", results[0])
+	}
+	if results[1].GetId() != "b" || results[1].GetVerdict().GetDecision() != "deny" {
+		t.Errorf("Неверный результат для b: %v", results[1])
+	}
+	if results[2].GetError() == "" {
+		t.Errorf("Ожидается ошибка для пустого текста")
+	}
+}
+
+func TestGRPC_checkStream(t *testing.T) {
+	client := newTestClient(t, newTestAPI(t))
+
+	stream, err := client.CheckStream(context.Background())
+	if err != nil {
+		t.Fatalf("Ошибка открытия потока: %v", err)
+	}
+	items := map[string]string{"a": "Отличная статья", "b": "qwerty"}
+	for id, text := range items {
+		if err := stream.Send(&censorpb.CheckItem{Id: id, Text: text}); err != nil {
+			t.Fatalf("Ошибка отправки: %v", err)
+		}
+	}
+	stream.CloseSend()
+
+	got := make(map[string]string)
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Ошибка получения: %v", err)
+		}
+		got[res.GetId()] = res.GetVerdict().GetDecision()
+	}
+	if got["a"] != "allow" || got["b"] != "deny" {
+		t.Errorf("Неверные результаты потока: %v", got)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	api := newTestAPI(t)
+	client := newTestClient(t, api)
+
+	for _, body := range []string{`{"text": "Отличная статья"}`, `{"text": "qwerty"}`, `{"text": ""}`} {
+		api.Router().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/check", strings.NewReader(body)))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	v, err := client.Check(ctx, &censorpb.CheckRequest{Text: "qwerty"})
+	if err != nil {
+		t.Fatalf("Ошибка вызова Check: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	api.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, metrics.Path, nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		fmt.Sprintf(`censor_verdicts_total{decision="deny",policy=%q} 2`, v.GetPolicy()),
+		fmt.Sprintf(`censor_verdicts_total{decision="allow",policy=%q} 1`, v.GetPolicy()),
+		`http_requests_total{method="POST",route="/check",status="200"} 2`,
+		`http_requests_total{method="POST",route="/check",status="400"} 1`,
+		fmt.Sprintf(`http_requests_total{method="gRPC",route=%q,status="OK"} 1`, censorpb.Censor_Check_FullMethodName),
+		"http_requests_in_flight 1", // сам запрос /metrics
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("В ответе %s нет %s", metrics.Path, want)
+		}
+	}
+}
+
+func TestGRPC_trace(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prevTP, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevProp)
+	}()
+
+	client := newTestClient(t, newTestAPI(t))
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		MetadataRequestID, "grpc-trace")
+	if _, err := client.Check(ctx, &censorpb.CheckRequest{Text: "qwerty"}); err != nil {
+		t.Fatalf("Ошибка вызова Check: %v", err)
+	}
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Ожидается 1 спан, получено %d", len(spans))
+	}
+	s := spans[0]
+	if s.Name() != "censor.v1.Censor/Check" || s.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Неверный спан вызова: %s в трассе %s", s.Name(), s.SpanContext().TraceID())
+	}
+	var requestID string
+	for _, kv := range s.Attributes() {
+		if string(kv.Key) == logging.KeyRequestID {
+			requestID = kv.Value.AsString()
+		}
+	}
+	if requestID != "grpc-trace" {
+		t.Errorf("Неверный request_id спана: %q", requestID)
+	}
+}
@@ -2,38 +2,23 @@ package api
 
 import (
 	"context"
-	"io"
-	"log"
+	"log/slog"
 	"math/rand"
 	"net/http"
-	"os"
 	"strings"
 	"time"
+
+	"APIGetaway/shared/logging"
+	"APIGetaway/shared/tracing"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // RequestIDKey — тип для ключа контекста
 type RequestIDKey struct{}
 
-// Создаем логгер для записи в файл и в stdout
-var logFile *os.File
-var logger *log.Logger
-
-// Инициализация логгера
-func initLogger() {
-	var err error
-	// Открытие или создание файла логов
-	logFile, err = os.OpenFile("access.log", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		log.Fatalf("Error opening log file: %v", err)
-	}
-
-	// Создаем MultiWriter для записи в файл и в stdout
-	multiWriter := io.MultiWriter(os.Stdout, logFile)
-
-	// Создаем новый логгер с использованием MultiWriter
-	logger = log.New(multiWriter, "", log.LstdFlags)
-}
-
 // Middleware для добавления request_id в контекст запроса и заголовок ответа
 func RequestIDMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -45,6 +30,9 @@ func RequestIDMiddleware(next http.Handler) http.Handler {
 
 		// Добавляем `request_id` в контекст запроса
 		ctx := context.WithValue(r.Context(), RequestIDKey{}, requestID)
+		// Журнал запроса: все записи обработчиков содержат request_id и trace_id
+		ctx = logging.NewContext(ctx, requestIDLogger(ctx, requestID))
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String(logging.KeyRequestID, requestID))
 
 		// Устанавливаем `request_id` в заголовок ответа, чтобы передавать его между сервисами
 		w.Header().Set("request_id", requestID)
@@ -60,24 +48,57 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		// Запоминаем время начала обработки запроса
 		start := time.Now()
 
-		// Извлекаем request_id из контекста
-		requestID, _ := r.Context().Value(RequestIDKey{}).(string)
-
-		// Получаем IP-адрес клиента
-		clientIP := r.RemoteAddr
+		// Обработчики могут добавить поля к записи о запросе, например user
+		ctx := logging.WithFields(r.Context())
 
 		// Перехватываем стандартный http.ResponseWriter для записи кода ответа
 		ww := &responseWriter{ResponseWriter: w}
 
 		// Вызываем обработчик запроса
-		next.ServeHTTP(ww, r)
+		next.ServeHTTP(ww, r.WithContext(ctx))
 
+		status := ww.statusCode
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
 		// Логируем информацию о запросе после его обработки
-		logger.Printf("Request ID: %s, Method: %s, URL: %s, IP: %s, Status: %d, Duration: %v, Time: %s",
-			requestID, r.Method, r.URL.Path, clientIP, ww.statusCode, time.Since(start), start.Format(time.RFC3339))
+		requestLogger(ctx).LogAttrs(ctx, level, "Запрос обработан",
+			slog.String(logging.KeyMethod, r.Method),
+			slog.String(logging.KeyPath, r.URL.Path),
+			slog.String(logging.KeyRemoteAddr, r.RemoteAddr),
+			slog.Int(logging.KeyStatus, status),
+			slog.Float64(logging.KeyDuration, durationMs(time.Since(start))),
+		)
 	})
 }
 
+// requestIDLogger возвращает журнал с request_id и идентификатором трассы, если запрос к ней относится.
+func requestIDLogger(ctx context.Context, requestID string) *slog.Logger {
+	l := slog.Default().With(logging.KeyRequestID, requestID)
+	if traceID := tracing.TraceID(ctx); traceID != "" {
+		l = l.With(logging.KeyTraceID, traceID)
+	}
+	return l
+}
+
+// requestLogger возвращает журнал запроса с шаблоном маршрута chi.
+func requestLogger(ctx context.Context) *slog.Logger {
+	l := logging.FromContext(ctx)
+	if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+		l = l.With(logging.KeyRoute, rctx.RoutePattern())
+	}
+	return l
+}
+
+// durationMs возвращает длительность в миллисекундах.
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
 // responseWriter - обертка для ResponseWriter для захвата кода статуса
 type responseWriter struct {
 	http.ResponseWriter
@@ -105,6 +126,12 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return rw.ResponseWriter.Write(b)
 }
 
+// Unwrap возвращает исходный ResponseWriter, чтобы http.ResponseController
+// мог отправить буферизованные данные и включить полнодуплексный режим.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // Генерация случайного request_id
 func generateRandomString(length int) string {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
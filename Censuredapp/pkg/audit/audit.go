@@ -0,0 +1,276 @@
+// Package audit хранит журнал решений цензора, чтобы можно было объяснить,
+// почему комментарий был отклонен.
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"APIGetaway/pkg/censor"
+	"APIGetaway/shared/logging"
+)
+
+// Record - запись журнала о решении по одному тексту.
+// Сам текст не сохраняется, только его хеш.
+type Record struct {
+	RequestID  string          `json:"request_id"`
+	ItemID     string          `json:"item_id,omitempty"` // ID элемента пакетной проверки
+	TextHash   string          `json:"text_hash"`         // SHA-256 текста
+	Policy     string          `json:"policy"`
+	Version    string          `json:"dictionary_version"`
+	Language   string          `json:"language"`
+	Decision   censor.Decision `json:"decision"`
+	Score      float64         `json:"score"`
+	Rules      []string        `json:"rules"`
+	Categories []string        `json:"categories"`
+	Signals    []censor.Signal `json:"signals"`
+	Action     censor.Action   `json:"action"`
+	LatencyMS  float64         `json:"latency_ms"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// NewRecord формирует запись журнала по вердикту.
+func NewRecord(requestID, itemID, text string, v censor.Verdict, latency time.Duration) Record {
+	sum := sha256.Sum256([]byte(text))
+	return Record{
+		RequestID:  requestID,
+		ItemID:     itemID,
+		TextHash:   hex.EncodeToString(sum[:]),
+		Policy:     v.Policy,
+		Version:    v.Version,
+		Language:   v.Language,
+		Decision:   v.Decision,
+		Score:      v.Score,
+		Rules:      v.Rules,
+		Categories: v.Categories,
+		Signals:    v.Signals,
+		Action:     v.Action,
+		LatencyMS:  float64(latency.Microseconds()) / 1000,
+		CreatedAt:  time.Now(),
+	}
+}
+
+// Параметры журнала по умолчанию
+const (
+	DefaultRetention  = 24 * time.Hour // время, в течение которого решения ищутся по request_id
+	DefaultMaxRecords = 100000         // наибольшее число решений, хранимых для поиска
+)
+
+// Config - параметры журнала решений.
+type Config struct {
+	Path        string `json:"path"`         // файл журнала, пустой - журнал отключен
+	MaxSizeMB   int    `json:"max_size_mb"`  // размер файла в мегабайтах для ротации, 0 - без ограничения
+	RotateEvery string `json:"rotate_every"` // период ротации, например "24h", пустой - без ротации по времени
+	MaxBackups  int    `json:"max_backups"`  // число хранимых старых файлов, 0 - хранить все
+	Compress    bool   `json:"compress"`     // сжимать старые файлы gzip
+	Retention   string `json:"retention"`    // время поиска решений по request_id, по умолчанию "24h"
+	MaxRecords  int    `json:"max_records"`  // число решений для поиска по request_id, по умолчанию 100000
+}
+
+// Log - журнал решений в файле формата JSON Lines с ротацией.
+// Старые файлы переименовываются и сжимаются, поэтому смещения записей
+// в файле не постоянны: для поиска по request_id в памяти хранятся решения
+// за последнее время Retention, но не больше MaxRecords. Более старые
+// решения, а после перезапуска и решения из старых файлов, остаются только
+// в файлах журнала: ByRequestID их не находит, а RetainedSince сообщает,
+// с какого времени поиск полон.
+type Log struct {
+	file       *logging.File
+	retention  time.Duration
+	maxRecords int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	recent  map[string][]Record // request_id -> решения запроса
+	order   []string            // request_id записей в порядке добавления
+	expired int                 // число устаревших записей в начале order
+	since   time.Time           // время самого нового решения, не попавшего в поиск
+}
+
+// Open открывает (или создает) файл журнала и загружает из него недавние решения.
+// Неполная последняя строка, оставшаяся после аварийной остановки, удаляется.
+func Open(cfg Config) (*Log, error) {
+	rc, retention, err := cfg.parse()
+	if err != nil {
+		return nil, err
+	}
+	l := Log{
+		retention:  retention,
+		maxRecords: cfg.MaxRecords,
+		now:        time.Now,
+		recent:     map[string][]Record{},
+	}
+	if l.maxRecords == 0 {
+		l.maxRecords = DefaultMaxRecords
+	}
+	if err := l.load(cfg.Path); err != nil {
+		return nil, err
+	}
+	// Старые файлы журнала при запуске не читаются, их решения в поиск не попадают
+	if backups, _ := filepath.Glob(cfg.Path + ".*"); len(backups) > 0 {
+		l.since = l.now()
+		if len(l.order) > l.expired {
+			l.since = l.recent[l.order[l.expired]][0].CreatedAt
+		}
+	}
+	if l.file, err = logging.OpenFile(cfg.Path, rc); err != nil {
+		return nil, fmt.Errorf("ошибка открытия журнала решений: %w", err)
+	}
+	return &l, nil
+}
+
+// parse проверяет настройки и возвращает параметры ротации и время хранения.
+func (c Config) parse() (logging.RotateConfig, time.Duration, error) {
+	rc := logging.RotateConfig{
+		MaxSize:    int64(c.MaxSizeMB) << 20,
+		MaxBackups: c.MaxBackups,
+		Compress:   c.Compress,
+	}
+	if c.Path == "" {
+		return rc, 0, fmt.Errorf("не задан файл журнала решений")
+	}
+	if c.MaxSizeMB < 0 || c.MaxBackups < 0 || c.MaxRecords < 0 {
+		return rc, 0, fmt.Errorf("неверные параметры журнала решений")
+	}
+	if c.RotateEvery != "" {
+		d, err := time.ParseDuration(c.RotateEvery)
+		if err != nil || d <= 0 {
+			return rc, 0, fmt.Errorf("неверный период ротации журнала решений %q", c.RotateEvery)
+		}
+		rc.Interval = d
+	}
+	retention := DefaultRetention
+	if c.Retention != "" {
+		d, err := time.ParseDuration(c.Retention)
+		if err != nil || d <= 0 {
+			return rc, 0, fmt.Errorf("неверное время хранения решений %q", c.Retention)
+		}
+		retention = d
+	}
+	return rc, retention, nil
+}
+
+// load обрезает файл журнала по последней полной строке и загружает
+// из него решения, которые еще не вышли за пределы времени хранения.
+func (l *Log) load(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка открытия журнала решений: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var size int64
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 && line[len(line)-1] == '\n' {
+			size += int64(len(line))
+			var rec Record
+			if json.Unmarshal(line, &rec) == nil {
+				l.add(rec)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка чтения журнала решений: %w", err)
+		}
+	}
+	// Неполная последняя строка (после аварийной остановки) удаляется,
+	// чтобы следующая запись начиналась с новой строки
+	if err := f.Truncate(size); err != nil {
+		return fmt.Errorf("ошибка восстановления журнала решений: %w", err)
+	}
+	return nil
+}
+
+// Write добавляет запись в журнал.
+func (l *Log) Write(rec Record) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.file.Write(b); err != nil {
+		return fmt.Errorf("ошибка записи в журнал решений: %w", err)
+	}
+	l.add(rec)
+	return nil
+}
+
+// add запоминает решение для поиска и удаляет устаревшие. Вызывается с захваченным mu
+// или до начала работы журнала.
+func (l *Log) add(rec Record) {
+	l.recent[rec.RequestID] = append(l.recent[rec.RequestID], rec)
+	l.order = append(l.order, rec.RequestID)
+	l.prune()
+}
+
+// prune удаляет самые старые решения, вышедшие за пределы времени хранения
+// или сверх MaxRecords.
+func (l *Log) prune() {
+	cut := l.now().Add(-l.retention)
+	for len(l.order)-l.expired > 0 {
+		id := l.order[l.expired]
+		records := l.recent[id]
+		if len(l.order)-l.expired <= l.maxRecords && !records[0].CreatedAt.Before(cut) {
+			break
+		}
+		if records[0].CreatedAt.After(l.since) {
+			l.since = records[0].CreatedAt
+		}
+		if len(records) == 1 {
+			delete(l.recent, id)
+		} else {
+			l.recent[id] = records[1:]
+		}
+		l.order[l.expired] = ""
+		l.expired++
+	}
+	// Освобождаем начало order, когда устаревших записей в нем больше половины
+	if l.expired > len(l.order)/2 {
+		l.order = append(l.order[:0:0], l.order[l.expired:]...)
+		l.expired = 0
+	}
+}
+
+// ByRequestID возвращает решения, принятые в рамках запроса за время хранения.
+func (l *Log) ByRequestID(requestID string) ([]Record, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prune()
+	return append([]Record{}, l.recent[requestID]...), nil
+}
+
+// RetainedSince возвращает время, начиная с которого ByRequestID находит все
+// решения. Более ранние решения могли выйти за пределы Retention или MaxRecords
+// и остались только в файлах журнала. Нулевое время - поиск охватывает
+// все решения журнала.
+func (l *Log) RetainedSince() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prune()
+	return l.since
+}
+
+// Close закрывает файл журнала.
+func (l *Log) Close() error {
+	return l.file.Close()
+}
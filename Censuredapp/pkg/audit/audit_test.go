@@ -0,0 +1,198 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"APIGetaway/pkg/censor"
+)
+
+func record(requestID string, at time.Time) Record {
+	return Record{RequestID: requestID, TextHash: "hash", Decision: censor.DecisionAllow, CreatedAt: at}
+}
+
+// open открывает журнал и закрывает его после теста.
+func open(t *testing.T, cfg Config) *Log {
+	t.Helper()
+	l, err := Open(cfg)
+	if err != nil {
+		t.Fatalf("Ошибка открытия журнала: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+func requestIDs(t *testing.T, l *Log, ids ...string) map[string]int {
+	t.Helper()
+	got := map[string]int{}
+	for _, id := range ids {
+		records, err := l.ByRequestID(id)
+		if err != nil {
+			t.Fatalf("Ошибка поиска: %v", err)
+		}
+		got[id] = len(records)
+	}
+	return got
+}
+
+func TestOpen_config(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "verdicts.log")
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "Только файл", cfg: Config{Path: path}},
+		{name: "Ротация", cfg: Config{Path: path, MaxSizeMB: 10, RotateEvery: "1h", MaxBackups: 3, Compress: true, Retention: "2h"}},
+		{name: "Без файла", cfg: Config{}, wantErr: true},
+		{name: "Неверный период", cfg: Config{Path: path, RotateEvery: "сутки"}, wantErr: true},
+		{name: "Неверное время хранения", cfg: Config{Path: path, Retention: "-1h"}, wantErr: true},
+		{name: "Отрицательное число решений", cfg: Config{Path: path, MaxRecords: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := Open(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Ошибка: ожидается %v, получено %v", tt.wantErr, err)
+			}
+			if l != nil {
+				l.Close()
+			}
+		})
+	}
+}
+
+func TestLog_reopen(t *testing.T) {
+	cfg := Config{Path: filepath.Join(t.TempDir(), "verdicts.log")}
+	l, err := Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for _, id := range []string{"a", "b", "a"} {
+		if err := l.Write(record(id, now)); err != nil {
+			t.Fatalf("Ошибка записи: %v", err)
+		}
+	}
+	l.Close()
+
+	// После перезапуска решения снова находятся по request_id
+	l = open(t, cfg)
+	got := requestIDs(t, l, "a", "b", "c")
+	if got["a"] != 2 || got["b"] != 1 || got["c"] != 0 {
+		t.Errorf("Неверное число решений: %v", got)
+	}
+}
+
+func TestLog_tornLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "verdicts.log")
+	full := `{"request_id":"a","decision":"allow","created_at":"` + time.Now().Format(time.RFC3339) + `"}` + "\n"
+	if err := os.WriteFile(path, []byte(full+`{"request_id":"b","deci`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l := open(t, Config{Path: path})
+	if err := l.Write(record("c", time.Now())); err != nil {
+		t.Fatalf("Ошибка записи: %v", err)
+	}
+
+	b, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	if len(lines) != 2 || lines[0]+"\n" != full || !strings.HasPrefix(lines[1], `{"request_id":"c"`) {
+		t.Errorf("Неполная строка не удалена: %q", b)
+	}
+	if got := requestIDs(t, l, "a", "b", "c"); got["a"] != 1 || got["b"] != 0 || got["c"] != 1 {
+		t.Errorf("Неверное число решений: %v", got)
+	}
+}
+
+func TestLog_retention(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := open(t, Config{Path: filepath.Join(t.TempDir(), "verdicts.log"), Retention: "1h", MaxRecords: 3})
+	l.now = func() time.Time { return now }
+
+	l.Write(record("old", now.Add(-2*time.Hour)))
+	l.Write(record("a", now.Add(-30*time.Minute)))
+	if got := requestIDs(t, l, "old", "a"); got["old"] != 0 || got["a"] != 1 {
+		t.Errorf("Устаревшее решение не удалено: %v", got)
+	}
+
+	// Сверх MaxRecords удаляются самые старые решения
+	for _, id := range []string{"b", "c", "d"} {
+		l.Write(record(id, now))
+	}
+	if got := requestIDs(t, l, "a", "b", "c", "d"); got["a"] != 0 || got["b"] != 1 || got["d"] != 1 {
+		t.Errorf("Неверные решения после превышения MaxRecords: %v", got)
+	}
+	if len(l.recent) != 3 {
+		t.Errorf("Хранится %d запросов, ожидается 3", len(l.recent))
+	}
+	if got := l.RetainedSince(); !got.Equal(now.Add(-30 * time.Minute)) {
+		t.Errorf("Поиск охватывает решения с %v, ожидается время вытесненного решения a", got)
+	}
+}
+
+func TestLog_defaultMaxRecords(t *testing.T) {
+	l := open(t, Config{Path: filepath.Join(t.TempDir(), "verdicts.log")})
+	if l.maxRecords != DefaultMaxRecords {
+		t.Fatalf("Число решений по умолчанию: ожидается %d, получено %d", DefaultMaxRecords, l.maxRecords)
+	}
+	if !l.RetainedSince().IsZero() {
+		t.Errorf("Пустой журнал: поиск должен охватывать все решения")
+	}
+
+	// Записи добавляются в индекс напрямую, без файла, чтобы тест был быстрым
+	start := time.Now()
+	l.mu.Lock()
+	for i := 0; i <= DefaultMaxRecords; i++ {
+		l.add(record("req"+strconv.Itoa(i), start.Add(time.Duration(i)*time.Millisecond)))
+	}
+	l.mu.Unlock()
+
+	if n := len(l.order) - l.expired; n != DefaultMaxRecords {
+		t.Errorf("Хранится %d решений, ожидается %d", n, DefaultMaxRecords)
+	}
+	last := "req" + strconv.Itoa(DefaultMaxRecords)
+	if got := requestIDs(t, l, "req0", "req1", last); got["req0"] != 0 || got["req1"] != 1 || got[last] != 1 {
+		t.Errorf("Вытеснено не самое старое решение: %v", got)
+	}
+	if got := l.RetainedSince(); !got.Equal(start) {
+		t.Errorf("Поиск охватывает решения с %v, ожидается %v", got, start)
+	}
+}
+
+func TestLog_rotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "verdicts.log")
+	l := open(t, Config{Path: path, RotateEvery: "10ms"})
+
+	l.Write(record("a", time.Now()))
+	time.Sleep(20 * time.Millisecond)
+	l.Write(record("b", time.Now()))
+
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 1 {
+		t.Fatalf("Ожидается 1 старый файл журнала, найдено %v", backups)
+	}
+	// Решения из старого файла по-прежнему находятся по request_id
+	if got := requestIDs(t, l, "a", "b"); got["a"] != 1 || got["b"] != 1 {
+		t.Errorf("Неверное число решений после ротации: %v", got)
+	}
+	if !l.RetainedSince().IsZero() {
+		t.Errorf("До перезапуска поиск должен охватывать все решения")
+	}
+
+	// После перезапуска старые файлы не читаются: поиск охватывает только текущий файл
+	l.Close()
+	l = open(t, Config{Path: path})
+	if got := requestIDs(t, l, "a", "b"); got["a"] != 0 || got["b"] != 1 {
+		t.Errorf("Неверное число решений после перезапуска: %v", got)
+	}
+	b := l.recent["b"][0].CreatedAt
+	if got := l.RetainedSince(); !got.Equal(b) {
+		t.Errorf("Поиск охватывает решения с %v, ожидается время решения b %v", got, b)
+	}
+}
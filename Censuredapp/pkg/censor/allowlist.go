@@ -0,0 +1,79 @@
+package censor
+
+// Exception - исключение из правил: совпадения, целиком попадающие внутрь
+// разрешенного фрагмента, не учитываются. Например, разрешенное слово
+// может содержать запрещенный фрагмент.
+type Exception struct {
+	Pattern string   `json:"pattern"` // разрешенный фрагмент (без учета регистра)
+	Rules   []string `json:"rules"`   // правила, к которым применяется исключение; пусто - ко всем
+}
+
+// Suppressed - совпадение, подавленное разрешенным фрагментом.
+type Suppressed struct {
+	Match
+	By string `json:"by"` // разрешенный фрагмент, подавивший совпадение
+}
+
+// allowEntry - подготовленное к поиску исключение.
+type allowEntry struct {
+	source  string
+	pattern []rune
+	rules   map[string]bool // nil - применяется ко всем правилам
+}
+
+// compileAllowlist готовит глобальный список разрешенных слов и исключения политики.
+// Фрагменты нормализуются так же, как проверяемый текст: каждое слово
+// по правилам своего языка, иначе "ёлка" не совпадет с текстом "елка".
+func compileAllowlist(languages *languageSet, allowlist []string, exceptions []Exception) []allowEntry {
+	entries := make([]allowEntry, 0, len(allowlist)+len(exceptions))
+	for _, word := range allowlist {
+		if word == "" {
+			continue
+		}
+		entries = append(entries, allowEntry{source: word, pattern: languages.analyze(word).norm})
+	}
+	for _, e := range exceptions {
+		if e.Pattern == "" {
+			continue
+		}
+		entry := allowEntry{source: e.Pattern, pattern: languages.analyze(e.Pattern).norm}
+		if len(e.Rules) > 0 {
+			entry.rules = make(map[string]bool, len(e.Rules))
+			for _, id := range e.Rules {
+				entry.rules[id] = true
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// allowSpan - вхождение разрешенного фрагмента в текст.
+type allowSpan struct {
+	entry      *allowEntry
+	start, end int
+}
+
+// findAllowed находит в тексте все вхождения разрешенных фрагментов.
+func findAllowed(lower []rune, entries []allowEntry) []allowSpan {
+	var spans []allowSpan
+	for i := range entries {
+		for _, start := range indexAll(lower, entries[i].pattern) {
+			spans = append(spans, allowSpan{entry: &entries[i], start: start, end: start + len(entries[i].pattern)})
+		}
+	}
+	return spans
+}
+
+// suppressedBy возвращает разрешенный фрагмент, внутрь которого целиком попадает совпадение.
+func suppressedBy(m Match, spans []allowSpan) (string, bool) {
+	for _, s := range spans {
+		if s.entry.rules != nil && !s.entry.rules[m.RuleID] {
+			continue
+		}
+		if s.start <= m.Start && m.End <= s.end {
+			return s.entry.source, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,212 @@
+// Package censor реализует проверку текста комментариев по правилам цензуры
+// и формирование структурированного решения (вердикта).
+package censor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"unicode"
+)
+
+// ErrUnknownPolicy возвращается при запросе проверки по несуществующей политике.
+var ErrUnknownPolicy = errors.New("неизвестная политика цензуры")
+
+// Decision - итоговое решение по комментарию.
+type Decision string
+
+const (
+	DecisionAllow  Decision = "allow"  // комментарий можно публиковать
+	DecisionDeny   Decision = "deny"   // комментарий отклонен
+	DecisionReview Decision = "review" // комментарий требует ручной проверки
+)
+
+// Rule - правило цензуры: запрещенный фрагмент текста.
+type Rule struct {
+	ID       string  `json:"id"`       // идентификатор правила
+	Category string  `json:"category"` // категория нарушения
+	Pattern  string  `json:"pattern"`  // искомый фрагмент (без учета регистра)
+	Weight   float64 `json:"weight"`   // вклад правила в итоговую оценку
+}
+
+// Match - найденное в тексте совпадение с правилом.
+type Match struct {
+	RuleID   string `json:"rule_id"`
+	Category string `json:"category"`
+	Fragment string `json:"fragment"` // совпавший фрагмент исходного текста
+	Start    int    `json:"start"`    // смещение начала в символах
+	End      int    `json:"end"`      // смещение конца в символах (не включая)
+}
+
+// Verdict - результат проверки комментария.
+type Verdict struct {
+	Policy     string   `json:"policy"`             // политика, по которой проверялся текст
+	Version    string   `json:"dictionary_version"` // версия словаря и настроек политики
+	Language   string   `json:"language"`           // преобладающий язык текста
+	Decision   Decision `json:"decision"`
+	Score      float64  `json:"score"`
+	Rules      []string `json:"rules"`      // идентификаторы сработавших правил
+	Categories []string `json:"categories"` // категории сработавших правил
+	Matches    []Match  `json:"matches"`
+	Signals    []Signal `json:"signals"` // сработавшие детекторы
+	// Suppressed - совпадения, подавленные списком разрешенных слов и исключениями.
+	Suppressed []Suppressed `json:"suppressed,omitempty"`
+	Action     Action       `json:"action"`
+	// SanitizedText - текст с замаскированными нарушениями,
+	// заполняется только для действия mask.
+	SanitizedText string `json:"sanitized_text,omitempty"`
+}
+
+// Censor проверяет тексты по именованным политикам.
+type Censor struct {
+	policies      map[string]*policy
+	defaultPolicy string
+	languages     *languageSet
+}
+
+// New создает цензор с заданной конфигурацией.
+func New(cfg Config) (*Censor, error) {
+	languages, err := compileLanguages(cfg.Languages)
+	if err != nil {
+		return nil, err
+	}
+	sets := make(map[string]RuleSet, len(cfg.RuleSets))
+	for _, rs := range cfg.RuleSets {
+		if rs.Language != "" && !languages.has(rs.Language) {
+			return nil, fmt.Errorf("набор правил %q: язык %q не описан", rs.Name, rs.Language)
+		}
+		sets[rs.Name] = rs
+	}
+
+	// Хеш модели входит в версию политик: переобученная модель дает новую версию
+	// Модель загружается, только если классификатор включен хотя бы в одной политике
+	classifier, model := cfg.Classifier, ""
+	if classifier != nil {
+		model = modelHash(classifier)
+	} else if cfg.ClassifierModel != "" && cfg.usesClassifier() {
+		nb, hash, err := loadModel(cfg.ClassifierModel)
+		if err != nil {
+			return nil, err
+		}
+		classifier, model = nb, hash
+	}
+
+	c := Censor{
+		policies:      make(map[string]*policy, len(cfg.Policies)),
+		defaultPolicy: cfg.DefaultPolicy,
+		languages:     languages,
+	}
+	for _, p := range cfg.Policies {
+		compiled, err := compilePolicy(p, sets, languages, classifier, model, cfg.Allowlist)
+		if err != nil {
+			return nil, err
+		}
+		c.policies[p.Name] = compiled
+	}
+	if _, ok := c.policies[c.defaultPolicy]; !ok {
+		return nil, fmt.Errorf("%w: политика по умолчанию %q не описана", ErrUnknownPolicy, c.defaultPolicy)
+	}
+	return &c, nil
+}
+
+// Policies возвращает имена доступных политик.
+func (c *Censor) Policies() []string {
+	names := make([]string, 0, len(c.policies))
+	for name := range c.policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check проверяет текст по политике с указанным именем и возвращает вердикт.
+// Пустое имя означает политику по умолчанию.
+func (c *Censor) Check(policyName, text string) (Verdict, error) {
+	if policyName == "" {
+		policyName = c.defaultPolicy
+	}
+	p, ok := c.policies[policyName]
+	if !ok {
+		return Verdict{}, fmt.Errorf("%w: %q", ErrUnknownPolicy, policyName)
+	}
+	return p.check(text, c.languages.analyze(text)), nil
+}
+
+// rule - подготовленное к поиску правило.
+type rule struct {
+	Rule
+	language string // язык набора правил, пустой - правило применяется к любому тексту
+	pattern  []rune
+}
+
+// matchRules ищет в нормализованном тексте совпадения с правилами языков,
+// встречающихся в тексте, и заполняет вердикт.
+// Совпадения внутри разрешенных фрагментов попадают в список подавленных.
+func matchRules(v *Verdict, rules []rule, allow []allowEntry, text string, info textInfo) {
+	src := []rune(text)
+	allowed := findAllowed(info.norm, allow)
+
+	categories := make(map[string]bool)
+	for _, r := range rules {
+		if r.language != "" && !info.languages[r.language] {
+			continue
+		}
+		found := false
+		for _, start := range indexAll(info.norm, r.pattern) {
+			end := start + len(r.pattern)
+			m := Match{
+				RuleID:   r.ID,
+				Category: r.Category,
+				Fragment: string(src[start:end]),
+				Start:    start,
+				End:      end,
+			}
+			if by, ok := suppressedBy(m, allowed); ok {
+				v.Suppressed = append(v.Suppressed, Suppressed{Match: m, By: by})
+				continue
+			}
+			v.Matches = append(v.Matches, m)
+			found = true
+		}
+		if !found {
+			continue
+		}
+		v.Rules = append(v.Rules, r.ID)
+		v.Score += r.Weight
+		if !categories[r.Category] {
+			categories[r.Category] = true
+			v.Categories = append(v.Categories, r.Category)
+		}
+	}
+	sort.SliceStable(v.Matches, func(i, j int) bool { return v.Matches[i].Start < v.Matches[j].Start })
+}
+
+// lowerRunes переводит текст в нижний регистр посимвольно,
+// сохраняя количество символов, чтобы смещения совпадали с исходным текстом.
+func lowerRunes(s string) []rune {
+	r := []rune(s)
+	for i := range r {
+		r[i] = unicode.ToLower(r[i])
+	}
+	return r
+}
+
+// indexAll возвращает позиции всех (в том числе перекрывающихся) вхождений pattern в text.
+func indexAll(text, pattern []rune) []int {
+	var res []int
+	for i := 0; i+len(pattern) <= len(text); i++ {
+		if equalRunes(text[i:i+len(pattern)], pattern) {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+func equalRunes(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
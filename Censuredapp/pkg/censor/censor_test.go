@@ -0,0 +1,589 @@
+package censor
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testConfig возвращает конфигурацию с двумя политиками для тестов.
+func testConfig() Config {
+	return Config{
+		RuleSets: []RuleSet{
+			{Name: "profanity", Rules: []Rule{
+				{ID: "word-qwerty", Category: "profanity", Pattern: "qwerty", Weight: 1},
+				{ID: "word-jcuken", Category: "profanity", Pattern: "йцукен", Weight: 1},
+			}},
+			{Name: "insults", Rules: []Rule{
+				{ID: "word-durak", Category: "insult", Pattern: "дурак", Weight: 0.5},
+			}},
+		},
+		Policies: []Policy{
+			{Name: "default", RuleSets: []string{"profanity", "insults"}, ReviewThreshold: 0.5, DenyThreshold: 1},
+			{
+				Name: "mask", RuleSets: []string{"profanity", "insults"}, ReviewThreshold: 0.5, DenyThreshold: 1,
+				Action: ActionMask, Masking: Masking{Char: "#", PreserveLength: true},
+				Exceptions: []Exception{
+					{Pattern: "qwerty-клавиатур", Rules: []string{"word-qwerty"}},
+					{Pattern: "дурака", Rules: []string{"word-qwerty"}}, // не относится к word-durak
+				},
+			},
+			{Name: "relaxed", RuleSets: []string{"profanity"}, ReviewThreshold: 1, DenyThreshold: 2},
+		},
+		DefaultPolicy: "default",
+		Allowlist:     []string{"дураков"},
+	}
+}
+
+func newTestCensor(t *testing.T) *Censor {
+	t.Helper()
+	c, err := New(testConfig())
+	if err != nil {
+		t.Fatalf("Ошибка создания цензора: %v", err)
+	}
+	return c
+}
+
+func TestCensor_Check(t *testing.T) {
+	c := newTestCensor(t)
+
+	tests := []struct {
+		name           string
+		policy         string
+		text           string
+		wantDecision   Decision
+		wantRules      []string
+		wantCategories []string
+		wantMatches    []Match
+	}{
+		{
+			name:           "Чистый текст",
+			text:           "Отличная статья",
+			wantDecision:   DecisionAllow,
+			wantRules:      []string{},
+			wantCategories: []string{},
+			wantMatches:    []Match{},
+		},
+		{
+			name:           "Запрещенное слово в другом регистре",
+			text:           "Это ЙЦУКЕН",
+			wantDecision:   DecisionDeny,
+			wantRules:      []string{"word-jcuken"},
+			wantCategories: []string{"profanity"},
+			wantMatches:    []Match{{RuleID: "word-jcuken", Category: "profanity", Fragment: "ЙЦУКЕН", Start: 4, End: 10}},
+		},
+		{
+			name:           "Пограничный случай",
+			text:           "Сам дурак",
+			wantDecision:   DecisionReview,
+			wantRules:      []string{"word-durak"},
+			wantCategories: []string{"insult"},
+			wantMatches:    []Match{{RuleID: "word-durak", Category: "insult", Fragment: "дурак", Start: 4, End: 9}},
+		},
+		{
+			name:           "Несколько правил",
+			text:           "дурак и qwerty, дурак",
+			wantDecision:   DecisionDeny,
+			wantRules:      []string{"word-qwerty", "word-durak"},
+			wantCategories: []string{"profanity", "insult"},
+			wantMatches: []Match{
+				{RuleID: "word-durak", Category: "insult", Fragment: "дурак", Start: 0, End: 5},
+				{RuleID: "word-qwerty", Category: "profanity", Fragment: "qwerty", Start: 8, End: 14},
+				{RuleID: "word-durak", Category: "insult", Fragment: "дурак", Start: 16, End: 21},
+			},
+		},
+		{
+			name:           "Совпадение внутри разрешенного слова",
+			text:           "Иван Дураков",
+			wantDecision:   DecisionAllow,
+			wantRules:      []string{},
+			wantCategories: []string{},
+			wantMatches:    []Match{},
+		},
+		{
+			name:           "Исключение политики",
+			policy:         "mask",
+			text:           "Купил qwerty-клавиатуру",
+			wantDecision:   DecisionAllow,
+			wantRules:      []string{},
+			wantCategories: []string{},
+			wantMatches:    []Match{},
+		},
+		{
+			name:           "Исключение для другого правила",
+			policy:         "mask",
+			text:           "У дурака",
+			wantDecision:   DecisionReview,
+			wantRules:      []string{"word-durak"},
+			wantCategories: []string{"insult"},
+			wantMatches:    []Match{{RuleID: "word-durak", Category: "insult", Fragment: "дурак", Start: 2, End: 7}},
+		},
+		{
+			name:           "Исключение действует только в своей политике",
+			text:           "Купил qwerty-клавиатуру",
+			wantDecision:   DecisionDeny,
+			wantRules:      []string{"word-qwerty"},
+			wantCategories: []string{"profanity"},
+			wantMatches:    []Match{{RuleID: "word-qwerty", Category: "profanity", Fragment: "qwerty", Start: 6, End: 12}},
+		},
+		{
+			name:           "Политика без набора правил",
+			policy:         "relaxed",
+			text:           "Сам дурак",
+			wantDecision:   DecisionAllow,
+			wantRules:      []string{},
+			wantCategories: []string{},
+			wantMatches:    []Match{},
+		},
+		{
+			name:           "Собственные пороги политики",
+			policy:         "relaxed",
+			text:           "Это qwerty",
+			wantDecision:   DecisionReview,
+			wantRules:      []string{"word-qwerty"},
+			wantCategories: []string{"profanity"},
+			wantMatches:    []Match{{RuleID: "word-qwerty", Category: "profanity", Fragment: "qwerty", Start: 4, End: 10}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := c.Check(tt.policy, tt.text)
+			if err != nil {
+				t.Fatalf("Ошибка проверки: %v", err)
+			}
+			wantPolicy := tt.policy
+			if wantPolicy == "" {
+				wantPolicy = "default"
+			}
+			if v.Policy != wantPolicy {
+				t.Errorf("Неверная политика: ожидается %q, получено %q", wantPolicy, v.Policy)
+			}
+			if v.Decision != tt.wantDecision {
+				t.Errorf("Неверное решение: ожидается %v, получено %v", tt.wantDecision, v.Decision)
+			}
+			if !reflect.DeepEqual(v.Rules, tt.wantRules) {
+				t.Errorf("Неверные правила: ожидается %v, получено %v", tt.wantRules, v.Rules)
+			}
+			if !reflect.DeepEqual(v.Categories, tt.wantCategories) {
+				t.Errorf("Неверные категории: ожидается %v, получено %v", tt.wantCategories, v.Categories)
+			}
+			if !reflect.DeepEqual(v.Matches, tt.wantMatches) {
+				t.Errorf("Неверные совпадения: ожидается %+v, получено %+v", tt.wantMatches, v.Matches)
+			}
+		})
+	}
+}
+
+func TestCensor_Suppressed(t *testing.T) {
+	c := newTestCensor(t)
+	v, err := c.Check("", "Иван Дураков и дурак")
+	if err != nil {
+		t.Fatalf("Ошибка проверки: %v", err)
+	}
+	if len(v.Suppressed) != 1 || v.Suppressed[0].By != "дураков" || v.Suppressed[0].Start != 5 {
+		t.Errorf("Неверные подавленные совпадения: %+v", v.Suppressed)
+	}
+	if len(v.Matches) != 1 || v.Matches[0].Start != 15 {
+		t.Errorf("Неверные совпадения: %+v", v.Matches)
+	}
+}
+
+func TestCensor_NormalizedAllowlist(t *testing.T) {
+	c, err := New(Config{
+		RuleSets: []RuleSet{{Name: "words", Rules: []Rule{
+			{ID: "word-el", Category: "profanity", Pattern: "ел", Weight: 1},
+			{ID: "word-ezh", Category: "profanity", Pattern: "ёж", Weight: 1},
+		}}},
+		Policies: []Policy{{
+			Name: "default", RuleSets: []string{"words"}, ReviewThreshold: 0.5, DenyThreshold: 1,
+			Exceptions: []Exception{{Pattern: "вeсел", Rules: []string{"word-el"}}}, // латинская e
+		}},
+		DefaultPolicy: "default",
+		Allowlist:     []string{"ёлка"},
+	})
+	if err != nil {
+		t.Fatalf("Ошибка создания цензора: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		text           string
+		wantDecision   Decision
+		wantSuppressed string // разрешенный фрагмент, подавивший совпадение
+	}{
+		{name: "Буква ё в тексте и списке", text: "Красивая ёлка", wantDecision: DecisionAllow, wantSuppressed: "ёлка"},
+		{name: "Буква е в тексте", text: "Красивая елка", wantDecision: DecisionAllow, wantSuppressed: "ёлка"},
+		{name: "Латинская буква в тексте", text: "Красивая ёлкa", wantDecision: DecisionAllow, wantSuppressed: "ёлка"},
+		{name: "Латинские двойники в исключении", text: "Было весело", wantDecision: DecisionAllow, wantSuppressed: "вeсел"},
+		{name: "Совпадение вне списка", text: "Он ел кашу", wantDecision: DecisionDeny},
+		{name: "Правило с буквой ё", text: "Колючий ежик", wantDecision: DecisionDeny},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := c.Check("", tt.text)
+			if err != nil {
+				t.Fatalf("Ошибка проверки: %v", err)
+			}
+			if v.Decision != tt.wantDecision {
+				t.Errorf("Неверное решение: ожидается %v, получено %v (%+v)", tt.wantDecision, v.Decision, v.Matches)
+			}
+			if tt.wantSuppressed == "" {
+				if len(v.Suppressed) != 0 {
+					t.Errorf("Лишние подавленные совпадения: %+v", v.Suppressed)
+				}
+				return
+			}
+			if len(v.Suppressed) != 1 || v.Suppressed[0].By != tt.wantSuppressed {
+				t.Errorf("Неверные подавленные совпадения: %+v", v.Suppressed)
+			}
+		})
+	}
+}
+
+func TestCensor_UnknownPolicy(t *testing.T) {
+	c := newTestCensor(t)
+	if _, err := c.Check("unknown", "qwerty"); !errors.Is(err, ErrUnknownPolicy) {
+		t.Errorf("Ожидается ошибка ErrUnknownPolicy, получено %v", err)
+	}
+}
+
+func TestCensor_Policies(t *testing.T) {
+	want := []string{"default", "mask", "relaxed"}
+	if got := newTestCensor(t).Policies(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Неверный список политик: ожидается %v, получено %v", want, got)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr bool
+	}{
+		{name: "Корректная конфигурация", modify: func(cfg *Config) {}},
+		{name: "Неизвестный набор правил", modify: func(cfg *Config) { cfg.Policies[0].RuleSets = []string{"unknown"} }, wantErr: true},
+		{name: "Неизвестное действие", modify: func(cfg *Config) { cfg.Policies[0].Action = "drop" }, wantErr: true},
+		{name: "Нет политики по умолчанию", modify: func(cfg *Config) { cfg.DefaultPolicy = "unknown" }, wantErr: true},
+		{name: "Неизвестный детектор", modify: func(cfg *Config) { cfg.Policies[0].Detectors = []DetectorConfig{{Type: "unknown"}} }, wantErr: true},
+		{name: "Неверные параметры детектора", modify: func(cfg *Config) {
+			cfg.Policies[0].Detectors = []DetectorConfig{{Type: "links", Params: []byte(`{"max_links": "два"}`)}}
+		}, wantErr: true},
+		{name: "Нет файла модели классификатора", modify: func(cfg *Config) {
+			cfg.ClassifierModel = "missing_model.json"
+			cfg.Policies[0].Classifier = &ClassifierConfig{Weight: 1, Threshold: 0.8}
+		}, wantErr: true},
+		{name: "Модель не нужна политикам", modify: func(cfg *Config) { cfg.ClassifierModel = "missing_model.json" }},
+		{name: "Нет порога отклонения", modify: func(cfg *Config) { cfg.Policies[0].DenyThreshold = 0 }, wantErr: true},
+		{name: "Отрицательный порог отклонения", modify: func(cfg *Config) { cfg.Policies[0].DenyThreshold = -1 }, wantErr: true},
+		{name: "Порог проверки выше порога отклонения", modify: func(cfg *Config) { cfg.Policies[0].ReviewThreshold = 1.5 }, wantErr: true},
+		{name: "Равные пороги", modify: func(cfg *Config) { cfg.Policies[0].ReviewThreshold = 1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			tt.modify(&cfg)
+			if _, err := New(cfg); (err != nil) != tt.wantErr {
+				t.Errorf("Неверный результат: ожидается ошибка %v, получено %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPolicy_decide(t *testing.T) {
+	p := newTestCensor(t).policies["default"]
+
+	tests := []struct {
+		score float64
+		want  Decision
+	}{
+		{score: 0, want: DecisionAllow},
+		{score: 0.4, want: DecisionAllow},
+		{score: 0.5, want: DecisionReview},
+		{score: 0.99, want: DecisionReview},
+		{score: 1, want: DecisionDeny},
+		{score: 2.5, want: DecisionDeny},
+	}
+	for _, tt := range tests {
+		if got := p.decide(tt.score); got != tt.want {
+			t.Errorf("Оценка %v: ожидается %v, получено %v", tt.score, tt.want, got)
+		}
+	}
+}
+
+func TestCensor_mask(t *testing.T) {
+	c := newTestCensor(t)
+
+	tests := []struct {
+		name          string
+		text          string
+		wantDecision  Decision
+		wantSanitized string
+	}{
+		{name: "Чистый текст", text: "Отличная статья", wantDecision: DecisionAllow, wantSanitized: "Отличная статья"},
+		{name: "Запрещенное слово", text: "Это QWERTY!", wantDecision: DecisionDeny, wantSanitized: "Это ######!"},
+		{name: "Пограничный случай", text: "Сам дурак", wantDecision: DecisionReview, wantSanitized: "Сам #####"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := c.Check("mask", tt.text)
+			if err != nil {
+				t.Fatalf("Ошибка проверки: %v", err)
+			}
+			if v.Action != ActionMask || v.Decision != tt.wantDecision {
+				t.Errorf("Неверный вердикт: ожидается %v/%v, получено %v/%v", ActionMask, tt.wantDecision, v.Action, v.Decision)
+			}
+			if v.SanitizedText != tt.wantSanitized {
+				t.Errorf("Неверный текст: ожидается %q, получено %q", tt.wantSanitized, v.SanitizedText)
+			}
+		})
+	}
+
+	// По умолчанию комментарий с нарушениями отклоняется, текст не маскируется
+	if v, _ := c.Check("", "qwerty"); v.Action != ActionReject || v.SanitizedText != "" {
+		t.Errorf("Неверный вердикт по умолчанию: %+v", v)
+	}
+}
+
+// TestCensor_ModelVersion проверяет, что модель классификатора входит в версию политики.
+func TestCensor_ModelVersion(t *testing.T) {
+	dir := t.TempDir()
+	version := func(samples ...Sample) string {
+		t.Helper()
+		path := filepath.Join(dir, "model.json")
+		if err := TrainNaiveBayes(samples).Save(path); err != nil {
+			t.Fatal(err)
+		}
+		cfg := DefaultConfig()
+		cfg.ClassifierModel = path
+		cfg.Policies[0].Classifier = &ClassifierConfig{Weight: 1, Threshold: 0.8}
+		c, err := New(cfg)
+		if err != nil {
+			t.Fatalf("Ошибка создания цензора: %v", err)
+		}
+		v, _ := c.Check("", "текст")
+		return v.Version
+	}
+
+	first := version(Sample{Text: "ты дурак", Toxic: true}, Sample{Text: "спасибо", Toxic: false})
+	same := version(Sample{Text: "ты дурак", Toxic: true}, Sample{Text: "спасибо", Toxic: false})
+	retrained := version(Sample{Text: "ты дурак", Toxic: true}, Sample{Text: "спасибо за статью", Toxic: false})
+	if first != same {
+		t.Errorf("Версия с той же моделью изменилась: %s, %s", first, same)
+	}
+	if first == retrained {
+		t.Errorf("Версия не изменилась после переобучения модели: %s", first)
+	}
+}
+
+func TestCensor_Language(t *testing.T) {
+	c, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatalf("Ошибка создания цензора: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		text         string
+		wantLanguage string
+		wantRules    []string
+	}{
+		{name: "Русский текст", text: "Спасибо за интересную статью", wantLanguage: "ru", wantRules: []string{}},
+		{name: "Английский текст", text: "Thanks for the interesting article", wantLanguage: "en", wantRules: []string{}},
+		{name: "Без слов", text: "!!! 123 ???", wantLanguage: LanguageUnknown, wantRules: []string{}},
+		{name: "Английское слово в русском тексте", text: "Какая же это qwerty новость", wantLanguage: "ru", wantRules: []string{"word-qwerty"}},
+		{name: "Латинские двойники в русском слове", text: "Ну ты и йцyкeн", wantLanguage: "ru", wantRules: []string{"word-jcuken"}},
+		{name: "Кириллические двойники в английском слове", text: "What a qwеrtу", wantLanguage: "en", wantRules: []string{"word-qwerty"}},
+		{name: "Цифры вместо букв", text: "zxvbnm and qw3r7y", wantLanguage: "en", wantRules: []string{"word-qwerty", "word-zxvbnm"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := c.Check("", tt.text)
+			if err != nil {
+				t.Fatalf("Ошибка проверки: %v", err)
+			}
+			if v.Language != tt.wantLanguage {
+				t.Errorf("Неверный язык: ожидается %v, получен %v", tt.wantLanguage, v.Language)
+			}
+			if !reflect.DeepEqual(v.Rules, tt.wantRules) {
+				t.Errorf("Неверные правила: ожидается %v, получено %v", tt.wantRules, v.Rules)
+			}
+		})
+	}
+
+	bad := DefaultConfig()
+	bad.Languages = []Language{{Code: "xx"}}
+	if _, err := New(bad); err == nil {
+		t.Errorf("Ожидалась ошибка для языка без профиля")
+	}
+}
+
+func TestMasking_Mask(t *testing.T) {
+	matches := []Match{{Start: 4, End: 10}, {Start: 8, End: 12}}
+	tests := []struct {
+		name    string
+		masking Masking
+		want    string
+	}{
+		{name: "С сохранением длины", masking: Masking{PreserveLength: true}, want: "это ******** тут"},
+		{name: "Фиксированная длина", masking: Masking{Char: "#", Length: 3}, want: "это ### тут"},
+		{name: "Длина по умолчанию", masking: Masking{}, want: "это **** тут"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.masking.Mask("это qwertyйц тут", matches); got != tt.want {
+				t.Errorf("Неверный результат: ожидается %q, получено %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDetectors(t *testing.T) {
+	tests := []struct {
+		name      string
+		detector  DetectorConfig
+		text      string
+		wantScore float64
+	}{
+		{name: "Запрещенный домен", detector: DetectorConfig{Type: "links", Params: []byte(`{"deny_domains": ["spam.test"]}`)}, text: "см. https://www.spam.test/x", wantScore: 1},
+		{name: "Разрешенный домен", detector: DetectorConfig{Type: "links", Params: []byte(`{"max_links": 0, "allow_domains": ["news.test"]}`)}, text: "см. https://news.test/x", wantScore: 0},
+		{name: "Повторы", detector: DetectorConfig{Type: "repetition"}, text: "аааааааа!!!!!", wantScore: 1},
+		{name: "Без повторов", detector: DetectorConfig{Type: "repetition"}, text: "Отличная статья", wantScore: 0},
+		{name: "Капс", detector: DetectorConfig{Type: "caps"}, text: "ЭТО ВОЗМУТИТЕЛЬНО", wantScore: 1},
+		{name: "Короткий капс", detector: DetectorConfig{Type: "caps"}, text: "OK", wantScore: 0},
+		{name: "Обычный текст", detector: DetectorConfig{Type: "caps"}, text: "Москва и Санкт-Петербург", wantScore: 0},
+		{name: "Телефон", detector: DetectorConfig{Type: "contacts"}, text: "звоните 8 800 555-35-35", wantScore: 1},
+		{name: "Почта", detector: DetectorConfig{Type: "contacts"}, text: "пишите a@b.test", wantScore: 1},
+		{name: "Почта не проверяется", detector: DetectorConfig{Type: "contacts", Params: []byte(`{"emails": false}`)}, text: "пишите a@b.test", wantScore: 0},
+		{name: "Допустимое число ссылок", detector: DetectorConfig{Type: "links", Params: []byte(`{"max_links": 2}`)}, text: "https://a.test https://b.test", wantScore: 1.0 / 3},
+		{name: "Одна ссылка из двух допустимых", detector: DetectorConfig{Type: "links", Params: []byte(`{"max_links": 2}`)}, text: "https://a.test", wantScore: 0},
+		{name: "Ссылок больше допустимого", detector: DetectorConfig{Type: "links", Params: []byte(`{"max_links": 2}`)}, text: "https://a.test https://b.test https://c.test https://d.test", wantScore: 1},
+		{name: "Половина заглавных букв", detector: DetectorConfig{Type: "caps", Params: []byte(`{"max_ratio": 0.8}`)}, text: "ОЧЕНЬ плохо!", wantScore: 0.25},
+		{name: "Один контакт из двух допустимых", detector: DetectorConfig{Type: "contacts", Params: []byte(`{"max_contacts": 1}`)}, text: "пишите a@b.test", wantScore: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := newDetector(tt.detector)
+			if err != nil {
+				t.Fatalf("Ошибка создания детектора: %v", err)
+			}
+			if got := d.Detect(tt.text).Score; math.Abs(got-tt.wantScore) > 1e-9 {
+				t.Errorf("Неверная оценка: ожидается %v, получено %v", tt.wantScore, got)
+			}
+		})
+	}
+}
+
+func TestCensor_detectors(t *testing.T) {
+	cfg := testConfig()
+	cfg.Policies[0].Detectors = []DetectorConfig{
+		{Type: "contacts", Weight: 0.5},
+		{Type: "caps", Weight: 0.5},
+	}
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Ошибка создания цензора: %v", err)
+	}
+
+	v, err := c.Check("", "Пишите a@b.test")
+	if err != nil {
+		t.Fatalf("Ошибка проверки: %v", err)
+	}
+	if v.Decision != DecisionReview || v.Score != 0.5 {
+		t.Errorf("Неверный вердикт: ожидается %v с оценкой 0.5, получено %v с оценкой %v", DecisionReview, v.Decision, v.Score)
+	}
+	if len(v.Signals) != 1 || v.Signals[0].Detector != "contacts" || v.Signals[0].Weight != 0.5 {
+		t.Errorf("Неверные сигналы: %+v", v.Signals)
+	}
+	want := []Match{{RuleID: "detector:email", Category: "contacts", Fragment: "a@b.test", Start: 7, End: 15}}
+	if !reflect.DeepEqual(v.Matches, want) {
+		t.Errorf("Неверные совпадения: ожидается %+v, получено %+v", want, v.Matches)
+	}
+	if !reflect.DeepEqual(v.Categories, []string{"contacts"}) {
+		t.Errorf("Неверные категории: %v", v.Categories)
+	}
+}
+
+// TestNaiveBayes_Model проверяет модель из data/toxicity_model.json. В config.json
+// классификатор выключен: модель дает ложные срабатывания на резкую, но допустимую
+// критику, например "Your article is wrong", поэтому оскорбления без слов из словаря
+// ловит только политика, в которой классификатор включен явно.
+func TestNaiveBayes_Model(t *testing.T) {
+	nb, err := LoadNaiveBayes("../../data/toxicity_model.json")
+	if err != nil {
+		t.Fatalf("Ошибка загрузки модели: %v", err)
+	}
+	tests := []struct {
+		text  string
+		toxic bool
+	}{
+		{text: "Автор жалкий неудачник и врун", toxic: true},
+		{text: "Закрой рот, тебя никто не спрашивал", toxic: true},
+		{text: "You are a pathetic loser", toxic: true},
+		{text: "Отличная статья, спасибо автору", toxic: false},
+		{text: "Great article, thanks for sharing", toxic: false},
+	}
+	for _, tt := range tests {
+		score := nb.Classify(tt.text)
+		if got := score >= 0.7; got != tt.toxic {
+			t.Errorf("%q: ожидается токсичность %v, оценка %.3f", tt.text, tt.toxic, score)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig("../../cmd/config.json")
+	if err != nil {
+		t.Fatalf("Ошибка чтения конфигурации: %v", err)
+	}
+	// Путь к модели отсчитывается от каталога файла конфигурации, а не от рабочего каталога
+	want := filepath.Join("../../cmd", "../data/toxicity_model.json")
+	if cfg.ClassifierModel != want {
+		t.Errorf("Путь к модели: ожидается %q, получено %q", want, cfg.ClassifierModel)
+	}
+	if _, err := os.Stat(cfg.ClassifierModel); err != nil {
+		t.Errorf("Модель классификатора не найдена: %v", err)
+	}
+}
+
+// TestCorpus проверяет конфигурацию сервиса на золотом корпусе.
+func TestCorpus(t *testing.T) {
+	const minScore = 0.9
+
+	cfg, err := LoadConfig("../../cmd/config.json")
+	if err != nil {
+		t.Fatalf("Ошибка чтения конфигурации: %v", err)
+	}
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Ошибка создания цензора: %v", err)
+	}
+	f, err := os.Open("testdata/corpus.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	entries, err := ReadCorpus(f)
+	if err != nil {
+		t.Fatalf("Ошибка чтения корпуса: %v", err)
+	}
+
+	reports, err := RunCorpus(c, entries)
+	if err != nil {
+		t.Fatalf("Ошибка прогона корпуса: %v", err)
+	}
+	for _, rep := range reports {
+		t.Logf("%s: precision=%.3f recall=%.3f exact=%d/%d", rep.Policy,
+			rep.Metrics.Precision(), rep.Metrics.Recall(), rep.Exact, rep.Total())
+		if rep.Metrics.Precision() < minScore || rep.Metrics.Recall() < minScore || rep.ExactRate() < minScore {
+			for _, f := range rep.Failures {
+				t.Logf("  ожидалось %s, получено %s: %q", f.Entry.Expected, f.Got, f.Entry.Text)
+			}
+			t.Errorf("Качество политики %s ниже %.2f", rep.Policy, minScore)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package censor
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Classifier - модель, оценивающая токсичность текста целиком,
+// а не по отдельным словам из словаря.
+type Classifier interface {
+	// Classify возвращает вероятность того, что текст токсичен, от 0 до 1.
+	Classify(text string) float64
+}
+
+// ClassifierConfig - использование классификатора в политике.
+type ClassifierConfig struct {
+	Weight    float64 `json:"weight"`    // вклад классификатора в итоговую оценку
+	Threshold float64 `json:"threshold"` // вероятность, начиная с которой оценка учитывается
+}
+
+// Метки обучающей выборки
+const (
+	LabelToxic = "toxic"
+	LabelClean = "clean"
+)
+
+// Sample - размеченный пример для обучения и оценки классификатора.
+type Sample struct {
+	Text  string
+	Toxic bool
+}
+
+// ReadSamples читает размеченные примеры из CSV с колонками text,label.
+// Метка toxic (а также 1 и true) означает токсичный текст, остальные - нормальный.
+// Первая строка считается заголовком, если ее вторая колонка равна "label".
+func ReadSamples(r io.Reader) ([]Sample, error) {
+	cr := csv.NewReader(bufio.NewReader(r))
+	cr.FieldsPerRecord = 2
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения выборки: %w", err)
+	}
+	if len(records) > 0 && records[0][1] == "label" {
+		records = records[1:]
+	}
+	samples := make([]Sample, 0, len(records))
+	for _, rec := range records {
+		label := strings.ToLower(strings.TrimSpace(rec[1]))
+		samples = append(samples, Sample{
+			Text:  rec[0],
+			Toxic: label == LabelToxic || label == "1" || label == "true",
+		})
+	}
+	return samples, nil
+}
+
+// NaiveBayes - мультиномиальный наивный байесовский классификатор
+// со сглаживанием Лапласа. Полностью офлайновый, модель хранится в JSON.
+type NaiveBayes struct {
+	Docs   map[string]int            `json:"docs"`   // число документов по меткам
+	Tokens map[string]int            `json:"tokens"` // число токенов по меткам
+	Counts map[string]map[string]int `json:"counts"` // частоты токенов по меткам
+	Vocab  int                       `json:"vocab"`  // размер словаря
+}
+
+// TrainNaiveBayes обучает классификатор на размеченных примерах.
+func TrainNaiveBayes(samples []Sample) *NaiveBayes {
+	nb := NaiveBayes{
+		Docs:   map[string]int{},
+		Tokens: map[string]int{},
+		Counts: map[string]map[string]int{LabelToxic: {}, LabelClean: {}},
+	}
+	vocab := map[string]bool{}
+	for _, s := range samples {
+		label := LabelClean
+		if s.Toxic {
+			label = LabelToxic
+		}
+		nb.Docs[label]++
+		for _, tok := range tokenize(s.Text) {
+			nb.Counts[label][tok]++
+			nb.Tokens[label]++
+			vocab[tok] = true
+		}
+	}
+	nb.Vocab = len(vocab)
+	return &nb
+}
+
+// LoadNaiveBayes загружает модель из файла.
+func LoadNaiveBayes(path string) (*NaiveBayes, error) {
+	nb, _, err := loadModel(path)
+	return nb, err
+}
+
+// loadModel загружает модель из файла и возвращает хеш содержимого файла,
+// который входит в версию политик, использующих классификатор.
+func loadModel(path string) (*NaiveBayes, string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("ошибка чтения модели классификатора (classifier_model): %w", err)
+	}
+	var nb NaiveBayes
+	if err := json.Unmarshal(b, &nb); err != nil {
+		return nil, "", fmt.Errorf("ошибка разбора модели классификатора %s: %w", path, err)
+	}
+	sum := sha256.Sum256(b)
+	return &nb, hex.EncodeToString(sum[:]), nil
+}
+
+// modelHash возвращает хеш готового классификатора, если его можно
+// сохранить в JSON, как NaiveBayes, иначе пустую строку.
+func modelHash(c Classifier) string {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// Save сохраняет модель в файл.
+func (nb *NaiveBayes) Save(path string) error {
+	b, err := json.Marshal(nb)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0644)
+}
+
+// Classify возвращает вероятность токсичности текста.
+func (nb *NaiveBayes) Classify(text string) float64 {
+	total := nb.Docs[LabelToxic] + nb.Docs[LabelClean]
+	if total == 0 || nb.Docs[LabelToxic] == 0 || nb.Docs[LabelClean] == 0 {
+		return 0
+	}
+	toxic := math.Log(float64(nb.Docs[LabelToxic]) / float64(total))
+	clean := math.Log(float64(nb.Docs[LabelClean]) / float64(total))
+	for _, tok := range tokenize(text) {
+		toxic += nb.logLikelihood(LabelToxic, tok)
+		clean += nb.logLikelihood(LabelClean, tok)
+	}
+	// P(toxic) = 1 / (1 + exp(clean - toxic))
+	return 1 / (1 + math.Exp(clean-toxic))
+}
+
+// logLikelihood возвращает логарифм сглаженной вероятности токена для метки.
+func (nb *NaiveBayes) logLikelihood(label, tok string) float64 {
+	return math.Log(float64(nb.Counts[label][tok]+1) / float64(nb.Tokens[label]+nb.Vocab+1))
+}
+
+// tokenize разбивает текст на слова в нижнем регистре.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Metrics - качество классификатора на размеченной выборке.
+type Metrics struct {
+	TruePositive  int
+	FalsePositive int
+	TrueNegative  int
+	FalseNegative int
+}
+
+// Evaluate оценивает классификатор на выборке при заданном пороге вероятности.
+func Evaluate(c Classifier, samples []Sample, threshold float64) Metrics {
+	var m Metrics
+	for _, s := range samples {
+		predicted := c.Classify(s.Text) >= threshold
+		switch {
+		case predicted && s.Toxic:
+			m.TruePositive++
+		case predicted && !s.Toxic:
+			m.FalsePositive++
+		case !predicted && s.Toxic:
+			m.FalseNegative++
+		default:
+			m.TrueNegative++
+		}
+	}
+	return m
+}
+
+// Precision - доля действительно токсичных среди отмеченных как токсичные.
+func (m Metrics) Precision() float64 {
+	return ratio(m.TruePositive, m.TruePositive+m.FalsePositive)
+}
+
+// Recall - доля найденных среди всех токсичных.
+func (m Metrics) Recall() float64 {
+	return ratio(m.TruePositive, m.TruePositive+m.FalseNegative)
+}
+
+// Accuracy - доля верных ответов.
+func (m Metrics) Accuracy() float64 {
+	return ratio(m.TruePositive+m.TrueNegative, m.TruePositive+m.TrueNegative+m.FalsePositive+m.FalseNegative)
+}
+
+// F1 - среднее гармоническое точности и полноты.
+func (m Metrics) F1() float64 {
+	p, r := m.Precision(), m.Recall()
+	if p+r == 0 {
+		return 0
+	}
+	return 2 * p * r / (p + r)
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
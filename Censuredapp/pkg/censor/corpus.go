@@ -0,0 +1,126 @@
+package censor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CorpusEntry - размеченный комментарий золотого корпуса.
+type CorpusEntry struct {
+	Text     string   `json:"text"`
+	Policy   string   `json:"policy"`   // политика, пустая - по умолчанию
+	Expected Decision `json:"expected"` // ожидаемое решение
+}
+
+// ReadCorpus читает корпус в формате JSON Lines.
+// Пустые строки и строки, начинающиеся с #, пропускаются.
+func ReadCorpus(r io.Reader) ([]CorpusEntry, error) {
+	var entries []CorpusEntry
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var e CorpusEntry
+		if err := json.Unmarshal([]byte(text), &e); err != nil {
+			return nil, fmt.Errorf("строка %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// CorpusFailure - комментарий, решение по которому не совпало с ожидаемым.
+type CorpusFailure struct {
+	Entry CorpusEntry
+	Got   Decision
+}
+
+// CorpusReport - результат прогона корпуса по одной политике.
+// Положительным считается любое решение, кроме allow.
+type CorpusReport struct {
+	Policy   string
+	Metrics  Metrics
+	Exact    int // число точно совпавших решений
+	Failures []CorpusFailure
+}
+
+// Total возвращает число проверенных комментариев.
+func (r *CorpusReport) Total() int {
+	m := r.Metrics
+	return m.TruePositive + m.FalsePositive + m.TrueNegative + m.FalseNegative
+}
+
+// ExactRate возвращает долю точно совпавших решений.
+func (r *CorpusReport) ExactRate() float64 {
+	return ratio(r.Exact, r.Total())
+}
+
+// RunCorpus проверяет корпус цензором и возвращает отчеты по политикам,
+// отсортированные по имени политики.
+func RunCorpus(c *Censor, entries []CorpusEntry) ([]*CorpusReport, error) {
+	reports := map[string]*CorpusReport{}
+	for _, e := range entries {
+		v, err := c.Check(e.Policy, e.Text)
+		if err != nil {
+			return nil, err
+		}
+		rep, ok := reports[v.Policy]
+		if !ok {
+			rep = &CorpusReport{Policy: v.Policy}
+			reports[v.Policy] = rep
+		}
+
+		expected, got := e.Expected != DecisionAllow, v.Decision != DecisionAllow
+		switch {
+		case expected && got:
+			rep.Metrics.TruePositive++
+		case !expected && got:
+			rep.Metrics.FalsePositive++
+		case expected && !got:
+			rep.Metrics.FalseNegative++
+		default:
+			rep.Metrics.TrueNegative++
+		}
+		if v.Decision == e.Expected {
+			rep.Exact++
+		} else {
+			rep.Failures = append(rep.Failures, CorpusFailure{Entry: e, Got: v.Decision})
+		}
+	}
+
+	res := make([]*CorpusReport, 0, len(reports))
+	for _, rep := range reports {
+		res = append(res, rep)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Policy < res[j].Policy })
+	return res, nil
+}
+
+// LoadConfig читает раздел censor файла конфигурации сервиса.
+// Относительный путь к модели классификатора отсчитывается от каталога файла конфигурации.
+func LoadConfig(path string) (Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	var file struct {
+		Censor Config `json:"censor"`
+	}
+	if err := json.Unmarshal(b, &file); err != nil {
+		return Config{}, fmt.Errorf("ошибка разбора %s: %w", path, err)
+	}
+	cfg := file.Censor
+	if len(cfg.Policies) == 0 {
+		return DefaultConfig(), nil
+	}
+	cfg.ResolvePaths(filepath.Dir(path))
+	return cfg, nil
+}
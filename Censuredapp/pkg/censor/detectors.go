@@ -0,0 +1,279 @@
+package censor
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Detector - эвристика, оценивающая текст по признаку, не сводящемуся к словарю
+// (ссылки, повторы, капс и т.п.).
+type Detector interface {
+	// Detect возвращает оценку текста от 0 (признак отсутствует) до 1.
+	Detect(text string) Signal
+}
+
+// Signal - результат работы детектора.
+type Signal struct {
+	Detector string  `json:"detector"`
+	Score    float64 `json:"score"`  // оценка детектора от 0 до 1
+	Weight   float64 `json:"weight"` // вес детектора в политике
+	Reason   string  `json:"reason,omitempty"`
+	Matches  []Match `json:"-"` // найденные фрагменты, попадают в общий список совпадений
+}
+
+// DetectorConfig - описание детектора в политике.
+type DetectorConfig struct {
+	Type   string          `json:"type"`   // тип детектора: links, repetition, caps, contacts
+	Weight float64         `json:"weight"` // вклад детектора в итоговую оценку
+	Params json.RawMessage `json:"params"` // параметры, специфичные для типа
+}
+
+// DetectorFactory создает детектор по параметрам из конфигурации.
+type DetectorFactory func(params json.RawMessage) (Detector, error)
+
+// detectorFactories - зарегистрированные типы детекторов.
+var detectorFactories = map[string]DetectorFactory{
+	"links":      newLinksDetector,
+	"repetition": newRepetitionDetector,
+	"caps":       newCapsDetector,
+	"contacts":   newContactsDetector,
+}
+
+// RegisterDetector регистрирует новый тип детектора.
+// Должна вызываться до создания цензора.
+func RegisterDetector(typ string, factory DetectorFactory) {
+	detectorFactories[typ] = factory
+}
+
+// newDetector создает детектор по описанию из конфигурации.
+func newDetector(cfg DetectorConfig) (Detector, error) {
+	factory, ok := detectorFactories[cfg.Type]
+	if !ok {
+		return nil, fmt.Errorf("неизвестный тип детектора %q", cfg.Type)
+	}
+	return factory(cfg.Params)
+}
+
+// grade переводит значение признака в оценку от 0 до 1. Значения меньше
+// половины предела limit не учитываются, дальше оценка растет линейно
+// и достигает 1 на пределе: текст с признаком на пороге получает
+// частичную оценку, а не 0.
+func grade(value, limit float64) float64 {
+	if limit <= 0 {
+		if value > 0 {
+			return 1
+		}
+		return 0
+	}
+	return math.Max(0, math.Min(1, (value-limit/2)/(limit/2)))
+}
+
+// decodeParams раскодирует параметры детектора поверх значений по умолчанию.
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+	return json.Unmarshal(params, v)
+}
+
+// linksDetector оценивает количество ссылок и их домены.
+type linksDetector struct {
+	MaxLinks     int      `json:"max_links"`     // допустимое число ссылок на недоверенные домены
+	AllowDomains []string `json:"allow_domains"` // доверенные домены, ссылки на них не учитываются
+	DenyDomains  []string `json:"deny_domains"`  // запрещенные домены, любая ссылка дает оценку 1
+}
+
+var linkRe = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"']+`)
+
+func newLinksDetector(params json.RawMessage) (Detector, error) {
+	d := linksDetector{MaxLinks: 1}
+	if err := decodeParams(params, &d); err != nil {
+		return nil, fmt.Errorf("детектор links: %w", err)
+	}
+	return &d, nil
+}
+
+func (d *linksDetector) Detect(text string) Signal {
+	var s Signal
+	links := 0
+	for _, loc := range linkRe.FindAllStringIndex(text, -1) {
+		link := text[loc[0]:loc[1]]
+		host := linkHost(link)
+		if domainIn(host, d.AllowDomains) {
+			continue
+		}
+		s.Matches = append(s.Matches, runeMatch(text, loc, "detector:links", "spam"))
+		if domainIn(host, d.DenyDomains) {
+			s.Score = 1
+			s.Reason = fmt.Sprintf("ссылка на запрещенный домен %s", host)
+			continue
+		}
+		links++
+	}
+	// Оценка растет с числом ссылок и равна 1, когда их больше допустимого
+	if score := grade(float64(links), float64(d.MaxLinks+1)); s.Score < 1 && score > 0 {
+		s.Score = score
+		s.Reason = fmt.Sprintf("ссылок: %d, допустимо: %d", links, d.MaxLinks)
+	}
+	if s.Score == 0 {
+		s.Matches = nil
+	}
+	return s
+}
+
+// linkHost извлекает имя хоста из найденной ссылки.
+func linkHost(link string) string {
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// domainIn проверяет, совпадает ли хост с одним из доменов или является его поддоменом.
+func domainIn(host string, domains []string) bool {
+	for _, d := range domains {
+		d = strings.ToLower(d)
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// repetitionDetector оценивает долю символов, входящих в серии повторов ("ааааа", "!!!!!").
+type repetitionDetector struct {
+	MinRun   int     `json:"min_run"`   // минимальная длина серии одинаковых символов
+	MaxRatio float64 `json:"max_ratio"` // допустимая доля символов в сериях
+}
+
+func newRepetitionDetector(params json.RawMessage) (Detector, error) {
+	d := repetitionDetector{MinRun: 4, MaxRatio: 0.3}
+	if err := decodeParams(params, &d); err != nil {
+		return nil, fmt.Errorf("детектор repetition: %w", err)
+	}
+	return &d, nil
+}
+
+func (d *repetitionDetector) Detect(text string) Signal {
+	runes := lowerRunes(text)
+	if len(runes) == 0 {
+		return Signal{}
+	}
+	repeated := 0
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && runes[j] == runes[i] {
+			j++
+		}
+		if j-i >= d.MinRun && !unicode.IsSpace(runes[i]) {
+			repeated += j - i
+		}
+		i = j
+	}
+	ratio := float64(repeated) / float64(len(runes))
+	score := grade(ratio, d.MaxRatio)
+	if score == 0 {
+		return Signal{}
+	}
+	return Signal{Score: score, Reason: fmt.Sprintf("доля повторов %.2f", ratio)}
+}
+
+// capsDetector оценивает долю заглавных букв ("крик").
+type capsDetector struct {
+	MinLetters int     `json:"min_letters"` // минимальное число букв, при котором проверка имеет смысл
+	MaxRatio   float64 `json:"max_ratio"`   // допустимая доля заглавных букв
+}
+
+func newCapsDetector(params json.RawMessage) (Detector, error) {
+	d := capsDetector{MinLetters: 10, MaxRatio: 0.7}
+	if err := decodeParams(params, &d); err != nil {
+		return nil, fmt.Errorf("детектор caps: %w", err)
+	}
+	return &d, nil
+}
+
+func (d *capsDetector) Detect(text string) Signal {
+	letters, upper := 0, 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.IsUpper(r) {
+			upper++
+		}
+	}
+	if letters < d.MinLetters {
+		return Signal{}
+	}
+	ratio := float64(upper) / float64(letters)
+	score := grade(ratio, d.MaxRatio)
+	if score == 0 {
+		return Signal{}
+	}
+	return Signal{Score: score, Reason: fmt.Sprintf("доля заглавных букв %.2f", ratio)}
+}
+
+// contactsDetector ищет в тексте телефоны и адреса электронной почты.
+type contactsDetector struct {
+	Phones      bool `json:"phones"`
+	Emails      bool `json:"emails"`
+	MaxContacts int  `json:"max_contacts"` // допустимое число контактов, по умолчанию 0
+}
+
+var (
+	emailRe = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	phoneRe = regexp.MustCompile(`\+?\d[\d\s().-]{8,}\d`)
+)
+
+func newContactsDetector(params json.RawMessage) (Detector, error) {
+	d := contactsDetector{Phones: true, Emails: true}
+	if err := decodeParams(params, &d); err != nil {
+		return nil, fmt.Errorf("детектор contacts: %w", err)
+	}
+	return &d, nil
+}
+
+func (d *contactsDetector) Detect(text string) Signal {
+	var s Signal
+	if d.Emails {
+		for _, loc := range emailRe.FindAllStringIndex(text, -1) {
+			s.Matches = append(s.Matches, runeMatch(text, loc, "detector:email", "contacts"))
+		}
+	}
+	if d.Phones {
+		for _, loc := range phoneRe.FindAllStringIndex(text, -1) {
+			s.Matches = append(s.Matches, runeMatch(text, loc, "detector:phone", "contacts"))
+		}
+	}
+	s.Score = grade(float64(len(s.Matches)), float64(d.MaxContacts+1))
+	if s.Score == 0 {
+		s.Matches = nil
+		return s
+	}
+	s.Reason = fmt.Sprintf("найдено контактов: %d", len(s.Matches))
+	return s
+}
+
+// runeMatch преобразует байтовые границы совпадения регулярного выражения
+// в совпадение со смещениями в символах.
+func runeMatch(text string, loc []int, ruleID, category string) Match {
+	start := utf8.RuneCountInString(text[:loc[0]])
+	return Match{
+		RuleID:   ruleID,
+		Category: category,
+		Fragment: text[loc[0]:loc[1]],
+		Start:    start,
+		End:      start + utf8.RuneCountInString(text[loc[0]:loc[1]]),
+	}
+}
@@ -0,0 +1,254 @@
+package censor
+
+import (
+	"embed"
+	"fmt"
+	"math"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// LanguageUnknown - язык текста, который не удалось определить (нет ни одного слова).
+const LanguageUnknown = "und"
+
+// maxNgram - наибольшая длина n-граммы в профилях языков.
+const maxNgram = 3
+
+// Language - язык комментариев и правила нормализации текста для него.
+type Language struct {
+	Code string `json:"code"` // код языка ISO 639-1, для него должен быть встроенный профиль
+	// Normalize - замены символов в словах этого языка перед поиском по словарям,
+	// например латинских двойников кириллицей: {"a": "а", "ё": "е"}.
+	// Ключ и значение - ровно один символ, чтобы смещения совпадений не менялись.
+	Normalize map[string]string `json:"normalize"`
+}
+
+// DefaultLanguages возвращает русский и английский языки с заменой
+// похожих символов другой письменности и цифр, которыми маскируют буквы.
+func DefaultLanguages() []Language {
+	return []Language{
+		{Code: "ru", Normalize: map[string]string{
+			"ё": "е", "a": "а", "b": "в", "c": "с", "e": "е", "h": "н", "k": "к", "m": "м",
+			"o": "о", "p": "р", "t": "т", "x": "х", "y": "у", "0": "о", "3": "з", "6": "б",
+		}},
+		{Code: "en", Normalize: map[string]string{
+			"а": "a", "в": "b", "с": "c", "е": "e", "н": "h", "к": "k", "м": "m", "о": "o",
+			"р": "p", "т": "t", "х": "x", "у": "y", "0": "o", "1": "i", "3": "e", "4": "a",
+			"5": "s", "7": "t",
+		}},
+	}
+}
+
+// langdata содержит тексты-образцы, по которым строятся профили языков:
+// файл <код языка>.txt на каждый поддерживаемый язык.
+//
+//go:embed langdata/*.txt
+var langdata embed.FS
+
+// builtinProfiles - профили встроенных языков по коду.
+var builtinProfiles = loadProfiles()
+
+// profile - логарифмы частот n-грамм в текстах языка.
+type profile struct {
+	code    string
+	logProb map[string]float64
+	unknown float64 // логарифм вероятности не встречавшейся n-граммы
+}
+
+// loadProfiles строит профили по встроенным текстам-образцам.
+func loadProfiles() map[string]*profile {
+	entries, err := langdata.ReadDir("langdata")
+	if err != nil {
+		panic(err)
+	}
+	profiles := make(map[string]*profile, len(entries))
+	for _, e := range entries {
+		b, err := langdata.ReadFile(path.Join("langdata", e.Name()))
+		if err != nil {
+			panic(err)
+		}
+		code := strings.TrimSuffix(e.Name(), ".txt")
+		profiles[code] = newProfile(code, string(b))
+	}
+	return profiles
+}
+
+// newProfile считает частоты n-грамм слов образца со сглаживанием Лапласа.
+func newProfile(code, sample string) *profile {
+	counts := make(map[string]int)
+	total := 0
+	for _, w := range splitWords(lowerRunes(sample)) {
+		for _, g := range ngrams(w.letters) {
+			counts[g]++
+			total++
+		}
+	}
+	p := profile{code: code, logProb: make(map[string]float64, len(counts))}
+	denom := float64(total + len(counts) + 1)
+	for g, n := range counts {
+		p.logProb[g] = math.Log(float64(n+1) / denom)
+	}
+	p.unknown = math.Log(1 / denom)
+	return &p
+}
+
+// score возвращает логарифм правдоподобия слова для языка.
+func (p *profile) score(grams []string) float64 {
+	var s float64
+	for _, g := range grams {
+		if lp, ok := p.logProb[g]; ok {
+			s += lp
+		} else {
+			s += p.unknown
+		}
+	}
+	return s
+}
+
+// word - слово текста: непрерывная последовательность букв и цифр.
+type word struct {
+	start, end int    // смещения в символах
+	letters    []rune // только буквы слова, по ним определяется язык
+}
+
+// splitWords разбивает текст на слова.
+func splitWords(text []rune) []word {
+	var words []word
+	for i := 0; i < len(text); {
+		if !unicode.IsLetter(text[i]) && !unicode.IsDigit(text[i]) {
+			i++
+			continue
+		}
+		w := word{start: i}
+		for ; i < len(text) && (unicode.IsLetter(text[i]) || unicode.IsDigit(text[i])); i++ {
+			if unicode.IsLetter(text[i]) {
+				w.letters = append(w.letters, text[i])
+			}
+		}
+		w.end = i
+		words = append(words, w)
+	}
+	return words
+}
+
+// ngrams возвращает n-граммы длиной от 1 до maxNgram слова, дополненного пробелами по краям.
+func ngrams(letters []rune) []string {
+	if len(letters) == 0 {
+		return nil
+	}
+	padded := make([]rune, 0, len(letters)+2)
+	padded = append(padded, ' ')
+	padded = append(padded, letters...)
+	padded = append(padded, ' ')
+
+	var grams []string
+	for n := 1; n <= maxNgram; n++ {
+		for i := 0; i+n <= len(padded); i++ {
+			if n == 1 && padded[i] == ' ' {
+				continue
+			}
+			grams = append(grams, string(padded[i:i+n]))
+		}
+	}
+	return grams
+}
+
+// languageSet - языки, между которыми выбирается язык слов текста.
+type languageSet struct {
+	languages []Language
+	profiles  []*profile
+	normalize map[string]map[rune]rune
+}
+
+// compileLanguages проверяет описания языков и готовит таблицы замен.
+func compileLanguages(langs []Language) (*languageSet, error) {
+	if len(langs) == 0 {
+		langs = DefaultLanguages()
+	}
+	ls := languageSet{languages: langs, normalize: make(map[string]map[rune]rune, len(langs))}
+	for _, l := range langs {
+		p, ok := builtinProfiles[l.Code]
+		if !ok {
+			return nil, fmt.Errorf("нет встроенного профиля языка %q", l.Code)
+		}
+		if _, dup := ls.normalize[l.Code]; dup {
+			return nil, fmt.Errorf("язык %q описан несколько раз", l.Code)
+		}
+		repl := make(map[rune]rune, len(l.Normalize))
+		for from, to := range l.Normalize {
+			if utf8.RuneCountInString(from) != 1 || utf8.RuneCountInString(to) != 1 {
+				return nil, fmt.Errorf("язык %q: замена %q -> %q должна быть посимвольной", l.Code, from, to)
+			}
+			f, _ := utf8.DecodeRuneInString(from)
+			t, _ := utf8.DecodeRuneInString(to)
+			repl[unicode.ToLower(f)] = t
+		}
+		ls.profiles = append(ls.profiles, p)
+		ls.normalize[l.Code] = repl
+	}
+	return &ls, nil
+}
+
+// has сообщает, описан ли язык.
+func (ls *languageSet) has(code string) bool {
+	_, ok := ls.normalize[code]
+	return ok
+}
+
+// normalizeRunes применяет к тексту замены указанного языка.
+func (ls *languageSet) normalizeRunes(code string, text []rune) {
+	repl := ls.normalize[code]
+	for i, r := range text {
+		if to, ok := repl[r]; ok {
+			text[i] = to
+		}
+	}
+}
+
+// classify определяет язык слова, пустая строка - в слове нет букв.
+func (ls *languageSet) classify(letters []rune) string {
+	grams := ngrams(letters)
+	if len(grams) == 0 {
+		return ""
+	}
+	best, bestScore := "", math.Inf(-1)
+	for _, p := range ls.profiles {
+		if s := p.score(grams); s > bestScore {
+			best, bestScore = p.code, s
+		}
+	}
+	return best
+}
+
+// textInfo - результат анализа текста перед поиском по словарям.
+type textInfo struct {
+	language  string          // преобладающий язык текста
+	languages map[string]bool // все языки, слова которых встречаются в тексте
+	norm      []rune          // текст в нижнем регистре после нормализации слов
+}
+
+// analyze определяет язык каждого слова текста, нормализует слова по правилам
+// их языка и выбирает преобладающий язык по числу букв.
+func (ls *languageSet) analyze(text string) textInfo {
+	info := textInfo{language: LanguageUnknown, languages: make(map[string]bool), norm: lowerRunes(text)}
+	letters := make(map[string]int)
+	for _, w := range splitWords(info.norm) {
+		code := ls.classify(w.letters)
+		if code == "" {
+			continue
+		}
+		info.languages[code] = true
+		letters[code] += len(w.letters)
+		ls.normalizeRunes(code, info.norm[w.start:w.end])
+	}
+	most := 0
+	for _, p := range ls.profiles {
+		if letters[p.code] > most {
+			most = letters[p.code]
+			info.language = p.code
+		}
+	}
+	return info
+}
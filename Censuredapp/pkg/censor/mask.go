@@ -0,0 +1,81 @@
+package censor
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Action - действие, применяемое к комментарию с найденными нарушениями.
+type Action string
+
+const (
+	ActionReject Action = "reject" // комментарий отклоняется целиком
+	ActionMask   Action = "mask"   // найденные фрагменты заменяются символом маски
+)
+
+// Параметры маскирования по умолчанию
+const (
+	defaultMaskChar   = '*'
+	defaultMaskLength = 4
+)
+
+// Masking - параметры маскирования.
+type Masking struct {
+	Char           string `json:"char"`            // символ маски, по умолчанию "*"
+	PreserveLength bool   `json:"preserve_length"` // сохранять длину замаскированного фрагмента
+	Length         int    `json:"length"`          // длина маски, если длина фрагмента не сохраняется
+}
+
+// Mask заменяет фрагменты текста, найденные в matches, символом маски.
+func (m Masking) Mask(text string, matches []Match) string {
+	if len(matches) == 0 {
+		return text
+	}
+
+	char, _ := utf8.DecodeRuneInString(m.Char)
+	if m.Char == "" || char == utf8.RuneError {
+		char = defaultMaskChar
+	}
+	length := m.Length
+	if length <= 0 {
+		length = defaultMaskLength
+	}
+
+	src := []rune(text)
+	var sb strings.Builder
+	pos := 0
+	for _, span := range mergeSpans(matches) {
+		sb.WriteString(string(src[pos:span[0]]))
+		n := length
+		if m.PreserveLength {
+			n = span[1] - span[0]
+		}
+		sb.WriteString(strings.Repeat(string(char), n))
+		pos = span[1]
+	}
+	sb.WriteString(string(src[pos:]))
+	return sb.String()
+}
+
+// mergeSpans объединяет пересекающиеся совпадения в непрерывные интервалы.
+func mergeSpans(matches []Match) [][2]int {
+	spans := make([][2]int, 0, len(matches))
+	for _, m := range matches {
+		spans = append(spans, [2]int{m.Start, m.End})
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+
+	merged := spans[:1]
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if s[0] <= last[1] {
+			if s[1] > last[1] {
+				last[1] = s[1]
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
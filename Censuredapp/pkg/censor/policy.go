@@ -0,0 +1,292 @@
+package censor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// RuleSet - именованный набор правил (словарь).
+type RuleSet struct {
+	Name string `json:"name"`
+	// Language - язык словаря: правила применяются, только если в тексте есть
+	// слова этого языка, а шаблоны нормализуются по его правилам.
+	// Пустой язык - словарь применяется к любому тексту.
+	Language string `json:"language"`
+	Rules    []Rule `json:"rules"`
+}
+
+// Policy - именованная политика цензуры: набор словарей, пороги и действие.
+type Policy struct {
+	Name            string   `json:"name"`
+	RuleSets        []string `json:"rule_sets"`        // имена используемых наборов правил
+	ReviewThreshold float64  `json:"review_threshold"` // оценка, начиная с которой нужна ручная проверка
+	DenyThreshold   float64  `json:"deny_threshold"`   // оценка, начиная с которой комментарий отклоняется
+	Action          Action   `json:"action"`           // действие при нарушениях: reject или mask
+	Masking         Masking  `json:"masking"`
+	// Detectors - эвристики, дополняющие словарь (ссылки, повторы, капс, контакты).
+	Detectors []DetectorConfig `json:"detectors"`
+	// Classifier - учет оценки классификатора токсичности, nil - не используется.
+	Classifier *ClassifierConfig `json:"classifier"`
+	// Exceptions - исключения из правил, действующие только в этой политике.
+	Exceptions []Exception `json:"exceptions"`
+}
+
+// Config - параметры цензора.
+type Config struct {
+	RuleSets      []RuleSet `json:"rule_sets"`
+	Policies      []Policy  `json:"policies"`
+	DefaultPolicy string    `json:"default_policy"` // политика для запросов без явного выбора
+	// Allowlist - разрешенные слова, подавляющие совпадения во всех политиках.
+	Allowlist []string `json:"allowlist"`
+	// ClassifierModel - путь к файлу модели наивного байесовского классификатора.
+	// Относительный путь в файле конфигурации отсчитывается от каталога этого файла,
+	// см. ResolvePaths.
+	ClassifierModel string `json:"classifier_model"`
+	// Classifier - готовый классификатор, имеет приоритет над ClassifierModel.
+	Classifier Classifier `json:"-"`
+	// Languages - языки комментариев и их нормализация, пусто - DefaultLanguages.
+	Languages []Language `json:"languages"`
+}
+
+// DefaultConfig возвращает конфигурацию со встроенным списком запрещенных слов.
+func DefaultConfig() Config {
+	return Config{
+		RuleSets: []RuleSet{
+			{
+				Name:     "default-en",
+				Language: "en",
+				Rules: []Rule{
+					{ID: "word-qwerty", Category: "profanity", Pattern: "qwerty", Weight: 1},
+					{ID: "word-zxvbnm", Category: "profanity", Pattern: "zxvbnm", Weight: 1},
+				},
+			},
+			{
+				Name:     "default-ru",
+				Language: "ru",
+				Rules: []Rule{
+					{ID: "word-jcuken", Category: "profanity", Pattern: "йцукен", Weight: 1},
+				},
+			},
+		},
+		Policies: []Policy{{
+			Name:            "default",
+			RuleSets:        []string{"default-en", "default-ru"},
+			ReviewThreshold: 0.5,
+			DenyThreshold:   1,
+			Action:          ActionReject,
+		}},
+		DefaultPolicy: "default",
+	}
+}
+
+// ResolvePaths делает относительный путь к модели классификатора
+// относительным каталогу dir файла конфигурации.
+func (c *Config) ResolvePaths(dir string) {
+	if c.ClassifierModel != "" && !filepath.IsAbs(c.ClassifierModel) {
+		c.ClassifierModel = filepath.Join(dir, c.ClassifierModel)
+	}
+}
+
+// usesClassifier сообщает, включен ли классификатор хотя бы в одной политике.
+func (c *Config) usesClassifier() bool {
+	for _, p := range c.Policies {
+		if p.Classifier != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// policy - подготовленная к проверке политика.
+type policy struct {
+	Policy
+	version    string // версия словаря и настроек политики
+	languages  []Language
+	rules      []rule
+	allow      []allowEntry
+	detectors  []weightedDetector
+	classifier Classifier
+	model      string // хеш модели классификатора
+}
+
+// weightedDetector - детектор с его типом и весом в политике.
+type weightedDetector struct {
+	Detector
+	name   string
+	weight float64
+}
+
+// compilePolicy собирает правила политики из описанных наборов.
+// model - хеш модели классификатора, он входит в версию политики.
+func compilePolicy(p Policy, sets map[string]RuleSet, languages *languageSet, classifier Classifier, model string, allowlist []string) (*policy, error) {
+	if p.Action == "" {
+		p.Action = ActionReject
+	}
+	if p.Action != ActionReject && p.Action != ActionMask {
+		return nil, fmt.Errorf("политика %q: неизвестное действие %q", p.Name, p.Action)
+	}
+	// Нулевой порог отклонял бы любой текст, а порог ручной проверки выше
+	// порога отклонения никогда бы не срабатывал
+	if p.DenyThreshold <= 0 {
+		return nil, fmt.Errorf("политика %q: порог отклонения должен быть положительным, получено %v", p.Name, p.DenyThreshold)
+	}
+	if p.ReviewThreshold > p.DenyThreshold {
+		return nil, fmt.Errorf("политика %q: порог ручной проверки %v выше порога отклонения %v", p.Name, p.ReviewThreshold, p.DenyThreshold)
+	}
+
+	compiled := policy{Policy: p, languages: languages.languages}
+	seen := make(map[string]bool)
+	for _, name := range p.RuleSets {
+		set, ok := sets[name]
+		if !ok {
+			return nil, fmt.Errorf("политика %q: неизвестный набор правил %q", p.Name, name)
+		}
+		for _, r := range set.Rules {
+			// Правило, входящее в несколько наборов, учитывается один раз
+			if r.Pattern == "" || seen[r.ID] {
+				continue
+			}
+			seen[r.ID] = true
+			// Правило набора без языка нормализуется, как текст: по языку каждого слова
+			pattern := lowerRunes(r.Pattern)
+			if set.Language != "" {
+				languages.normalizeRunes(set.Language, pattern)
+			} else {
+				pattern = languages.analyze(r.Pattern).norm
+			}
+			compiled.rules = append(compiled.rules, rule{Rule: r, language: set.Language, pattern: pattern})
+		}
+	}
+	compiled.allow = compileAllowlist(languages, allowlist, p.Exceptions)
+	for _, dc := range p.Detectors {
+		d, err := newDetector(dc)
+		if err != nil {
+			return nil, fmt.Errorf("политика %q: %w", p.Name, err)
+		}
+		compiled.detectors = append(compiled.detectors, weightedDetector{Detector: d, name: dc.Type, weight: dc.Weight})
+	}
+	if p.Classifier != nil {
+		if classifier == nil {
+			return nil, fmt.Errorf("политика %q: классификатор не загружен", p.Name)
+		}
+		compiled.classifier = classifier
+		compiled.model = model
+	}
+	compiled.version = compiled.computeVersion()
+	return &compiled, nil
+}
+
+// computeVersion вычисляет версию политики как хеш ее настроек, правил
+// и модели классификатора, поэтому любое изменение словаря или модели
+// дает новую версию.
+func (p *policy) computeVersion() string {
+	type versionRule struct {
+		Rule
+		Language string `json:"language,omitempty"`
+	}
+	rules := make([]versionRule, 0, len(p.rules))
+	for _, r := range p.rules {
+		rules = append(rules, versionRule{Rule: r.Rule, Language: r.language})
+	}
+	allow := make([]string, 0, len(p.allow))
+	for _, a := range p.allow {
+		allow = append(allow, a.source)
+	}
+	b, _ := json.Marshal(struct {
+		Policy    Policy        `json:"policy"`
+		Rules     []versionRule `json:"rules"`
+		Allow     []string      `json:"allow"`
+		Languages []Language    `json:"languages"`
+		Model     string        `json:"model,omitempty"`
+	}{p.Policy, rules, allow, p.languages, p.model})
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:6])
+}
+
+// check проверяет текст по политике.
+func (p *policy) check(text string, info textInfo) Verdict {
+	v := Verdict{
+		Policy:     p.Name,
+		Version:    p.version,
+		Language:   info.language,
+		Rules:      []string{},
+		Categories: []string{},
+		Matches:    []Match{},
+		Signals:    []Signal{},
+		Action:     p.Action,
+	}
+	matchRules(&v, p.rules, p.allow, text, info)
+	p.runDetectors(&v, text)
+	p.runClassifier(&v, text)
+	v.Decision = p.decide(v.Score)
+	if p.Action == ActionMask {
+		v.SanitizedText = p.Masking.Mask(text, v.Matches)
+	}
+	return v
+}
+
+// runDetectors применяет детекторы политики и добавляет их оценки в вердикт.
+func (p *policy) runDetectors(v *Verdict, text string) {
+	for _, d := range p.detectors {
+		s := d.Detect(text)
+		if s.Score <= 0 {
+			continue
+		}
+		s.Detector = d.name
+		s.Weight = d.weight
+		v.Signals = append(v.Signals, s)
+		v.Score += s.Score * s.Weight
+		v.Matches = append(v.Matches, s.Matches...)
+		for _, m := range s.Matches {
+			if !containsString(v.Categories, m.Category) {
+				v.Categories = append(v.Categories, m.Category)
+			}
+		}
+	}
+	sort.SliceStable(v.Matches, func(i, j int) bool { return v.Matches[i].Start < v.Matches[j].Start })
+}
+
+// runClassifier добавляет в вердикт оценку классификатора токсичности.
+func (p *policy) runClassifier(v *Verdict, text string) {
+	if p.classifier == nil {
+		return
+	}
+	prob := p.classifier.Classify(text)
+	if prob < p.Classifier.Threshold {
+		return
+	}
+	v.Signals = append(v.Signals, Signal{
+		Detector: "classifier",
+		Score:    prob,
+		Weight:   p.Classifier.Weight,
+		Reason:   fmt.Sprintf("вероятность токсичности %.2f", prob),
+	})
+	v.Score += prob * p.Classifier.Weight
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// decide определяет решение по итоговой оценке.
+func (p *policy) decide(score float64) Decision {
+	switch {
+	case score <= 0:
+		return DecisionAllow
+	case score >= p.DenyThreshold:
+		return DecisionDeny
+	case score >= p.ReviewThreshold:
+		return DecisionReview
+	default:
+		return DecisionAllow
+	}
+}
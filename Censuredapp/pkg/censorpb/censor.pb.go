@@ -0,0 +1,758 @@
+// Интерфейс сервиса цензуры комментариев.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: censor/v1/censor.proto
+
+package censorpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CheckRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Text  string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	// Политика цензуры, пустая - по умолчанию.
+	Policy        string `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckRequest) Reset() {
+	*x = CheckRequest{}
+	mi := &file_censor_v1_censor_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckRequest) ProtoMessage() {}
+
+func (x *CheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_censor_v1_censor_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckRequest.ProtoReflect.Descriptor instead.
+func (*CheckRequest) Descriptor() ([]byte, []int) {
+	return file_censor_v1_censor_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CheckRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *CheckRequest) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+// Совпадение с правилом, смещения в символах.
+type Match struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RuleId        string                 `protobuf:"bytes,1,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	Category      string                 `protobuf:"bytes,2,opt,name=category,proto3" json:"category,omitempty"`
+	Fragment      string                 `protobuf:"bytes,3,opt,name=fragment,proto3" json:"fragment,omitempty"`
+	Start         int32                  `protobuf:"varint,4,opt,name=start,proto3" json:"start,omitempty"`
+	End           int32                  `protobuf:"varint,5,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Match) Reset() {
+	*x = Match{}
+	mi := &file_censor_v1_censor_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Match) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Match) ProtoMessage() {}
+
+func (x *Match) ProtoReflect() protoreflect.Message {
+	mi := &file_censor_v1_censor_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Match.ProtoReflect.Descriptor instead.
+func (*Match) Descriptor() ([]byte, []int) {
+	return file_censor_v1_censor_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Match) GetRuleId() string {
+	if x != nil {
+		return x.RuleId
+	}
+	return ""
+}
+
+func (x *Match) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Match) GetFragment() string {
+	if x != nil {
+		return x.Fragment
+	}
+	return ""
+}
+
+func (x *Match) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *Match) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+// Сработавший детектор или классификатор.
+type Signal struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Detector      string                 `protobuf:"bytes,1,opt,name=detector,proto3" json:"detector,omitempty"`
+	Score         float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	Weight        float64                `protobuf:"fixed64,3,opt,name=weight,proto3" json:"weight,omitempty"`
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Signal) Reset() {
+	*x = Signal{}
+	mi := &file_censor_v1_censor_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Signal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Signal) ProtoMessage() {}
+
+func (x *Signal) ProtoReflect() protoreflect.Message {
+	mi := &file_censor_v1_censor_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Signal.ProtoReflect.Descriptor instead.
+func (*Signal) Descriptor() ([]byte, []int) {
+	return file_censor_v1_censor_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Signal) GetDetector() string {
+	if x != nil {
+		return x.Detector
+	}
+	return ""
+}
+
+func (x *Signal) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *Signal) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *Signal) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// Совпадение, подавленное разрешенным фрагментом.
+type Suppressed struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Match         *Match                 `protobuf:"bytes,1,opt,name=match,proto3" json:"match,omitempty"`
+	By            string                 `protobuf:"bytes,2,opt,name=by,proto3" json:"by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Suppressed) Reset() {
+	*x = Suppressed{}
+	mi := &file_censor_v1_censor_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Suppressed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Suppressed) ProtoMessage() {}
+
+func (x *Suppressed) ProtoReflect() protoreflect.Message {
+	mi := &file_censor_v1_censor_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Suppressed.ProtoReflect.Descriptor instead.
+func (*Suppressed) Descriptor() ([]byte, []int) {
+	return file_censor_v1_censor_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Suppressed) GetMatch() *Match {
+	if x != nil {
+		return x.Match
+	}
+	return nil
+}
+
+func (x *Suppressed) GetBy() string {
+	if x != nil {
+		return x.By
+	}
+	return ""
+}
+
+type Verdict struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Policy            string                 `protobuf:"bytes,1,opt,name=policy,proto3" json:"policy,omitempty"`
+	DictionaryVersion string                 `protobuf:"bytes,2,opt,name=dictionary_version,json=dictionaryVersion,proto3" json:"dictionary_version,omitempty"`
+	// Решение: allow, deny или review.
+	Decision   string        `protobuf:"bytes,3,opt,name=decision,proto3" json:"decision,omitempty"`
+	Score      float64       `protobuf:"fixed64,4,opt,name=score,proto3" json:"score,omitempty"`
+	Rules      []string      `protobuf:"bytes,5,rep,name=rules,proto3" json:"rules,omitempty"`
+	Categories []string      `protobuf:"bytes,6,rep,name=categories,proto3" json:"categories,omitempty"`
+	Matches    []*Match      `protobuf:"bytes,7,rep,name=matches,proto3" json:"matches,omitempty"`
+	Signals    []*Signal     `protobuf:"bytes,8,rep,name=signals,proto3" json:"signals,omitempty"`
+	Suppressed []*Suppressed `protobuf:"bytes,9,rep,name=suppressed,proto3" json:"suppressed,omitempty"`
+	// Действие политики: reject или mask.
+	Action        string `protobuf:"bytes,10,opt,name=action,proto3" json:"action,omitempty"`
+	SanitizedText string `protobuf:"bytes,11,opt,name=sanitized_text,json=sanitizedText,proto3" json:"sanitized_text,omitempty"`
+	// Преобладающий язык текста, und - не определен.
+	Language      string `protobuf:"bytes,12,opt,name=language,proto3" json:"language,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Verdict) Reset() {
+	*x = Verdict{}
+	mi := &file_censor_v1_censor_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Verdict) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Verdict) ProtoMessage() {}
+
+func (x *Verdict) ProtoReflect() protoreflect.Message {
+	mi := &file_censor_v1_censor_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Verdict.ProtoReflect.Descriptor instead.
+func (*Verdict) Descriptor() ([]byte, []int) {
+	return file_censor_v1_censor_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Verdict) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *Verdict) GetDictionaryVersion() string {
+	if x != nil {
+		return x.DictionaryVersion
+	}
+	return ""
+}
+
+func (x *Verdict) GetDecision() string {
+	if x != nil {
+		return x.Decision
+	}
+	return ""
+}
+
+func (x *Verdict) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *Verdict) GetRules() []string {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *Verdict) GetCategories() []string {
+	if x != nil {
+		return x.Categories
+	}
+	return nil
+}
+
+func (x *Verdict) GetMatches() []*Match {
+	if x != nil {
+		return x.Matches
+	}
+	return nil
+}
+
+func (x *Verdict) GetSignals() []*Signal {
+	if x != nil {
+		return x.Signals
+	}
+	return nil
+}
+
+func (x *Verdict) GetSuppressed() []*Suppressed {
+	if x != nil {
+		return x.Suppressed
+	}
+	return nil
+}
+
+func (x *Verdict) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *Verdict) GetSanitizedText() string {
+	if x != nil {
+		return x.SanitizedText
+	}
+	return ""
+}
+
+func (x *Verdict) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+type CheckItem struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Идентификатор, назначенный клиентом.
+	Id            string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Text          string `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	Policy        string `protobuf:"bytes,3,opt,name=policy,proto3" json:"policy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckItem) Reset() {
+	*x = CheckItem{}
+	mi := &file_censor_v1_censor_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckItem) ProtoMessage() {}
+
+func (x *CheckItem) ProtoReflect() protoreflect.Message {
+	mi := &file_censor_v1_censor_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckItem.ProtoReflect.Descriptor instead.
+func (*CheckItem) Descriptor() ([]byte, []int) {
+	return file_censor_v1_censor_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CheckItem) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CheckItem) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *CheckItem) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+type CheckResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Verdict       *Verdict               `protobuf:"bytes,2,opt,name=verdict,proto3" json:"verdict,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckResult) Reset() {
+	*x = CheckResult{}
+	mi := &file_censor_v1_censor_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckResult) ProtoMessage() {}
+
+func (x *CheckResult) ProtoReflect() protoreflect.Message {
+	mi := &file_censor_v1_censor_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckResult.ProtoReflect.Descriptor instead.
+func (*CheckResult) Descriptor() ([]byte, []int) {
+	return file_censor_v1_censor_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CheckResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CheckResult) GetVerdict() *Verdict {
+	if x != nil {
+		return x.Verdict
+	}
+	return nil
+}
+
+func (x *CheckResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type CheckBatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Политика для элементов без собственной политики.
+	Policy        string       `protobuf:"bytes,1,opt,name=policy,proto3" json:"policy,omitempty"`
+	Items         []*CheckItem `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckBatchRequest) Reset() {
+	*x = CheckBatchRequest{}
+	mi := &file_censor_v1_censor_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckBatchRequest) ProtoMessage() {}
+
+func (x *CheckBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_censor_v1_censor_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckBatchRequest.ProtoReflect.Descriptor instead.
+func (*CheckBatchRequest) Descriptor() ([]byte, []int) {
+	return file_censor_v1_censor_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CheckBatchRequest) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *CheckBatchRequest) GetItems() []*CheckItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type CheckBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*CheckResult         `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckBatchResponse) Reset() {
+	*x = CheckBatchResponse{}
+	mi := &file_censor_v1_censor_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckBatchResponse) ProtoMessage() {}
+
+func (x *CheckBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_censor_v1_censor_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckBatchResponse.ProtoReflect.Descriptor instead.
+func (*CheckBatchResponse) Descriptor() ([]byte, []int) {
+	return file_censor_v1_censor_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CheckBatchResponse) GetResults() []*CheckResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+var File_censor_v1_censor_proto protoreflect.FileDescriptor
+
+const file_censor_v1_censor_proto_rawDesc = "" +
+	"\n" +
+	"\x16censor/v1/censor.proto\x12\tcensor.v1\":\n" +
+	"\fCheckRequest\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x16\n" +
+	"\x06policy\x18\x02 \x01(\tR\x06policy\"\x80\x01\n" +
+	"\x05Match\x12\x17\n" +
+	"\arule_id\x18\x01 \x01(\tR\x06ruleId\x12\x1a\n" +
+	"\bcategory\x18\x02 \x01(\tR\bcategory\x12\x1a\n" +
+	"\bfragment\x18\x03 \x01(\tR\bfragment\x12\x14\n" +
+	"\x05start\x18\x04 \x01(\x05R\x05start\x12\x10\n" +
+	"\x03end\x18\x05 \x01(\x05R\x03end\"j\n" +
+	"\x06Signal\x12\x1a\n" +
+	"\bdetector\x18\x01 \x01(\tR\bdetector\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x01R\x05score\x12\x16\n" +
+	"\x06weight\x18\x03 \x01(\x01R\x06weight\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\"D\n" +
+	"\n" +
+	"Suppressed\x12&\n" +
+	"\x05match\x18\x01 \x01(\v2\x10.censor.v1.MatchR\x05match\x12\x0e\n" +
+	"\x02by\x18\x02 \x01(\tR\x02by\"\xa3\x03\n" +
+	"\aVerdict\x12\x16\n" +
+	"\x06policy\x18\x01 \x01(\tR\x06policy\x12-\n" +
+	"\x12dictionary_version\x18\x02 \x01(\tR\x11dictionaryVersion\x12\x1a\n" +
+	"\bdecision\x18\x03 \x01(\tR\bdecision\x12\x14\n" +
+	"\x05score\x18\x04 \x01(\x01R\x05score\x12\x14\n" +
+	"\x05rules\x18\x05 \x03(\tR\x05rules\x12\x1e\n" +
+	"\n" +
+	"categories\x18\x06 \x03(\tR\n" +
+	"categories\x12*\n" +
+	"\amatches\x18\a \x03(\v2\x10.censor.v1.MatchR\amatches\x12+\n" +
+	"\asignals\x18\b \x03(\v2\x11.censor.v1.SignalR\asignals\x125\n" +
+	"\n" +
+	"suppressed\x18\t \x03(\v2\x15.censor.v1.SuppressedR\n" +
+	"suppressed\x12\x16\n" +
+	"\x06action\x18\n" +
+	" \x01(\tR\x06action\x12%\n" +
+	"\x0esanitized_text\x18\v \x01(\tR\rsanitizedText\x12\x1a\n" +
+	"\blanguage\x18\f \x01(\tR\blanguage\"G\n" +
+	"\tCheckItem\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12\x16\n" +
+	"\x06policy\x18\x03 \x01(\tR\x06policy\"a\n" +
+	"\vCheckResult\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12,\n" +
+	"\averdict\x18\x02 \x01(\v2\x12.censor.v1.VerdictR\averdict\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"W\n" +
+	"\x11CheckBatchRequest\x12\x16\n" +
+	"\x06policy\x18\x01 \x01(\tR\x06policy\x12*\n" +
+	"\x05items\x18\x02 \x03(\v2\x14.censor.v1.CheckItemR\x05items\"F\n" +
+	"\x12CheckBatchResponse\x120\n" +
+	"\aresults\x18\x01 \x03(\v2\x16.censor.v1.CheckResultR\aresults2\xca\x01\n" +
+	"\x06Censor\x124\n" +
+	"\x05Check\x12\x17.censor.v1.CheckRequest\x1a\x12.censor.v1.Verdict\x12I\n" +
+	"\n" +
+	"CheckBatch\x12\x1c.censor.v1.CheckBatchRequest\x1a\x1d.censor.v1.CheckBatchResponse\x12?\n" +
+	"\vCheckStream\x12\x14.censor.v1.CheckItem\x1a\x16.censor.v1.CheckResult(\x010\x01B\"Z APIGetaway/pkg/censorpb;censorpbb\x06proto3"
+
+var (
+	file_censor_v1_censor_proto_rawDescOnce sync.Once
+	file_censor_v1_censor_proto_rawDescData []byte
+)
+
+func file_censor_v1_censor_proto_rawDescGZIP() []byte {
+	file_censor_v1_censor_proto_rawDescOnce.Do(func() {
+		file_censor_v1_censor_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_censor_v1_censor_proto_rawDesc), len(file_censor_v1_censor_proto_rawDesc)))
+	})
+	return file_censor_v1_censor_proto_rawDescData
+}
+
+var file_censor_v1_censor_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_censor_v1_censor_proto_goTypes = []any{
+	(*CheckRequest)(nil),       // 0: censor.v1.CheckRequest
+	(*Match)(nil),              // 1: censor.v1.Match
+	(*Signal)(nil),             // 2: censor.v1.Signal
+	(*Suppressed)(nil),         // 3: censor.v1.Suppressed
+	(*Verdict)(nil),            // 4: censor.v1.Verdict
+	(*CheckItem)(nil),          // 5: censor.v1.CheckItem
+	(*CheckResult)(nil),        // 6: censor.v1.CheckResult
+	(*CheckBatchRequest)(nil),  // 7: censor.v1.CheckBatchRequest
+	(*CheckBatchResponse)(nil), // 8: censor.v1.CheckBatchResponse
+}
+var file_censor_v1_censor_proto_depIdxs = []int32{
+	1,  // 0: censor.v1.Suppressed.match:type_name -> censor.v1.Match
+	1,  // 1: censor.v1.Verdict.matches:type_name -> censor.v1.Match
+	2,  // 2: censor.v1.Verdict.signals:type_name -> censor.v1.Signal
+	3,  // 3: censor.v1.Verdict.suppressed:type_name -> censor.v1.Suppressed
+	4,  // 4: censor.v1.CheckResult.verdict:type_name -> censor.v1.Verdict
+	5,  // 5: censor.v1.CheckBatchRequest.items:type_name -> censor.v1.CheckItem
+	6,  // 6: censor.v1.CheckBatchResponse.results:type_name -> censor.v1.CheckResult
+	0,  // 7: censor.v1.Censor.Check:input_type -> censor.v1.CheckRequest
+	7,  // 8: censor.v1.Censor.CheckBatch:input_type -> censor.v1.CheckBatchRequest
+	5,  // 9: censor.v1.Censor.CheckStream:input_type -> censor.v1.CheckItem
+	4,  // 10: censor.v1.Censor.Check:output_type -> censor.v1.Verdict
+	8,  // 11: censor.v1.Censor.CheckBatch:output_type -> censor.v1.CheckBatchResponse
+	6,  // 12: censor.v1.Censor.CheckStream:output_type -> censor.v1.CheckResult
+	10, // [10:13] is the sub-list for method output_type
+	7,  // [7:10] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_censor_v1_censor_proto_init() }
+func file_censor_v1_censor_proto_init() {
+	if File_censor_v1_censor_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_censor_v1_censor_proto_rawDesc), len(file_censor_v1_censor_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_censor_v1_censor_proto_goTypes,
+		DependencyIndexes: file_censor_v1_censor_proto_depIdxs,
+		MessageInfos:      file_censor_v1_censor_proto_msgTypes,
+	}.Build()
+	File_censor_v1_censor_proto = out.File
+	file_censor_v1_censor_proto_goTypes = nil
+	file_censor_v1_censor_proto_depIdxs = nil
+}
@@ -0,0 +1,206 @@
+// Интерфейс сервиса цензуры комментариев.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: censor/v1/censor.proto
+
+package censorpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Censor_Check_FullMethodName       = "/censor.v1.Censor/Check"
+	Censor_CheckBatch_FullMethodName  = "/censor.v1.Censor/CheckBatch"
+	Censor_CheckStream_FullMethodName = "/censor.v1.Censor/CheckStream"
+)
+
+// CensorClient is the client API for Censor service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Censor проверяет тексты комментариев по политикам цензуры.
+// Идентификатор запроса передается в метаданных под ключом request_id.
+type CensorClient interface {
+	// Check проверяет один текст.
+	Check(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*Verdict, error)
+	// CheckBatch проверяет набор текстов, результаты возвращаются в порядке запроса.
+	CheckBatch(ctx context.Context, in *CheckBatchRequest, opts ...grpc.CallOption) (*CheckBatchResponse, error)
+	// CheckStream проверяет поток текстов, результаты возвращаются по мере готовности.
+	CheckStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[CheckItem, CheckResult], error)
+}
+
+type censorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCensorClient(cc grpc.ClientConnInterface) CensorClient {
+	return &censorClient{cc}
+}
+
+func (c *censorClient) Check(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*Verdict, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Verdict)
+	err := c.cc.Invoke(ctx, Censor_Check_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *censorClient) CheckBatch(ctx context.Context, in *CheckBatchRequest, opts ...grpc.CallOption) (*CheckBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckBatchResponse)
+	err := c.cc.Invoke(ctx, Censor_CheckBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *censorClient) CheckStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[CheckItem, CheckResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Censor_ServiceDesc.Streams[0], Censor_CheckStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CheckItem, CheckResult]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Censor_CheckStreamClient = grpc.BidiStreamingClient[CheckItem, CheckResult]
+
+// CensorServer is the server API for Censor service.
+// All implementations must embed UnimplementedCensorServer
+// for forward compatibility.
+//
+// Censor проверяет тексты комментариев по политикам цензуры.
+// Идентификатор запроса передается в метаданных под ключом request_id.
+type CensorServer interface {
+	// Check проверяет один текст.
+	Check(context.Context, *CheckRequest) (*Verdict, error)
+	// CheckBatch проверяет набор текстов, результаты возвращаются в порядке запроса.
+	CheckBatch(context.Context, *CheckBatchRequest) (*CheckBatchResponse, error)
+	// CheckStream проверяет поток текстов, результаты возвращаются по мере готовности.
+	CheckStream(grpc.BidiStreamingServer[CheckItem, CheckResult]) error
+	mustEmbedUnimplementedCensorServer()
+}
+
+// UnimplementedCensorServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCensorServer struct{}
+
+func (UnimplementedCensorServer) Check(context.Context, *CheckRequest) (*Verdict, error) {
+	return nil, status.Error(codes.Unimplemented, "method Check not implemented")
+}
+func (UnimplementedCensorServer) CheckBatch(context.Context, *CheckBatchRequest) (*CheckBatchResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CheckBatch not implemented")
+}
+func (UnimplementedCensorServer) CheckStream(grpc.BidiStreamingServer[CheckItem, CheckResult]) error {
+	return status.Error(codes.Unimplemented, "method CheckStream not implemented")
+}
+func (UnimplementedCensorServer) mustEmbedUnimplementedCensorServer() {}
+func (UnimplementedCensorServer) testEmbeddedByValue()                {}
+
+// UnsafeCensorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CensorServer will
+// result in compilation errors.
+type UnsafeCensorServer interface {
+	mustEmbedUnimplementedCensorServer()
+}
+
+func RegisterCensorServer(s grpc.ServiceRegistrar, srv CensorServer) {
+	// If the following call panics, it indicates UnimplementedCensorServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Censor_ServiceDesc, srv)
+}
+
+func _Censor_Check_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CensorServer).Check(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Censor_Check_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CensorServer).Check(ctx, req.(*CheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Censor_CheckBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CensorServer).CheckBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Censor_CheckBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CensorServer).CheckBatch(ctx, req.(*CheckBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Censor_CheckStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CensorServer).CheckStream(&grpc.GenericServerStream[CheckItem, CheckResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Censor_CheckStreamServer = grpc.BidiStreamingServer[CheckItem, CheckResult]
+
+// Censor_ServiceDesc is the grpc.ServiceDesc for Censor service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Censor_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "censor.v1.Censor",
+	HandlerType: (*CensorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Check",
+			Handler:    _Censor_Check_Handler,
+		},
+		{
+			MethodName: "CheckBatch",
+			Handler:    _Censor_CheckBatch_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CheckStream",
+			Handler:       _Censor_CheckStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "censor/v1/censor.proto",
+}
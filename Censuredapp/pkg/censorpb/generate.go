@@ -0,0 +1,5 @@
+// Package censorpb содержит код, сгенерированный по описанию сервиса цензуры
+// Censuredapp/proto/censor/v1/censor.proto.
+package censorpb
+
+//go:generate protoc -I ../../../Censuredapp/proto --go_out=../.. --go_opt=module=APIGetaway --go-grpc_out=../.. --go-grpc_opt=module=APIGetaway censor/v1/censor.proto
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"APIGetaway/pkg/api"
+	"APIGetaway/pkg/migrations"
+	"APIGetaway/pkg/poller"
+	"APIGetaway/pkg/storage"
+	"APIGetaway/shared/logging"
+	"APIGetaway/shared/tracing"
+)
+
+// конфигурация приложения
+type config struct {
+	DB    storage.DBConfig `json:"db"`
+	RSS   poller.Config    `json:"rss"` // ленты новостей и период их опроса
+	Log   logging.Config   `json:"log"`
+	Trace tracing.Config   `json:"trace"`
+}
+
+func main() {
+	// чтение и раскодирование файла конфигурации
+	b, err := os.ReadFile("./config.json")
+	if err != nil {
+		log.Fatal(err)
+	}
+	var config config
+	err = json.Unmarshal(b, &config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	dbInfo := config.DB.DSN()
+
+	// Журнал сервиса в stdout и файл с ротацией, SIGHUP открывает файл заново
+	logger, logFile, err := logging.Open("news", config.Log)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer logFile.Close()
+	slog.SetDefault(logger)
+
+	// Трассировка OpenTelemetry, экспортер можно заменить переменной OTEL_TRACES_EXPORTER
+	tracer, err := tracing.Open("news", config.Trace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer tracer.Close()
+
+	// перед запуском применяем невыполненные миграции
+	results, err := migrations.RunMigrations(context.Background(), dbInfo)
+	if err != nil {
+		log.Fatalf("Ошибка выполнения миграций: %v", err)
+	}
+	slog.Info("Миграции выполнены успешно", "applied", len(results))
+
+	db, err := storage.New(dbInfo)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	// фоновый опрос RSS-лент
+	p, err := poller.New(db, config.RSS)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go p.Run(context.Background())
+
+	api := api.New(db)
+
+	// запуск веб-сервера с API
+	slog.Info("Сервер запущен", "addr", ":8081")
+	err = http.ListenAndServe(":8081", api.Router())
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"APIGetaway/pkg/models"
+	"APIGetaway/pkg/storage"
+	"APIGetaway/shared/logging"
+	"APIGetaway/shared/metrics"
+	"APIGetaway/shared/problem"
+	"APIGetaway/shared/tracing"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Число новостей на странице списка
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
+// MaxPage - наибольший номер страницы, ограничивает смещение выборки.
+const MaxPage = 10000
+
+// TotalCountHeader - заголовок ответа с общим числом найденных новостей.
+const TotalCountHeader = "X-Total-Count"
+
+// API структура.
+type API struct {
+	db      storage.DBInterface
+	r       *chi.Mux
+	metrics *metrics.Metrics
+}
+
+// Конструктор API.
+func New(db storage.DBInterface) *API {
+	a := API{db: db, r: chi.NewRouter(), metrics: metrics.New()}
+	a.endpoints()
+	return &a
+}
+
+// Router возвращает маршрутизатор для использования
+// в качестве аргумента HTTP-сервера.
+func (api *API) Router() *chi.Mux {
+	return api.r
+}
+
+// Регистрация методов API в маршрутизаторе запросов.
+func (api *API) endpoints() {
+	api.r.Use(tracing.Middleware)  // Спан запроса, продолжает трассу из заголовка traceparent
+	api.r.Use(RequestIDMiddleware) // Добавляем middleware для request_id
+	api.r.Use(LoggingMiddleware)   // Добавляем middleware для логирования
+	api.r.Use(api.metrics.Middleware)
+	api.r.NotFound(problem.NotFound)
+	api.r.MethodNotAllowed(problem.MethodNotAllowed)
+	api.r.Get("/news", api.newsHandler)
+	api.r.Get("/news/{id}", api.newsByIDHandler)
+	api.r.Method(http.MethodGet, metrics.Path, api.metrics.Handler())
+}
+
+// Обработчик списка новостей: s - поиск по заголовку, page - номер страницы с 1,
+// page_size - число новостей на странице. Общее число найденных новостей
+// возвращается в заголовке X-Total-Count.
+func (api *API) newsHandler(w http.ResponseWriter, r *http.Request) {
+	page, ok := intParam(w, r, "page", 1, 1, MaxPage)
+	if !ok {
+		return
+	}
+	pageSize, ok := intParam(w, r, "page_size", DefaultPageSize, 1, MaxPageSize)
+	if !ok {
+		return
+	}
+
+	posts, total, err := api.db.Posts(r.Context(), r.URL.Query().Get("s"), pageSize, (page-1)*pageSize)
+	if err != nil {
+		requestLogger(r.Context()).Error("Не удалось получить новости", logging.Err(err))
+		problem.Error(w, r, http.StatusInternalServerError, problem.CodeInternal, "news")
+		return
+	}
+
+	news := make([]models.NewsShortDetailed, 0, len(posts))
+	for _, p := range posts {
+		news = append(news, models.NewsShortDetailed{
+			ID:      p.ID,
+			Title:   p.Title,
+			Content: p.Content,
+			PubTime: p.PubTime,
+			Link:    p.Link,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(TotalCountHeader, strconv.Itoa(total))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(news)
+}
+
+// Обработчик получения новости по ID.
+func (api *API) newsByIDHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "format", "id")
+		return
+	}
+
+	p, err := api.db.PostByID(r.Context(), id)
+	if errors.Is(err, storage.ErrNotFound) {
+		problem.Error(w, r, http.StatusNotFound, problem.CodeNotFound, "news")
+		return
+	}
+	if err != nil {
+		requestLogger(r.Context()).Error("Не удалось получить новость", logging.Err(err))
+		problem.Error(w, r, http.StatusInternalServerError, problem.CodeInternal, "post")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.NewsFullDetailed{
+		ID:       p.ID,
+		Title:    p.Title,
+		Content:  p.Content,
+		Author:   p.Author,
+		Category: p.Category,
+		PubTime:  p.PubTime,
+		Link:     p.Link,
+	})
+}
+
+// intParam читает целый параметр запроса name в границах [lo, hi] (hi 0 - без верхней границы).
+// При ошибке отправляет ответ 400 и возвращает false.
+func intParam(w http.ResponseWriter, r *http.Request, name string, def, lo, hi int) (int, bool) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return def, true
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < lo || (hi > 0 && v > hi) {
+		problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "value", name)
+		return 0, false
+	}
+	return v, true
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"APIGetaway/pkg/models"
+	"APIGetaway/pkg/storage"
+	"APIGetaway/shared/problem"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// stubDB - хранилище публикаций в памяти, публикации отсортированы от новых к старым.
+type stubDB struct {
+	posts []models.Post
+}
+
+func (db *stubDB) AddPosts(ctx context.Context, posts []models.Post) (int, error) {
+	db.posts = append(db.posts, posts...)
+	return len(posts), nil
+}
+
+func (db *stubDB) Posts(ctx context.Context, search string, limit, offset int) ([]models.Post, int, error) {
+	var found []models.Post
+	for _, p := range db.posts {
+		if strings.Contains(strings.ToLower(p.Title), strings.ToLower(search)) {
+			found = append(found, p)
+		}
+	}
+	total := len(found)
+	if offset >= total {
+		return nil, total, nil
+	}
+	return found[offset:min(offset+limit, total)], total, nil
+}
+
+func (db *stubDB) PostByID(ctx context.Context, id int) (models.Post, error) {
+	for _, p := range db.posts {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return models.Post{}, storage.ErrNotFound
+}
+
+func (db *stubDB) Close() {}
+
+func newTestAPI() *API {
+	db := &stubDB{}
+	for i := 25; i >= 1; i-- {
+		title := "Новость"
+		if i%5 == 0 {
+			title = "Релиз Go"
+		}
+		db.posts = append(db.posts, models.Post{ID: i, Title: title, Category: "tech", PubTime: int64(i), Link: "https://example.com/" + title})
+	}
+	return New(db)
+}
+
+func TestAPI_news(t *testing.T) {
+	api := newTestAPI()
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedIDs    []int
+		expectedTotal  string
+	}{
+		{name: "Первая страница", url: "/news", expectedStatus: http.StatusOK, expectedIDs: []int{25, 24, 23, 22, 21, 20, 19, 18, 17, 16}, expectedTotal: "25"},
+		{name: "Последняя страница", url: "/news?page=3", expectedStatus: http.StatusOK, expectedIDs: []int{5, 4, 3, 2, 1}, expectedTotal: "25"},
+		{name: "Страница за концом списка", url: "/news?page=4", expectedStatus: http.StatusOK, expectedIDs: []int{}, expectedTotal: "25"},
+		{name: "Поиск", url: "/news?s=%D1%80%D0%B5%D0%BB%D0%B8%D0%B7&page=1", expectedStatus: http.StatusOK, expectedIDs: []int{25, 20, 15, 10, 5}, expectedTotal: "5"},
+		{name: "Размер страницы", url: "/news?page=2&page_size=20", expectedStatus: http.StatusOK, expectedIDs: []int{5, 4, 3, 2, 1}, expectedTotal: "25"},
+		{name: "Неверная страница", url: "/news?page=0", expectedStatus: http.StatusBadRequest},
+		{name: "Слишком далекая страница", url: "/news?page=10001", expectedStatus: http.StatusBadRequest},
+		{name: "Переполнение смещения", url: "/news?page=9223372036854775807&page_size=100", expectedStatus: http.StatusBadRequest},
+		{name: "Слишком большая страница", url: "/news?page_size=101", expectedStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			api.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Неверный статус: ожидается %d, получено %d", tt.expectedStatus, rr.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var news []models.NewsShortDetailed
+			if err := json.NewDecoder(rr.Body).Decode(&news); err != nil {
+				t.Fatalf("Ошибка разбора ответа: %v", err)
+			}
+			ids := []int{}
+			for _, n := range news {
+				ids = append(ids, n.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expectedIDs) {
+				t.Errorf("Неверные новости: ожидается %v, получено %v", tt.expectedIDs, ids)
+			}
+			if got := rr.Header().Get(TotalCountHeader); got != tt.expectedTotal {
+				t.Errorf("Неверное общее число новостей: ожидается %s, получено %s", tt.expectedTotal, got)
+			}
+		})
+	}
+}
+
+func TestAPI_newsByID(t *testing.T) {
+	api := newTestAPI()
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "Существующая новость", url: "/news/5", expectedStatus: http.StatusOK},
+		{name: "Несуществующая новость", url: "/news/100", expectedStatus: http.StatusNotFound, expectedCode: problem.CodeNotFound},
+		{name: "Неверный ID", url: "/news/abc", expectedStatus: http.StatusBadRequest, expectedCode: problem.CodeInvalidParameter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			api.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Неверный статус: ожидается %d, получено %d", tt.expectedStatus, rr.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				var p problem.Problem
+				if err := json.NewDecoder(rr.Body).Decode(&p); err != nil || p.Code != tt.expectedCode || p.Status != tt.expectedStatus {
+					t.Errorf("Неверная ошибка: ожидается %s, получено %+v (%v)", tt.expectedCode, p, err)
+				}
+				if ct := rr.Header().Get("Content-Type"); ct != problem.ContentType {
+					t.Errorf("Неверный тип ответа об ошибке: %q", ct)
+				}
+				return
+			}
+			var news models.NewsFullDetailed
+			if err := json.NewDecoder(rr.Body).Decode(&news); err != nil {
+				t.Fatalf("Ошибка разбора ответа: %v", err)
+			}
+			if news.ID != 5 || news.Title != "Релиз Go" || news.Category != "tech" {
+				t.Errorf("Неверная новость: %+v", news)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"APIGetaway/shared/logging"
+	"APIGetaway/shared/tracing"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDKey — тип для ключа контекста
+type RequestIDKey struct{}
+
+// Middleware для добавления request_id в контекст запроса и заголовок ответа
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Получаем `request_id` из заголовка запроса или генерируем новый
+		requestID := r.Header.Get("request_id")
+		if requestID == "" {
+			requestID = generateRandomString(6)
+		}
+
+		// Добавляем `request_id` в контекст запроса
+		ctx := context.WithValue(r.Context(), RequestIDKey{}, requestID)
+		// Журнал запроса: все записи обработчиков содержат request_id и trace_id
+		ctx = logging.NewContext(ctx, requestIDLogger(ctx, requestID))
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String(logging.KeyRequestID, requestID))
+
+		// Устанавливаем `request_id` в заголовок ответа, чтобы передавать его между сервисами
+		w.Header().Set("request_id", requestID)
+
+		// Передаем запрос следующему обработчику с новым контекстом
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Middleware для журналирования запросов
+func LoggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Запоминаем время начала обработки запроса
+		start := time.Now()
+
+		// Обработчики могут добавить поля к записи о запросе, например user
+		ctx := logging.WithFields(r.Context())
+
+		// Перехватываем стандартный http.ResponseWriter для записи кода ответа
+		ww := &responseWriter{ResponseWriter: w}
+
+		// Вызываем обработчик запроса
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		status := ww.statusCode
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		// Логируем информацию о запросе после его обработки
+		requestLogger(ctx).LogAttrs(ctx, level, "Запрос обработан",
+			slog.String(logging.KeyMethod, r.Method),
+			slog.String(logging.KeyPath, r.URL.Path),
+			slog.String(logging.KeyRemoteAddr, r.RemoteAddr),
+			slog.Int(logging.KeyStatus, status),
+			slog.Float64(logging.KeyDuration, durationMs(time.Since(start))),
+		)
+	})
+}
+
+// requestIDLogger возвращает журнал с request_id и идентификатором трассы, если запрос к ней относится.
+func requestIDLogger(ctx context.Context, requestID string) *slog.Logger {
+	l := slog.Default().With(logging.KeyRequestID, requestID)
+	if traceID := tracing.TraceID(ctx); traceID != "" {
+		l = l.With(logging.KeyTraceID, traceID)
+	}
+	return l
+}
+
+// requestLogger возвращает журнал запроса с шаблоном маршрута chi.
+func requestLogger(ctx context.Context) *slog.Logger {
+	l := logging.FromContext(ctx)
+	if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+		l = l.With(logging.KeyRoute, rctx.RoutePattern())
+	}
+	return l
+}
+
+// durationMs возвращает длительность в миллисекундах.
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// responseWriter - обертка для ResponseWriter для захвата кода статуса
+type responseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+// WriteHeader перехватывает код статуса HTTP-ответа
+func (rw *responseWriter) WriteHeader(statusCode int) {
+	// Если статусный код еще не установлен, записываем его
+	if rw.statusCode == 0 {
+		rw.statusCode = statusCode
+	}
+	// Вызываем WriteHeader у оригинального ResponseWriter
+	rw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// В случае, если Write не вызывается в вашем обработчике, можно использовать
+// Write для корректной записи статуса.
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	// Если код статуса еще не был установлен, можно установить его в 200
+	if rw.statusCode == 0 {
+		rw.statusCode = http.StatusOK
+	}
+	// Вызываем Write у оригинального ResponseWriter
+	return rw.ResponseWriter.Write(b)
+}
+
+// Unwrap возвращает исходный ResponseWriter, чтобы http.ResponseController
+// мог отправить буферизованные данные и включить полнодуплексный режим.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Генерация случайного request_id
+func generateRandomString(length int) string {
+	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	var sb strings.Builder
+	for i := 0; i < length; i++ {
+		sb.WriteByte(charset[rand.Intn(len(charset))])
+	}
+	return sb.String()
+}
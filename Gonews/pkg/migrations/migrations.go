@@ -0,0 +1,42 @@
+// Package migrations выполняет миграции схемы базы данных новостей.
+// Миграции встроены в исполняемый файл (storage.Migrations); на время миграции
+// берется рекомендательная блокировка Postgres, поэтому одновременный запуск
+// нескольких реплик безопасен.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+
+	"APIGetaway/pkg/storage"
+
+	_ "github.com/lib/pq"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// RunMigrations применяет невыполненные миграции при запуске сервиса.
+func RunMigrations(ctx context.Context, dsn string) ([]*goose.MigrationResult, error) {
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, err
+	}
+	fsys, err := fs.Sub(storage.Migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось подключиться к базе данных: %w", err)
+	}
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, fsys, goose.WithSessionLocker(locker))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	defer provider.Close()
+	return provider.Up(ctx)
+}
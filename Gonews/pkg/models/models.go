@@ -0,0 +1,32 @@
+package models
+
+// Post - публикация из RSS-ленты.
+type Post struct {
+	ID       int    // номер записи
+	Title    string // заголовок публикации
+	Content  string // содержание публикации
+	Author   string // автор публикации
+	Category string // раздел новости
+	PubTime  int64  // время публикации, Unix
+	Link     string // ссылка на источник, уникальна
+}
+
+// Краткая новость для списка, поля совпадают с моделью шлюза
+type NewsShortDetailed struct {
+	ID      int    // номер записи
+	Title   string // заголовок публикации
+	Content string // содержание публикации
+	PubTime int64  // время публикации
+	Link    string // ссылка на источник
+}
+
+// Полная новость
+type NewsFullDetailed struct {
+	ID       int    `json:"id"`
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+	Author   string `json:"author"`
+	Category string `json:"category"` // раздел новости, по нему шлюз выбирает политику цензуры
+	PubTime  int64  `json:"pub_time"`
+	Link     string `json:"link"`
+}
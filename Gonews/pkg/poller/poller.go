@@ -0,0 +1,126 @@
+// Package poller периодически загружает RSS-ленты и сохраняет новые публикации.
+package poller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"APIGetaway/pkg/rss"
+	"APIGetaway/pkg/storage"
+	"APIGetaway/shared/logging"
+)
+
+// DefaultInterval - период опроса лент по умолчанию.
+const DefaultInterval = 5 * time.Minute
+
+// Feed - лента новостей.
+type Feed struct {
+	URL      string `json:"url"`
+	Category string `json:"category"` // раздел для всех публикаций ленты, пустой - раздел из ленты
+}
+
+// Config - настройки опроса лент.
+type Config struct {
+	Feeds    []Feed `json:"feeds"`
+	Interval string `json:"interval"` // период опроса, например "5m"
+}
+
+// Poller опрашивает ленты и сохраняет публикации в хранилище.
+type Poller struct {
+	db       storage.DBInterface
+	feeds    []Feed
+	interval time.Duration
+	client   *http.Client
+	now      func() time.Time
+}
+
+// New создает опросчик лент.
+func New(db storage.DBInterface, cfg Config) (*Poller, error) {
+	if len(cfg.Feeds) == 0 {
+		return nil, errors.New("не задано ни одной ленты")
+	}
+	for _, f := range cfg.Feeds {
+		if f.URL == "" {
+			return nil, errors.New("у ленты не указан адрес")
+		}
+	}
+	interval := DefaultInterval
+	if cfg.Interval != "" {
+		var err error
+		interval, err = time.ParseDuration(cfg.Interval)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("неверный период опроса лент %q", cfg.Interval)
+		}
+	}
+	return &Poller{
+		db:       db,
+		feeds:    cfg.Feeds,
+		interval: interval,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		now:      time.Now,
+	}, nil
+}
+
+// Run опрашивает ленты сразу и затем с заданным периодом, пока не отменен ctx.
+func (p *Poller) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll один раз опрашивает все ленты параллельно и возвращает число новых публикаций.
+// Ошибка одной ленты не мешает обработке остальных и записывается в журнал.
+func (p *Poller) Poll(ctx context.Context) int {
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		added int
+	)
+	for _, feed := range p.feeds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := p.pollFeed(ctx, feed)
+			if err != nil {
+				slog.Warn("Не удалось опросить ленту", "feed", feed.URL, logging.Err(err))
+				return
+			}
+			mu.Lock()
+			added += n
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return added
+}
+
+// pollFeed загружает одну ленту и сохраняет ее публикации.
+// Уже сохраненные публикации хранилище пропускает по ссылке.
+func (p *Poller) pollFeed(ctx context.Context, feed Feed) (int, error) {
+	posts, err := rss.Fetch(ctx, p.client, feed.URL)
+	if err != nil {
+		return 0, err
+	}
+	now := p.now().Unix()
+	for i := range posts {
+		if feed.Category != "" {
+			posts[i].Category = feed.Category
+		}
+		if posts[i].PubTime == 0 {
+			posts[i].PubTime = now
+		}
+	}
+	return p.db.AddPosts(ctx, posts)
+}
@@ -0,0 +1,133 @@
+package poller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"APIGetaway/pkg/models"
+	"APIGetaway/pkg/storage"
+)
+
+// memDB - хранилище публикаций в памяти. Как и Postgres, пропускает
+// публикации со ссылкой, которая уже сохранена.
+type memDB struct {
+	mu    sync.Mutex
+	posts map[string]models.Post // ссылка -> публикация
+}
+
+func (db *memDB) AddPosts(ctx context.Context, posts []models.Post) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	added := 0
+	for _, p := range posts {
+		if _, ok := db.posts[p.Link]; ok {
+			continue
+		}
+		db.posts[p.Link] = p
+		added++
+	}
+	return added, nil
+}
+
+func (db *memDB) Posts(ctx context.Context, search string, limit, offset int) ([]models.Post, int, error) {
+	return nil, 0, nil
+}
+
+func (db *memDB) PostByID(ctx context.Context, id int) (models.Post, error) {
+	return models.Post{}, storage.ErrNotFound
+}
+
+func (db *memDB) Close() {}
+
+// newFeedServer отдает ленты из тестовых данных пакета rss.
+func newFeedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rss", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		http.ServeFile(w, r, "../rss/testdata/rss.xml")
+	})
+	mux.HandleFunc("/atom", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/atom+xml")
+		http.ServeFile(w, r, "../rss/testdata/atom.xml")
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestNew(t *testing.T) {
+	feeds := []Feed{{URL: "https://example.com/rss"}}
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "Период по умолчанию", cfg: Config{Feeds: feeds}},
+		{name: "Заданный период", cfg: Config{Feeds: feeds, Interval: "1m"}},
+		{name: "Нет лент", cfg: Config{}, wantErr: true},
+		{name: "Лента без адреса", cfg: Config{Feeds: []Feed{{Category: "tech"}}}, wantErr: true},
+		{name: "Неверный период", cfg: Config{Feeds: feeds, Interval: "пять минут"}, wantErr: true},
+		{name: "Нулевой период", cfg: Config{Feeds: feeds, Interval: "0s"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(&memDB{}, tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Ошибка: ожидается %v, получено %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPoller_Poll(t *testing.T) {
+	srv := newFeedServer(t)
+	db := &memDB{posts: map[string]models.Post{}}
+	p, err := New(db, Config{Feeds: []Feed{
+		{URL: srv.URL + "/rss", Category: "tech"},
+		{URL: srv.URL + "/atom"},
+		{URL: srv.URL + "/missing"}, // ошибка одной ленты не мешает остальным
+	}})
+	if err != nil {
+		t.Fatalf("Ошибка создания опросчика: %v", err)
+	}
+	now := time.Date(2024, 11, 10, 12, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	if added := p.Poll(context.Background()); added != 4 {
+		t.Fatalf("Ожидается 4 новые публикации, добавлено %d", added)
+	}
+	// Повторный опрос тех же лент не добавляет дубликатов
+	if added := p.Poll(context.Background()); added != 0 {
+		t.Errorf("Повторный опрос добавил %d публикаций", added)
+	}
+	if len(db.posts) != 4 {
+		t.Errorf("В хранилище %d публикаций, ожидается 4", len(db.posts))
+	}
+
+	tests := []struct {
+		link         string
+		wantCategory string
+		wantPubTime  int64
+	}{
+		{link: "https://habr.com/ru/articles/1/", wantCategory: "tech", wantPubTime: time.Date(2024, 11, 4, 10, 30, 0, 0, time.UTC).Unix()},
+		{link: "https://habr.com/ru/articles/2/", wantCategory: "tech", wantPubTime: now.Unix()},
+		{link: "https://go.dev/blog/range-functions", wantCategory: "language", wantPubTime: time.Date(2024, 8, 20, 0, 0, 0, 0, time.UTC).Unix()},
+		{link: "https://go.dev/blog/updated", wantPubTime: time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC).Unix()},
+	}
+	for _, tt := range tests {
+		got, ok := db.posts[tt.link]
+		if !ok {
+			t.Errorf("Публикация %s не сохранена", tt.link)
+			continue
+		}
+		if got.Category != tt.wantCategory || got.PubTime != tt.wantPubTime {
+			t.Errorf("Публикация %s: раздел %q и время %d, ожидается %q и %d",
+				tt.link, got.Category, got.PubTime, tt.wantCategory, tt.wantPubTime)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Package rss загружает и разбирает ленты новостей в форматах RSS 2.0 и Atom.
+package rss
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"APIGetaway/pkg/models"
+
+	"golang.org/x/net/html/charset"
+)
+
+// MaxFeedSize - наибольший размер ленты в байтах. Ленты больше
+// не загружаются целиком, чтобы не исчерпать память.
+const MaxFeedSize = 10 << 20
+
+// document - корневой элемент ленты: <rss> с каналом или <feed> с записями Atom.
+type document struct {
+	XMLName xml.Name
+	Channel struct {
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Links       []string `xml:"link"`
+	Description string   `xml:"description"`
+	PubDate     string   `xml:"pubDate"`
+	Author      string   `xml:"author"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories  []string `xml:"category"`
+}
+
+type atomEntry struct {
+	Title string `xml:"title"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Summary   string `xml:"summary"`
+	Content   string `xml:"content"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Author    struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Categories []struct {
+		Term string `xml:"term,attr"`
+	} `xml:"category"`
+}
+
+// Parse разбирает ленту RSS 2.0 или Atom. Записи без ссылки пропускаются:
+// по ссылке публикации отличаются друг от друга. Если время публикации
+// не удалось разобрать, PubTime равно нулю. Кроме UTF-8 поддерживаются
+// кодировки, указанные в объявлении XML, например windows-1251.
+func Parse(r io.Reader) ([]models.Post, error) {
+	var doc document
+	dec := xml.NewDecoder(r)
+	dec.CharsetReader = charset.NewReaderLabel
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("ошибка разбора ленты: %w", err)
+	}
+
+	var posts []models.Post
+	switch doc.XMLName.Local {
+	case "rss":
+		for _, item := range doc.Channel.Items {
+			p := models.Post{
+				Title:   cleanText(item.Title),
+				Content: cleanText(item.Description),
+				Author:  strings.TrimSpace(item.Author),
+				PubTime: parseTime(item.PubDate),
+				Link:    firstNonEmpty(item.Links...),
+			}
+			if item.Creator != "" {
+				p.Author = strings.TrimSpace(item.Creator)
+			}
+			if len(item.Categories) > 0 {
+				p.Category = strings.TrimSpace(item.Categories[0])
+			}
+			if p.Link != "" {
+				posts = append(posts, p)
+			}
+		}
+	case "feed":
+		for _, entry := range doc.Entries {
+			p := models.Post{
+				Title:   cleanText(entry.Title),
+				Content: cleanText(firstNonEmpty(entry.Summary, entry.Content)),
+				Author:  strings.TrimSpace(entry.Author.Name),
+				PubTime: parseTime(firstNonEmpty(entry.Published, entry.Updated)),
+			}
+			for _, l := range entry.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					p.Link = strings.TrimSpace(l.Href)
+					break
+				}
+			}
+			if len(entry.Categories) > 0 {
+				p.Category = strings.TrimSpace(entry.Categories[0].Term)
+			}
+			if p.Link != "" {
+				posts = append(posts, p)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("неизвестный формат ленты: <%s>", doc.XMLName.Local)
+	}
+	return posts, nil
+}
+
+// Fetch загружает ленту по адресу url и разбирает ее.
+func Fetch(ctx context.Context, client *http.Client, url string) ([]models.Post, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки ленты: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ошибка загрузки ленты: статус %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxFeedSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки ленты: %w", err)
+	}
+	if len(data) > MaxFeedSize {
+		return nil, fmt.Errorf("ошибка загрузки ленты: размер больше %d байт", MaxFeedSize)
+	}
+	return Parse(bytes.NewReader(data))
+}
+
+// Форматы времени публикации. В RSS встречаются варианты RFC 822
+// с днем недели и без него, в Atom используется RFC 3339.
+var timeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC3339,
+}
+
+// parseTime возвращает время публикации в секундах Unix или ноль.
+func parseTime(s string) int64 {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Unix()
+		}
+	}
+	return 0
+}
+
+var (
+	tagRe   = regexp.MustCompile(`<[^>]*>`)
+	spaceRe = regexp.MustCompile(`\s+`)
+)
+
+// cleanText удаляет HTML-разметку и лишние пробелы.
+func cleanText(s string) string {
+	s = tagRe.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	return strings.TrimSpace(spaceRe.ReplaceAllString(s, " "))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+package rss
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"APIGetaway/pkg/models"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want []models.Post
+	}{
+		{
+			name: "RSS 2.0",
+			file: "testdata/rss.xml",
+			want: []models.Post{
+				{
+					Title: "Go 1.24 & generics", Content: "Новый релиз", Author: "anna", Category: "Go",
+					PubTime: time.Date(2024, 11, 4, 10, 30, 0, 0, time.UTC).Unix(), Link: "https://habr.com/ru/articles/1/",
+				},
+				{Title: "Без даты", Content: "Текст", Author: "bob@example.com", Link: "https://habr.com/ru/articles/2/"},
+			},
+		},
+		{
+			name: "Atom",
+			file: "testdata/atom.xml",
+			want: []models.Post{
+				{
+					Title: "Range functions", Content: "Iterators", Author: "Ian", Category: "language",
+					PubTime: time.Date(2024, 8, 20, 0, 0, 0, 0, time.UTC).Unix(), Link: "https://go.dev/blog/range-functions",
+				},
+				{
+					Title: "Only updated", Content: "Content",
+					PubTime: time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC).Unix(), Link: "https://go.dev/blog/updated",
+				},
+			},
+		},
+		{
+			name: "RSS в windows-1251",
+			file: "testdata/rss_cp1251.xml",
+			want: []models.Post{
+				{
+					Title: "Новости недели", Content: "Текст в кодировке windows-1251", Author: "редакция",
+					PubTime: time.Date(2024, 11, 4, 10, 30, 0, 0, time.UTC).Unix(), Link: "https://example.ru/news/1",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.Open(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			got, err := Parse(f)
+			if err != nil {
+				t.Fatalf("Ошибка разбора ленты: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Неверные публикации:\nожидается %+v\nполучено  %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParse_Unknown(t *testing.T) {
+	if _, err := Parse(strings.NewReader(`<html><body/></html>`)); err == nil {
+		t.Error("Для документа, который не является лентой, ожидается ошибка")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/feed" {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, "testdata/atom.xml")
+	}))
+	defer srv.Close()
+
+	posts, err := Fetch(context.Background(), srv.Client(), srv.URL+"/feed")
+	if err != nil || len(posts) != 2 {
+		t.Errorf("Ожидается 2 публикации, получено %d (%v)", len(posts), err)
+	}
+	if _, err := Fetch(context.Background(), srv.Client(), srv.URL+"/missing"); err == nil {
+		t.Error("Для ответа 404 ожидается ошибка")
+	}
+}
+
+func TestFetch_tooLarge(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `<?xml version="1.0"?><rss version="2.0"><channel>`)
+		w.Write(bytes.Repeat([]byte(" "), MaxFeedSize))
+		io.WriteString(w, `</channel></rss>`)
+	}))
+	defer srv.Close()
+
+	_, err := Fetch(context.Background(), srv.Client(), srv.URL)
+	if err == nil || !strings.Contains(err.Error(), "размер") {
+		t.Errorf("Для ленты больше MaxFeedSize ожидается ошибка размера, получено %v", err)
+	}
+}
@@ -0,0 +1,8 @@
+package storage
+
+import "embed"
+
+// Migrations - SQL-миграции схемы базы данных, встроенные в исполняемый файл.
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
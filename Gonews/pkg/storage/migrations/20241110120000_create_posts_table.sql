@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS posts (
+		id SERIAL PRIMARY KEY,
+		title TEXT NOT NULL,
+		content TEXT NOT NULL DEFAULT '',
+		author TEXT NOT NULL DEFAULT '',
+		category TEXT NOT NULL DEFAULT '',
+		pub_time BIGINT NOT NULL DEFAULT 0,
+		link TEXT NOT NULL UNIQUE
+	);
+CREATE INDEX IF NOT EXISTS posts_pub_time_idx ON posts (pub_time DESC, id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS posts;
+-- +goose StatementEnd
@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"APIGetaway/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// Интерфейс для работы с базой данных
+type DBInterface interface {
+	// AddPosts сохраняет публикации и возвращает число добавленных.
+	// Публикации со ссылкой, которая уже есть в базе, пропускаются.
+	AddPosts(ctx context.Context, posts []models.Post) (int, error)
+	// Posts возвращает страницу публикаций, начиная с самых свежих, и общее
+	// число публикаций. Непустой search отбирает публикации по заголовку.
+	Posts(ctx context.Context, search string, limit, offset int) ([]models.Post, int, error)
+	// PostByID возвращает публикацию или ErrNotFound.
+	PostByID(ctx context.Context, id int) (models.Post, error)
+	Close()
+}
+
+// ErrNotFound возвращается, если запрошенная запись не найдена.
+var ErrNotFound = errors.New("запись не найдена")
+
+// Конфигурация БД
+type DBConfig struct {
+	Host     string `json:"host"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+	DBName   string `json:"dbname"`
+	Port     int    `json:"port"`
+	SSLMode  string `json:"sslmode"`
+}
+
+// DSN возвращает строку подключения к Postgres.
+func (c DBConfig) DSN() string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
+		c.Host, c.User, c.Password, c.DBName, c.Port, c.SSLMode)
+}
+
+// База данных
+type DB struct {
+	pool *pgxpool.Pool
+}
+
+var _ DBInterface = (*DB)(nil)
+
+// Конструктор для инициализации соединения с БД
+func New(connstr string) (*DB, error) {
+	if connstr == "" {
+		return nil, errors.New("не указано подключение к БД")
+	}
+	cfg, err := pgxpool.ParseConfig(connstr)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подключения к БД: %w", err)
+	}
+	withQueryTracer(cfg.ConnConfig)
+	pool, err := pgxpool.ConnectConfig(context.Background(), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подключения к БД: %w", err)
+	}
+	return &DB{pool: pool}, nil
+}
+
+// Реализация метода для сохранения публикаций
+func (db *DB) AddPosts(ctx context.Context, posts []models.Post) (int, error) {
+	if len(posts) == 0 {
+		return 0, nil
+	}
+	batch := &pgx.Batch{}
+	for _, p := range posts {
+		batch.Queue(`INSERT INTO posts (title, content, author, category, pub_time, link)
+			  VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (link) DO NOTHING`,
+			p.Title, p.Content, p.Author, p.Category, p.PubTime, p.Link)
+	}
+	br := db.pool.SendBatch(ctx, batch)
+	defer br.Close()
+
+	added := 0
+	for range posts {
+		tag, err := br.Exec()
+		if err != nil {
+			return added, fmt.Errorf("ошибка сохранения публикации: %w", err)
+		}
+		added += int(tag.RowsAffected())
+	}
+	return added, nil
+}
+
+// Реализация метода для получения страницы публикаций
+func (db *DB) Posts(ctx context.Context, search string, limit, offset int) ([]models.Post, int, error) {
+	// Без строки поиска условие не нужно: ILIKE '%%' заставил бы проверять заголовок каждой строки
+	where, args := "", []any{}
+	if search != "" {
+		// Символы шаблона LIKE в строке поиска ищутся как обычные символы
+		where, args = "WHERE title ILIKE $1", append(args, "%"+escapeLike(search)+"%")
+	}
+
+	var total int
+	err := db.pool.QueryRow(ctx, `SELECT count(*) FROM posts `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка подсчета публикаций: %w", err)
+	}
+
+	query := fmt.Sprintf(`SELECT id, title, content, author, category, pub_time, link FROM posts
+			  %s ORDER BY pub_time DESC, id DESC LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
+	rows, err := db.pool.Query(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка получения публикаций: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []models.Post
+	for rows.Next() {
+		var p models.Post
+		err := rows.Scan(&p.ID, &p.Title, &p.Content, &p.Author, &p.Category, &p.PubTime, &p.Link)
+		if err != nil {
+			return nil, 0, fmt.Errorf("ошибка обработки публикации: %w", err)
+		}
+		posts = append(posts, p)
+	}
+	return posts, total, rows.Err()
+}
+
+// Реализация метода для получения публикации по ID
+func (db *DB) PostByID(ctx context.Context, id int) (models.Post, error) {
+	var p models.Post
+	err := db.pool.QueryRow(ctx, `SELECT id, title, content, author, category, pub_time, link FROM posts
+			  WHERE id = $1`, id).Scan(&p.ID, &p.Title, &p.Content, &p.Author, &p.Category, &p.PubTime, &p.Link)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Post{}, ErrNotFound
+	}
+	if err != nil {
+		return models.Post{}, fmt.Errorf("ошибка получения публикации: %w", err)
+	}
+	return p, nil
+}
+
+// Закрытие соединения с БД
+func (db *DB) Close() {
+	db.pool.Close()
+}
+
+// escapeLike экранирует символы шаблона LIKE.
+func escapeLike(s string) string {
+	var out []rune
+	for _, r := range s {
+		if r == '%' || r == '_' || r == '\\' {
+			out = append(out, '\\')
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
@@ -0,0 +1,107 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"APIGetaway/pkg/migrations"
+	"APIGetaway/pkg/models"
+	"APIGetaway/pkg/storage"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// TestDB проверяет хранилище Postgres. Нужна отдельная тестовая база, ее адрес
+// задается переменной окружения GONEWS_TEST_DSN, например
+// "host=localhost user=postgres dbname=news_test". Таблица posts очищается перед тестом.
+func TestDB(t *testing.T) {
+	dsn := os.Getenv("GONEWS_TEST_DSN")
+	if dsn == "" {
+		t.Skip("GONEWS_TEST_DSN не задана, тесты Postgres пропущены")
+	}
+	ctx := context.Background()
+	if _, err := migrations.RunMigrations(ctx, dsn); err != nil {
+		t.Fatalf("Ошибка выполнения миграций: %v", err)
+	}
+	db, err := storage.New(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	truncate(t, dsn)
+
+	posts := []models.Post{
+		{Title: "Релиз Go 1.24", Content: "Текст", Author: "anna", Category: "tech", PubTime: 100, Link: "https://example.com/1"},
+		{Title: "Погода", PubTime: 200, Link: "https://example.com/2"},
+		{Title: "Скидка 100%", PubTime: 300, Link: "https://example.com/3"},
+	}
+	added, err := db.AddPosts(ctx, posts)
+	if err != nil || added != 3 {
+		t.Fatalf("Ожидается 3 новые публикации, добавлено %d (%v)", added, err)
+	}
+	// Публикации с уже известной ссылкой пропускаются
+	added, err = db.AddPosts(ctx, append(posts[:1:1], models.Post{Title: "Новая", PubTime: 50, Link: "https://example.com/4"}))
+	if err != nil || added != 1 {
+		t.Fatalf("Ожидается 1 новая публикация, добавлено %d (%v)", added, err)
+	}
+
+	tests := []struct {
+		name      string
+		search    string
+		limit     int
+		offset    int
+		wantTitle []string
+		wantTotal int
+	}{
+		{name: "Первая страница", limit: 2, wantTitle: []string{"Скидка 100%", "Погода"}, wantTotal: 4},
+		{name: "Вторая страница", limit: 2, offset: 2, wantTitle: []string{"Релиз Go 1.24", "Новая"}, wantTotal: 4},
+		{name: "Поиск без учета регистра", search: "релиз", limit: 10, wantTitle: []string{"Релиз Go 1.24"}, wantTotal: 1},
+		{name: "Символ шаблона в поиске", search: "0%", limit: 10, wantTitle: []string{"Скидка 100%"}, wantTotal: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, total, err := db.Posts(ctx, tt.search, tt.limit, tt.offset)
+			if err != nil {
+				t.Fatalf("Ошибка получения публикаций: %v", err)
+			}
+			var titles []string
+			for _, p := range got {
+				titles = append(titles, p.Title)
+			}
+			if total != tt.wantTotal || len(titles) != len(tt.wantTitle) {
+				t.Fatalf("Неверная страница: ожидается %v из %d, получено %v из %d", tt.wantTitle, tt.wantTotal, titles, total)
+			}
+			for i := range titles {
+				if titles[i] != tt.wantTitle[i] {
+					t.Errorf("Неверная страница: ожидается %v, получено %v", tt.wantTitle, titles)
+				}
+			}
+		})
+	}
+
+	first, _, err := db.Posts(ctx, "Релиз", 1, 0)
+	if err != nil || len(first) != 1 {
+		t.Fatalf("Ошибка поиска публикации: %v", err)
+	}
+	p, err := db.PostByID(ctx, first[0].ID)
+	if err != nil || p.Author != "anna" || p.Category != "tech" || p.Link != "https://example.com/1" {
+		t.Errorf("Неверная публикация: %+v (%v)", p, err)
+	}
+	if _, err := db.PostByID(ctx, -1); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Для несуществующей публикации ожидается ErrNotFound, получено %v", err)
+	}
+}
+
+func truncate(t *testing.T, dsn string) {
+	t.Helper()
+	pool, err := pgxpool.Connect(context.Background(), dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	if _, err := pool.Exec(context.Background(), `TRUNCATE posts RESTART IDENTITY`); err != nil {
+		t.Fatalf("Ошибка очистки таблицы: %v", err)
+	}
+}
@@ -1,20 +1,33 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 
 	"APIGetaway/pkg/api"
+	"APIGetaway/pkg/censor"
+	"APIGetaway/pkg/dedup"
 	"APIGetaway/pkg/migrations"
+	"APIGetaway/pkg/moderation"
 	"APIGetaway/pkg/storage"
+	"APIGetaway/pkg/storage/memdb"
+	"APIGetaway/pkg/storage/sqlite"
+	"APIGetaway/shared/logging"
+	"APIGetaway/shared/tracing"
 )
 
 // конфигурация приложения
 type config struct {
-	DB storage.DBConfig `json:"db"`
+	DB             storage.DBConfig `json:"db"`
+	Censor         censor.Config    `json:"censor"`          // подключение к сервису цензуры
+	Dedup          *dedup.Config    `json:"dedup"`           // поиск дубликатов, отключен если не задан
+	TrustedProxies []string         `json:"trusted_proxies"` // сети шлюза, выбирающего политику цензуры, по умолчанию localhost
+	Log            logging.Config   `json:"log"`
+	Trace          tracing.Config   `json:"trace"`
 }
 
 func main() {
@@ -29,16 +42,89 @@ func main() {
 		log.Fatal(err)
 	}
 	// инициализация зависимостей приложения
-	dbInfo := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s", config.DB.Host, config.DB.User, config.DB.Password, config.DB.DBName, config.DB.Port, config.DB.SSLMode)
+	if config.DB.Driver == "" {
+		config.DB.Driver = storage.DriverPostgres
+	}
+	dbInfo := config.DB.DSN()
+
+	// подкоманда управления миграциями: commapp migrate <команда>
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate(config.DB.Driver, dbInfo, os.Args[2:]); err != nil {
+			log.Printf("Ошибка миграции: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Журнал сервиса в stdout и файл с ротацией, SIGHUP открывает файл заново
+	logger, logFile, err := logging.Open("comments", config.Log)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer logFile.Close()
+	slog.SetDefault(logger)
 
-	db, err := storage.New(dbInfo)
+	// Трассировка OpenTelemetry, экспортер можно заменить переменной OTEL_TRACES_EXPORTER
+	tracer, err := tracing.Open("comments", config.Trace)
 	if err != nil {
 		log.Fatal(err)
 	}
-	migrations.RunMigrations(dbInfo)
-	api := api.New(db)
+	defer tracer.Close()
+
+	// перед запуском применяем невыполненные миграции
+	if config.DB.Driver != storage.DriverMemory {
+		results, err := migrations.RunMigrations(context.Background(), config.DB.Driver, dbInfo)
+		if err != nil {
+			log.Fatalf("Ошибка выполнения миграций: %v", err)
+		}
+		slog.Info("Миграции выполнены успешно", "applied", len(results))
+	}
+
+	var db storage.DBInterface
+	switch config.DB.Driver {
+	case storage.DriverPostgres:
+		db, err = storage.New(dbInfo)
+	case storage.DriverSQLite:
+		db, err = sqlite.New(dbInfo)
+	case storage.DriverMemory:
+		slog.Warn("Комментарии хранятся в памяти и будут потеряны при остановке")
+		db = memdb.New()
+	default:
+		log.Fatalf("Неизвестный драйвер хранилища %q", config.DB.Driver)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+	var dd *dedup.Index
+	if config.Dedup != nil {
+		dd, err = dedup.New(*config.Dedup)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	cc, err := censor.New(config.Censor)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer cc.Close()
+	mr := moderation.NewRunner(db, cc)
+	// продолжаем задания модерации, прерванные остановкой сервиса
+	if err := mr.ResumeInterrupted(context.Background()); err != nil {
+		slog.Error("Не удалось продолжить задания модерации", logging.Err(err))
+	}
+	trusted, err := api.ParseTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		log.Fatal(err)
+	}
+	api := api.New(db, cc, dd, mr, trusted)
+	// статистика пула соединений Postgres в /metrics
+	if pg, ok := db.(*storage.DB); ok {
+		api.Metrics().MustRegister(pg.Collector())
+	}
 
 	// запуск веб-сервера с API и приложением
+	slog.Info("Сервер запущен", "addr", ":8082")
 	err = http.ListenAndServe(":8082", api.Router())
 	if err != nil {
 		log.Fatal(err)
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"APIGetaway/pkg/migrations"
+	"APIGetaway/pkg/storage"
+
+	"github.com/pressly/goose/v3"
+)
+
+// migrateUsage - справка по подкоманде migrate.
+const migrateUsage = `Использование: commapp migrate [-dir каталог] <команда>
+
+Команды:
+  up            применить все невыполненные миграции
+  down          откатить последнюю миграцию
+  redo          откатить и заново применить последнюю миграцию
+  status        показать состояние миграций
+  create ИМЯ    создать файл новой SQL-миграции в каталоге -dir
+`
+
+// migrate выполняет подкоманду управления миграциями.
+func migrate(driver, dbInfo string, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	dir := flags.String("dir", "../pkg/storage/migrations/"+driver, "каталог исходных файлов миграций (для create)")
+	flags.Usage = func() { fmt.Fprint(flags.Output(), migrateUsage) }
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if driver == storage.DriverMemory {
+		return fmt.Errorf("миграции не поддерживаются драйвером %q", driver)
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("не указана команда")
+	}
+
+	command := flags.Arg(0)
+	if command == "create" {
+		if flags.NArg() != 2 {
+			return errors.New("create: укажите имя миграции")
+		}
+		return migrations.Create(*dir, flags.Arg(1))
+	}
+
+	m, err := migrations.New(driver, dbInfo)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		results, err := m.Up(ctx)
+		printResults(results)
+		return err
+	case "down":
+		result, err := m.Down(ctx)
+		if result != nil {
+			printResults([]*goose.MigrationResult{result})
+		}
+		return err
+	case "redo":
+		results, err := m.Redo(ctx)
+		printResults(results)
+		return err
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ВЕРСИЯ\tСОСТОЯНИЕ\tПРИМЕНЕНА\tФАЙЛ")
+		for _, s := range statuses {
+			applied := "-"
+			if s.State == goose.StateApplied {
+				applied = s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Source.Version, s.State, applied, s.Source.Path)
+		}
+		return w.Flush()
+	default:
+		flags.Usage()
+		return fmt.Errorf("неизвестная команда %q", command)
+	}
+}
+
+// printResults выводит результаты выполненных миграций.
+func printResults(results []*goose.MigrationResult) {
+	if len(results) == 0 {
+		fmt.Println("Нет миграций для выполнения")
+		return
+	}
+	for _, r := range results {
+		fmt.Println(r)
+	}
+}
@@ -1,34 +1,52 @@
 package api
 
 import (
+	"APIGetaway/pkg/censor"
+	"APIGetaway/pkg/dedup"
 	"APIGetaway/pkg/models"
+	"APIGetaway/pkg/moderation"
 	"APIGetaway/pkg/storage"
-	"context"
+	"APIGetaway/shared/logging"
+	"APIGetaway/shared/metrics"
+	"APIGetaway/shared/problem"
+	"APIGetaway/shared/tracing"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 )
 
+// PolicyHeader - заголовок с именем политики цензуры.
+const PolicyHeader = "X-Censor-Policy"
+
+// censorService - имя сервиса цензуры в поле upstream журнала.
+const censorService = "censor"
+
 // API структура.
 type API struct {
-	db storage.DBInterface
-	r  *chi.Mux
+	db         storage.DBInterface
+	r          *chi.Mux
+	censor     *censor.Client
+	dedup      *dedup.Index // индекс для поиска дубликатов, nil - поиск отключен
+	moderation *moderation.Runner
+	metrics    *metrics.Metrics
+	trusted    TrustedProxies // сети шлюза, выбирающего политику цензуры
 }
 
 // Конструктор API.
-func New(db storage.DBInterface) *API {
-	// Инициализируем логгер
-	initLogger()
-	a := API{db: db, r: chi.NewRouter()}
+func New(db storage.DBInterface, cc *censor.Client, dd *dedup.Index, mr *moderation.Runner, trusted TrustedProxies) *API {
+	a := API{db: db, r: chi.NewRouter(), censor: cc, dedup: dd, moderation: mr, metrics: metrics.New(), trusted: trusted}
 	a.endpoints()
 	return &a
 }
 
+// Metrics возвращает реестр метрик сервиса для регистрации метрик хранилища.
+func (api *API) Metrics() *metrics.Metrics {
+	return api.metrics
+}
+
 // Router возвращает маршрутизатор для использования
 // в качестве аргумента HTTP-сервера.
 func (api *API) Router() *chi.Mux {
@@ -37,111 +55,131 @@ func (api *API) Router() *chi.Mux {
 
 // Регистрация методов API в маршрутизаторе запросов.
 func (api *API) endpoints() {
-	// Middleware для логирования запросов
+	// Middleware для трассировки и логирования запросов
+	api.r.Use(tracing.Middleware)  // Спан запроса, продолжает трассу из заголовка traceparent
 	api.r.Use(RequestIDMiddleware) // Добавляем middleware для request_id
 	api.r.Use(LoggingMiddleware)   // Добавляем middleware для логирования
+	api.r.Use(api.metrics.Middleware)
+	api.r.NotFound(problem.NotFound)
+	api.r.MethodNotAllowed(problem.MethodNotAllowed)
 	api.r.Post("/comments", api.addCommentHandler)
 	api.r.Get("/comments", api.getCommentsHandler)
+	api.r.Get("/comments/search", api.searchCommentsHandler)
+
+	// Повторная модерация опубликованных комментариев
+	api.r.Post("/moderation/jobs", api.startModerationHandler)
+	api.r.Get("/moderation/jobs/{id}", api.getModerationHandler)
+	api.r.Get("/moderation/jobs/{id}/changes", api.getModerationChangesHandler)
+	api.r.Post("/moderation/jobs/{id}/pause", api.pauseModerationHandler)
+	api.r.Post("/moderation/jobs/{id}/resume", api.resumeModerationHandler)
+
+	api.r.Method(http.MethodGet, metrics.Path, api.metrics.Handler())
 }
 
 // Обработчик для добавления комментария.
 func (api *API) addCommentHandler(w http.ResponseWriter, r *http.Request) {
 	var comment models.Comment
 	if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
-		http.Error(w, "неверный формат запроса", http.StatusBadRequest)
+		problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "")
 		return
 	}
 
-	// Извлекаем request_id из контекста запроса
-	requestID, ok := r.Context().Value(RequestIDKey{}).(string)
-	if !ok {
-		fmt.Println()
+	if comment.Author != "" {
+		logging.AddFields(r.Context(), logging.KeyUser, comment.Author)
 	}
 
-	// Отправляем запрос к сервису цензуры для проверки текста комментария
-	censorPassed, err := api.checkCommentWithCensorshipService(comment.Text, requestID)
-	if err != nil {
-		http.Error(w, "ошибка проверки цензуры", http.StatusInternalServerError)
-		return
+	// Извлекаем request_id из контекста запроса
+	requestID, _ := r.Context().Value(RequestIDKey{}).(string)
+
+	// Проверяем, не публиковался ли такой же текст недавно. Отпечаток сразу
+	// попадает в индекс, чтобы одновременный повтор тоже считался дубликатом,
+	// и удаляется из него, если комментарий не будет опубликован. Индекс
+	// локален для процесса, а автор берется из запроса и не проверяется,
+	// поэтому это защита от случайных повторов, а не от намеренного спама.
+	duplicate, release := dedup.Result{}, func() {}
+	if api.dedup != nil {
+		fp := dedup.NewFingerprint(comment.Text)
+		duplicate, release = api.dedup.CheckAndAdd(fp, comment.Author, comment.NewsID, time.Now())
+		if duplicate.Duplicate && duplicate.Action == dedup.ActionReject {
+			problem.Error(w, r, http.StatusConflict, problem.CodeDuplicateComment, "published")
+			return
+		}
 	}
-	if !censorPassed {
-		http.Error(w, "Комментарий содержит недопустимые слова", http.StatusBadRequest)
+	published := false
+	defer func() {
+		if !published {
+			release()
+		}
+	}()
+
+	// Отправляем запрос к сервису цензуры для проверки текста комментария,
+	// политику цензуры выбирает шлюз в зависимости от раздела новости
+	policy := api.censorPolicy(r)
+	verdict, err := api.censor.Check(r.Context(), comment.Text, policy, requestID)
+	if err != nil {
+		requestLogger(r.Context()).Error("Ошибка проверки цензуры",
+			logging.KeyUpstream, censorService, logging.Err(err))
+		problem.Error(w, r, http.StatusBadGateway, problem.CodeUpstreamUnavailable, "censor")
 		return
 	}
 
 	// Устанавливаем статус и время создания по умолчанию
 	comment.CreatedAt = time.Now()
+	comment.Status = models.StatusPublished
+
+	// По политике mask нарушения не отклоняют комментарий, а маскируются
+	comment.Text, _ = verdict.MaskedText(comment.Text)
+
+	switch {
+	case verdict.Decision == models.DecisionDeny && verdict.Action != models.ActionMask:
+		// Возвращаем вердикт, чтобы пользователь видел, что именно нужно исправить
+		problem.Write(w, r, problem.New(http.StatusBadRequest, problem.CodeCommentRejected, "words").With("verdict", verdict))
+		return
+	case verdict.Decision == models.DecisionReview:
+		// Пограничный случай - комментарий отправляется на ручную проверку
+		comment.Status = models.StatusReview
+	case duplicate.Duplicate && duplicate.Action == dedup.ActionFlag:
+		// Похожий комментарий недавно публиковался - проверяем вручную
+		comment.Status = models.StatusReview
+	}
 
 	// Сохранение комментария в базе данных
-	id, err := api.db.AddComment(context.Background(), comment)
+	id, err := api.db.AddComment(r.Context(), comment)
 	if err != nil {
-		http.Error(w, "не удалось добавить комментарий", http.StatusInternalServerError)
+		internalError(w, r, "add_comment", err)
 		return
 	}
+	published = true
 
 	// Возвращаем ID созданного комментария
+	if comment.Status == models.StatusReview {
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": id, "status": comment.Status, "verdict": verdict})
+		return
+	}
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]int64{"id": id})
 }
 
-// checkCommentWithCensorshipService отправляет запрос к сервису цензуры с request_id.
-func (api *API) checkCommentWithCensorshipService(text, requestID string) (bool, error) {
-	// Адрес сервиса цензуры
-	censorshipServiceURL := fmt.Sprintf("http://localhost:8083/comments?request_id=%s", requestID)
-
-	// Формируем JSON-запрос
-	requestBody, err := json.Marshal(map[string]string{"text": text})
-	if err != nil {
-		return false, err
-	}
-
-	// Создаем запрос к сервису цензуры
-	req, err := http.NewRequest("POST", censorshipServiceURL, strings.NewReader(string(requestBody)))
-	if err != nil {
-		return false, err
-	}
-
-	// Устанавливаем заголовок Content-Type и request_id
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("request_id", requestID)
-
-	// Выполняем запрос
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return false, err
-	}
-	defer resp.Body.Close()
-
-	// Обрабатываем ответ от сервиса цензуры
-	if resp.StatusCode == http.StatusOK {
-		return true, nil // Комментарий прошел цензуру
-	} else if resp.StatusCode == http.StatusBadRequest {
-		return false, nil // Комментарий не прошел цензуру
-	}
-
-	return false, fmt.Errorf("непредвиденный статус ответа от сервиса цензуры: %d", resp.StatusCode)
-}
-
 // Обработчик для получения комментариев по ID новости.
 func (api *API) getCommentsHandler(w http.ResponseWriter, r *http.Request) {
 	newsIDParam := r.URL.Query().Get("news_id")
 	if newsIDParam == "" {
-		http.Error(w, "news_id является обязательным параметром", http.StatusBadRequest)
+		problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "required", "news_id")
 		return
 	}
 
 	// Преобразование ID новости из строки в число
 	newsID, err := strconv.ParseInt(newsIDParam, 10, 64)
 	if err != nil {
-		http.Error(w, "неверный формат news_id", http.StatusBadRequest)
+		problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "format", "news_id")
 		return
 	}
 
 	// Получение комментариев из базы данных
-	comments, err := api.db.GetCommentsByNewsID(context.Background(), newsID)
+	comments, err := api.db.GetCommentsByNewsID(r.Context(), newsID)
 	if err != nil {
-		http.Error(w, "не удалось получить комментарии", http.StatusInternalServerError)
+		internalError(w, r, "comments", err)
 		return
 	}
 
@@ -149,3 +187,10 @@ func (api *API) getCommentsHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(comments)
 }
+
+// internalError записывает ошибку err в журнал запроса и отправляет ответ 500
+// с описанием msg из каталога сообщений.
+func internalError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	requestLogger(r.Context()).Error(problem.Message(problem.DefaultLang, problem.CodeInternal+"."+msg), logging.Err(err))
+	problem.Error(w, r, http.StatusInternalServerError, problem.CodeInternal, msg)
+}
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"APIGetaway/pkg/censor"
+	"APIGetaway/pkg/censorpb"
+	"APIGetaway/pkg/models"
+	"APIGetaway/pkg/storage/memdb"
+	"APIGetaway/shared/tracing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// fakeCensor - сервис цензуры, возвращающий заданные вердикты по тексту.
+// Тексты без вердикта разрешаются.
+type fakeCensor struct {
+	censorpb.UnimplementedCensorServer
+	verdicts map[string]*censorpb.Verdict
+}
+
+func (f *fakeCensor) Check(ctx context.Context, req *censorpb.CheckRequest) (*censorpb.Verdict, error) {
+	if v, ok := f.verdicts[req.GetText()]; ok {
+		return v, nil
+	}
+	return &censorpb.Verdict{Policy: "default", Decision: models.DecisionAllow, Action: models.ActionReject}, nil
+}
+
+// newTestCensor запускает сервис цензуры fc и возвращает клиент к нему.
+func newTestCensor(t *testing.T, fc *fakeCensor) *censor.Client {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Ошибка запуска сервиса цензуры: %v", err)
+	}
+	srv := grpc.NewServer()
+	censorpb.RegisterCensorServer(srv, fc)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	cc, err := censor.New(censor.Config{Addr: lis.Addr().String()})
+	if err != nil {
+		t.Fatalf("Ошибка создания клиента цензуры: %v", err)
+	}
+	t.Cleanup(func() { cc.Close() })
+	return cc
+}
+
+// newTestAPI создает API с хранилищем db и сервисом цензуры fc.
+func newTestAPI(t *testing.T, db *tracedDB, fc *fakeCensor) *API {
+	t.Helper()
+	trusted, err := ParseTrustedProxies(nil)
+	if err != nil {
+		t.Fatalf("Ошибка разбора сетей по умолчанию: %v", err)
+	}
+	return New(db, newTestCensor(t, fc), nil, nil, trusted)
+}
+
+// tracedDB - хранилище в памяти, которое создает спаны запросов так же,
+// как queryTracer хранилища Postgres: только внутри трассы запроса.
+type tracedDB struct {
+	*memdb.DB
+}
+
+func (db *tracedDB) AddComment(ctx context.Context, comment models.Comment) (int64, error) {
+	querySpan(ctx, "INSERT")
+	return db.DB.AddComment(ctx, comment)
+}
+
+func (db *tracedDB) GetCommentsByNewsID(ctx context.Context, newsID int64) ([]models.Comment, error) {
+	querySpan(ctx, "SELECT")
+	return db.DB.GetCommentsByNewsID(ctx, newsID)
+}
+
+func querySpan(ctx context.Context, name string) {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return
+	}
+	_, span := tracing.Tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+	span.End()
+}
+
+func TestAPI_querySpans(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+
+	api := newTestAPI(t, &tracedDB{DB: memdb.New()}, &fakeCensor{})
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		wantCode int
+		wantSpan string // спан запроса к хранилищу
+	}{
+		{"Добавление комментария", http.MethodPost, "/comments", `{"news_id":1,"text":"Хорошая новость"}`, http.StatusCreated, "INSERT"},
+		{"Комментарии новости", http.MethodGet, "/comments?news_id=1", "", http.StatusOK, "SELECT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(rec.Ended())
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			api.Router().ServeHTTP(rr, req)
+			if rr.Code != tt.wantCode {
+				t.Fatalf("Код ответа: ожидается %d, получено %d: %s", tt.wantCode, rr.Code, rr.Body)
+			}
+
+			var query, server sdktrace.ReadOnlySpan
+			for _, s := range rec.Ended()[before:] {
+				switch {
+				case s.Name() == tt.wantSpan:
+					query = s
+				case s.SpanKind() == trace.SpanKindServer:
+					server = s
+				}
+			}
+			if server == nil {
+				t.Fatalf("Нет спана запроса %s %s", tt.method, tt.target)
+			}
+			if query == nil {
+				t.Fatalf("Нет спана %s запроса к хранилищу", tt.wantSpan)
+			}
+			if query.Parent().SpanID() != server.SpanContext().SpanID() {
+				t.Errorf("Спан %s не входит в трассу запроса", tt.wantSpan)
+			}
+		})
+	}
+}
+
+func TestAPI_addComment(t *testing.T) {
+	fc := &fakeCensor{verdicts: map[string]*censorpb.Verdict{
+		"Ты дурак": {
+			Policy: "relaxed", Decision: models.DecisionAllow, Score: 0.5,
+			Action: models.ActionMask, SanitizedText: "Ты *****",
+		},
+		"Это qwerty": {
+			Policy: "relaxed", Decision: models.DecisionDeny, Score: 2,
+			Action: models.ActionMask, SanitizedText: "Это ******",
+		},
+		"Это qwerty!": {Policy: "default", Decision: models.DecisionDeny, Score: 1, Action: models.ActionReject},
+		"Может быть":  {Policy: "default", Decision: models.DecisionReview, Score: 0.5, Action: models.ActionReject},
+	}}
+
+	tests := []struct {
+		name     string
+		text     string
+		wantCode int
+		wantText string // опубликованный текст, пустой - комментарий не опубликован
+	}{
+		{name: "Допустимый текст", text: "Хорошая новость", wantCode: http.StatusCreated, wantText: "Хорошая новость"},
+		{name: "Маскирование при решении allow", text: "Ты дурак", wantCode: http.StatusCreated, wantText: "Ты *****"},
+		{name: "Маскирование при решении deny", text: "Это qwerty", wantCode: http.StatusCreated, wantText: "Это ******"},
+		{name: "Отклонение", text: "Это qwerty!", wantCode: http.StatusBadRequest},
+		{name: "Ручная проверка", text: "Может быть", wantCode: http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &tracedDB{DB: memdb.New()}
+			api := newTestAPI(t, db, fc)
+			body := fmt.Sprintf(`{"news_id":1,"text":%q}`, tt.text)
+			rr := httptest.NewRecorder()
+			api.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/comments", strings.NewReader(body)))
+			if rr.Code != tt.wantCode {
+				t.Fatalf("Код ответа: ожидается %d, получено %d: %s", tt.wantCode, rr.Code, rr.Body)
+			}
+
+			comments, err := db.GetCommentsByNewsID(context.Background(), 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got string
+			if len(comments) > 0 {
+				got = comments[0].Text
+			}
+			if got != tt.wantText {
+				t.Errorf("Опубликованный текст: ожидается %q, получено %q", tt.wantText, got)
+			}
+		})
+	}
+}
+
+func TestAPI_startModeration_batchSize(t *testing.T) {
+	api := newTestAPI(t, &tracedDB{DB: memdb.New()}, &fakeCensor{})
+	for _, size := range []int{-1, 1001, 100000} {
+		body := fmt.Sprintf(`{"batch_size":%d}`, size)
+		rr := httptest.NewRecorder()
+		api.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/moderation/jobs", strings.NewReader(body)))
+		if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), `"invalid_parameter"`) {
+			t.Errorf("batch_size %d: ожидается 400 invalid_parameter, получено %d: %s", size, rr.Code, rr.Body)
+		}
+	}
+}
+
+func TestAPI_searchComments_page(t *testing.T) {
+	api := newTestAPI(t, &tracedDB{DB: memdb.New()}, &fakeCensor{})
+	tests := []struct {
+		target   string
+		wantCode int
+	}{
+		{"/comments/search?q=news&page=10000&page_size=100", http.StatusOK},
+		{"/comments/search?q=news&page=10001", http.StatusBadRequest},
+		{"/comments/search?q=news&page=9223372036854775807&page_size=100", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		api.Router().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if rr.Code != tt.wantCode {
+			t.Errorf("%s: ожидается код %d, получено %d: %s", tt.target, tt.wantCode, rr.Code, rr.Body)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"APIGetaway/pkg/moderation"
+	"APIGetaway/pkg/storage"
+	"APIGetaway/shared/problem"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Обработчик для запуска задания повторной модерации.
+func (api *API) startModerationHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Policy    string `json:"policy"`
+		DryRun    bool   `json:"dry_run"`
+		BatchSize int    `json:"batch_size"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidRequest, "")
+		return
+	}
+	// Пакет целиком уходит в сервис цензуры, который ограничивает число элементов
+	if req.BatchSize < 0 || req.BatchSize > moderation.MaxBatchSize {
+		problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "range", "batch_size", 1, moderation.MaxBatchSize)
+		return
+	}
+
+	job, err := api.moderation.Start(r.Context(), req.Policy, req.DryRun, req.BatchSize)
+	if err != nil {
+		internalError(w, r, "start_job", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// Обработчик для получения состояния задания повторной модерации.
+func (api *API) getModerationHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := jobID(w, r)
+	if !ok {
+		return
+	}
+	job, err := api.db.GetModerationJob(r.Context(), id)
+	if errors.Is(err, storage.ErrNotFound) {
+		problem.Error(w, r, http.StatusNotFound, problem.CodeNotFound, "job")
+		return
+	}
+	if err != nil {
+		internalError(w, r, "job", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// Обработчик для получения изменений, внесенных (или предлагаемых) заданием.
+func (api *API) getModerationChangesHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := jobID(w, r)
+	if !ok {
+		return
+	}
+	changes, err := api.db.GetModerationChanges(r.Context(), id)
+	if err != nil {
+		internalError(w, r, "changes", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(changes)
+}
+
+// Обработчик для приостановки задания повторной модерации.
+func (api *API) pauseModerationHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := jobID(w, r)
+	if !ok {
+		return
+	}
+	if !api.moderation.Pause(id) {
+		problem.Error(w, r, http.StatusConflict, problem.CodeConflict, "job_not_running")
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Обработчик для продолжения задания повторной модерации с контрольной точки.
+func (api *API) resumeModerationHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := jobID(w, r)
+	if !ok {
+		return
+	}
+	job, err := api.moderation.Resume(r.Context(), id)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		problem.Error(w, r, http.StatusNotFound, problem.CodeNotFound, "job")
+		return
+	case errors.Is(err, moderation.ErrJobActive):
+		problem.Error(w, r, http.StatusConflict, problem.CodeConflict, "job_active")
+		return
+	case errors.Is(err, moderation.ErrJobFinished):
+		problem.Error(w, r, http.StatusConflict, problem.CodeConflict, "job_finished")
+		return
+	case err != nil:
+		internalError(w, r, "resume_job", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// jobID извлекает ID задания из пути запроса.
+func jobID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "format", "id")
+		return 0, false
+	}
+	return id, true
+}
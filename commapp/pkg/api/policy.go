@@ -0,0 +1,64 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+)
+
+// DefaultTrustedProxies - сети, из которых по умолчанию принимается заголовок
+// X-Censor-Policy: шлюз работает на той же машине, что и сервис комментариев.
+var DefaultTrustedProxies = []string{"127.0.0.0/8", "::1/128"}
+
+// TrustedProxies - сети шлюза, которому разрешено выбирать политику цензуры.
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies разбирает список сетей в формате CIDR. Если список пуст,
+// используются сети DefaultTrustedProxies.
+func ParseTrustedProxies(cidrs []string) (TrustedProxies, error) {
+	if len(cidrs) == 0 {
+		cidrs = DefaultTrustedProxies
+	}
+	var res TrustedProxies
+	for _, s := range cidrs {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("неверная сеть доверенного шлюза %q: %w", s, err)
+		}
+		res = append(res, p.Masked())
+	}
+	return res, nil
+}
+
+// contains сообщает, относится ли адрес клиента host:port к доверенным сетям.
+func (t TrustedProxies) contains(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range t {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// censorPolicy возвращает политику цензуры из заголовка X-Censor-Policy.
+// Заголовок принимается только от доверенного шлюза, у остальных клиентов
+// он не учитывается и применяется политика по умолчанию.
+func (api *API) censorPolicy(r *http.Request) string {
+	policy := r.Header.Get(PolicyHeader)
+	if policy == "" || api.trusted.contains(r.RemoteAddr) {
+		return policy
+	}
+	requestLogger(r.Context()).Warn("Заголовок политики цензуры от недоверенного клиента не учитывается",
+		"policy", policy)
+	return ""
+}
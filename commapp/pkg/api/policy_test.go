@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCensorPolicy(t *testing.T) {
+	trusted, err := ParseTrustedProxies(nil)
+	if err != nil {
+		t.Fatalf("Ошибка разбора сетей по умолчанию: %v", err)
+	}
+	api := &API{trusted: trusted}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		policy     string
+		want       string
+	}{
+		{"Шлюз на localhost", "127.0.0.1:51000", "strict", "strict"},
+		{"Шлюз на localhost IPv6", "[::1]:51000", "relaxed", "relaxed"},
+		{"Внешний клиент", "203.0.113.7:51000", "relaxed", ""},
+		{"Без заголовка", "203.0.113.7:51000", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/comments", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.policy != "" {
+				req.Header.Set(PolicyHeader, tt.policy)
+			}
+			if got := api.censorPolicy(req); got != tt.want {
+				t.Errorf("Политика: ожидается %q, получено %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	if _, err := ParseTrustedProxies([]string{"10.0.0.0/8", "gateway"}); err == nil {
+		t.Errorf("Ожидается ошибка для неверной сети")
+	}
+	trusted, err := ParseTrustedProxies([]string{"10.1.2.0/24"})
+	if err != nil {
+		t.Fatalf("Ошибка разбора: %v", err)
+	}
+	if !trusted.contains("10.1.2.3:80") || trusted.contains("127.0.0.1:80") {
+		t.Errorf("Неверная проверка адреса для сетей %v", trusted)
+	}
+}
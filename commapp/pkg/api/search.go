@@ -0,0 +1,78 @@
+package api
+
+import (
+	"APIGetaway/pkg/models"
+	"APIGetaway/pkg/storage"
+	"APIGetaway/shared/problem"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// Размер страницы результатов поиска
+const (
+	DefaultSearchPageSize = 20
+	MaxSearchPageSize     = 100
+)
+
+// MaxSearchPage - наибольший номер страницы, ограничивает смещение выборки.
+const MaxSearchPage = 10000
+
+// Обработчик полнотекстового поиска комментариев:
+// q - запрос, news_id - новость (необязательно), page и page_size - страница результатов.
+func (api *API) searchCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := storage.SearchQuery{Text: query.Get("q")}
+	if len(storage.SearchTerms(q.Text)) == 0 {
+		problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "required", "q")
+		return
+	}
+	if s := query.Get("news_id"); s != "" {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || id <= 0 {
+			problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "format", "news_id")
+			return
+		}
+		q.NewsID = id
+	}
+	page, ok := intParam(w, r, "page", 1, 1, MaxSearchPage)
+	if !ok {
+		return
+	}
+	pageSize, ok := intParam(w, r, "page_size", DefaultSearchPageSize, 1, MaxSearchPageSize)
+	if !ok {
+		return
+	}
+	q.Limit, q.Offset = pageSize, (page-1)*pageSize
+
+	matches, total, err := api.db.SearchComments(r.Context(), q)
+	if err != nil {
+		internalError(w, r, "search", err)
+		return
+	}
+	if matches == nil {
+		matches = []models.CommentMatch{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items":     matches,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
+
+// intParam читает целый параметр запроса name в границах [lo, hi] (hi 0 - без верхней границы).
+// При ошибке отправляет ответ 400 и возвращает false.
+func intParam(w http.ResponseWriter, r *http.Request, name string, def, lo, hi int) (int, bool) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return def, true
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < lo || (hi > 0 && v > hi) {
+		problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidParameter, "value", name)
+		return 0, false
+	}
+	return v, true
+}
@@ -0,0 +1,153 @@
+// Package censor - клиент сервиса цензуры.
+package censor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"APIGetaway/pkg/censorpb"
+	"APIGetaway/pkg/models"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// Параметры подключения по умолчанию
+const (
+	DefaultAddr    = "localhost:9083" // адрес gRPC-сервера цензуры
+	DefaultTimeout = 3 * time.Second  // предельное время одного вызова
+)
+
+// MetadataRequestID - ключ метаданных gRPC с идентификатором запроса.
+const MetadataRequestID = "request_id"
+
+// Config - параметры подключения к сервису цензуры.
+type Config struct {
+	Addr    string `json:"addr"`    // адрес gRPC-сервера, например "localhost:9083"
+	Timeout string `json:"timeout"` // предельное время вызова, например "3s"
+}
+
+// Client выполняет запросы к сервису цензуры.
+type Client struct {
+	conn    *grpc.ClientConn
+	censor  censorpb.CensorClient
+	timeout time.Duration
+}
+
+// New создает клиент сервиса цензуры. Соединение устанавливается при первом вызове.
+func New(cfg Config) (*Client, error) {
+	if cfg.Addr == "" {
+		cfg.Addr = DefaultAddr
+	}
+	timeout := DefaultTimeout
+	if cfg.Timeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("неверное время ожидания сервиса цензуры: %w", err)
+		}
+	}
+	conn, err := grpc.NewClient(cfg.Addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		// Спаны вызовов, контекст трассировки передается в метаданных traceparent
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, censor: censorpb.NewCensorClient(conn), timeout: timeout}, nil
+}
+
+// Close закрывает соединение с сервисом цензуры.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Check отправляет текст на проверку и возвращает вердикт.
+// Пустая политика означает политику по умолчанию.
+func (c *Client) Check(ctx context.Context, text, policy, requestID string) (*models.Verdict, error) {
+	ctx, cancel := c.callContext(ctx, requestID)
+	defer cancel()
+
+	v, err := c.censor.Check(ctx, &censorpb.CheckRequest{Text: text, Policy: policy})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка проверки в сервисе цензуры: %w", err)
+	}
+	verdict := fromProtoVerdict(v)
+	return &verdict, nil
+}
+
+// BatchItem - текст для пакетной проверки.
+type BatchItem struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// CheckBatch отправляет тексты на пакетную проверку и возвращает вердикты по ID элементов.
+// Элементы, которые сервис не смог проверить, возвращаются ошибкой.
+func (c *Client) CheckBatch(ctx context.Context, items []BatchItem, policy, requestID string) (map[string]models.Verdict, error) {
+	ctx, cancel := c.callContext(ctx, requestID)
+	defer cancel()
+
+	req := &censorpb.CheckBatchRequest{Policy: policy, Items: make([]*censorpb.CheckItem, 0, len(items))}
+	for _, item := range items {
+		req.Items = append(req.Items, &censorpb.CheckItem{Id: item.ID, Text: item.Text})
+	}
+	resp, err := c.censor.CheckBatch(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка пакетной проверки в сервисе цензуры: %w", err)
+	}
+
+	verdicts := make(map[string]models.Verdict, len(resp.GetResults()))
+	for _, res := range resp.GetResults() {
+		if res.GetVerdict() == nil {
+			return nil, fmt.Errorf("сервис цензуры не проверил элемент %s: %s", res.GetId(), res.GetError())
+		}
+		verdicts[res.GetId()] = fromProtoVerdict(res.GetVerdict())
+	}
+	return verdicts, nil
+}
+
+// callContext ограничивает время вызова и передает request_id в метаданных.
+func (c *Client) callContext(ctx context.Context, requestID string) (context.Context, context.CancelFunc) {
+	if requestID != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, MetadataRequestID, requestID)
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+// fromProtoVerdict преобразует сообщение gRPC в вердикт.
+func fromProtoVerdict(v *censorpb.Verdict) models.Verdict {
+	out := models.Verdict{
+		Policy:        v.GetPolicy(),
+		Version:       v.GetDictionaryVersion(),
+		Language:      v.GetLanguage(),
+		Decision:      v.GetDecision(),
+		Score:         v.GetScore(),
+		Rules:         v.GetRules(),
+		Categories:    v.GetCategories(),
+		Action:        v.GetAction(),
+		SanitizedText: v.GetSanitizedText(),
+	}
+	for _, m := range v.GetMatches() {
+		out.Matches = append(out.Matches, models.Match{
+			RuleID:   m.GetRuleId(),
+			Category: m.GetCategory(),
+			Fragment: m.GetFragment(),
+			Start:    int(m.GetStart()),
+			End:      int(m.GetEnd()),
+		})
+	}
+	for _, s := range v.GetSignals() {
+		out.Signals = append(out.Signals, models.Signal{
+			Detector: s.GetDetector(),
+			Score:    s.GetScore(),
+			Weight:   s.GetWeight(),
+			Reason:   s.GetReason(),
+		})
+	}
+	return out
+}
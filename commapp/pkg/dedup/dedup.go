@@ -0,0 +1,268 @@
+// Package dedup обнаруживает повторную публикацию одинаковых и почти одинаковых
+// комментариев в пределах скользящего окна времени.
+//
+// Ограничения:
+//   - индекс хранится в памяти процесса: после перезапуска он пуст, а каждая
+//     реплика сервиса видит только комментарии, принятые ею самой, поэтому
+//     повтор, попавший на другую реплику, дубликатом не считается;
+//   - поиск почти одинаковых текстов перебирает все записи окна, время проверки
+//     растет линейно с числом комментариев за окно, поэтому окно должно быть
+//     коротким (минуты, а не часы);
+//   - автор сравнивается по значению, которое передал клиент. Сменив имя автора,
+//     можно обойти действие same_author, и повтор получит действие cross_author.
+//     Поэтому cross_author не стоит делать мягче, чем flag, если same_author - reject.
+package dedup
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Действия при обнаружении дубликата
+const (
+	ActionIgnore = "ignore" // дубликат не учитывается
+	ActionFlag   = "flag"   // комментарий отправляется на ручную проверку
+	ActionReject = "reject" // комментарий отклоняется
+)
+
+// DefaultMinLength - минимальная длина нормализованного текста по умолчанию.
+// Короткие ответы вроде "спасибо" или "+1" повторяются естественным образом
+// и дубликатами не считаются.
+const DefaultMinLength = 20
+
+// Config - параметры поиска дубликатов.
+type Config struct {
+	Window      string `json:"window"`       // окно времени, например "10m"
+	MaxDistance int    `json:"max_distance"` // максимальное расстояние Хэмминга между SimHash почти одинаковых текстов
+	SameAuthor  string `json:"same_author"`  // действие для дубликата от того же автора
+	CrossAuthor string `json:"cross_author"` // действие для дубликата от другого автора
+	MinLength   int    `json:"min_length"`   // тексты короче этого числа символов не проверяются, по умолчанию 20
+}
+
+// Fingerprint - отпечаток текста комментария.
+type Fingerprint struct {
+	Hash    uint64 // хеш нормализованного текста, совпадает у точных дубликатов
+	SimHash uint64 // SimHash, близок у почти одинаковых текстов
+	Length  int    // длина нормализованного текста в символах
+}
+
+// NewFingerprint вычисляет отпечаток текста.
+func NewFingerprint(text string) Fingerprint {
+	norm := Normalize(text)
+	h := fnv.New64a()
+	h.Write([]byte(norm))
+	return Fingerprint{Hash: h.Sum64(), SimHash: simHash(norm), Length: utf8.RuneCountInString(norm)}
+}
+
+// Normalize приводит текст к нижнему регистру, удаляет знаки препинания
+// и схлопывает пробелы, чтобы мелкие правки не меняли отпечаток.
+func Normalize(text string) string {
+	var sb strings.Builder
+	space := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && sb.Len() > 0 {
+				sb.WriteByte(' ')
+			}
+			space = false
+			sb.WriteRune(r)
+		default:
+			space = true
+		}
+	}
+	return sb.String()
+}
+
+// shingleSize - длина символьных n-грамм для SimHash.
+const shingleSize = 3
+
+// simHash вычисляет 64-битный SimHash по символьным n-граммам текста.
+func simHash(text string) uint64 {
+	runes := []rune(text)
+	if len(runes) < shingleSize {
+		h := fnv.New64a()
+		h.Write([]byte(text))
+		return h.Sum64()
+	}
+	var weights [64]int
+	for i := 0; i+shingleSize <= len(runes); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(string(runes[i : i+shingleSize])))
+		sum := h.Sum64()
+		for b := 0; b < 64; b++ {
+			if sum&(1<<uint(b)) != 0 {
+				weights[b]++
+			} else {
+				weights[b]--
+			}
+		}
+	}
+	var res uint64
+	for b := 0; b < 64; b++ {
+		if weights[b] > 0 {
+			res |= 1 << uint(b)
+		}
+	}
+	return res
+}
+
+// Result - результат поиска дубликата.
+type Result struct {
+	Duplicate  bool   // найден точный или почти точный дубликат
+	Exact      bool   // тексты совпадают после нормализации
+	SameAuthor bool   // дубликат опубликован тем же автором
+	NewsID     int64  // новость, к которой был опубликован найденный комментарий
+	Distance   int    // расстояние Хэмминга между SimHash
+	Action     string // действие согласно конфигурации
+}
+
+// entry - запись индекса.
+type entry struct {
+	seq    uint64 // номер записи для отмены добавления
+	fp     Fingerprint
+	author string
+	newsID int64
+	at     time.Time
+}
+
+// Index - индекс отпечатков комментариев за скользящее окно времени.
+// Индекс не сохраняется между перезапусками и не разделяется между репликами.
+type Index struct {
+	mu          sync.Mutex
+	window      time.Duration
+	maxDistance int
+	sameAuthor  string
+	crossAuthor string
+	minLength   int
+	entries     []entry // записи в порядке добавления
+	seq         uint64  // номер последней добавленной записи
+}
+
+// New создает индекс с заданной конфигурацией.
+func New(cfg Config) (*Index, error) {
+	window, err := time.ParseDuration(cfg.Window)
+	if err != nil {
+		return nil, fmt.Errorf("неверное окно поиска дубликатов: %w", err)
+	}
+	if window <= 0 {
+		return nil, fmt.Errorf("окно поиска дубликатов должно быть положительным: %s", cfg.Window)
+	}
+	if cfg.MaxDistance < 0 || cfg.MaxDistance > 64 {
+		return nil, fmt.Errorf("расстояние max_distance должно быть от 0 до 64: %d", cfg.MaxDistance)
+	}
+	if cfg.MinLength < 0 {
+		return nil, fmt.Errorf("длина min_length не может быть отрицательной: %d", cfg.MinLength)
+	}
+	idx := Index{
+		window:      window,
+		maxDistance: cfg.MaxDistance,
+		sameAuthor:  cfg.SameAuthor,
+		crossAuthor: cfg.CrossAuthor,
+		minLength:   cfg.MinLength,
+	}
+	if idx.sameAuthor == "" {
+		idx.sameAuthor = ActionReject
+	}
+	if idx.crossAuthor == "" {
+		idx.crossAuthor = ActionFlag
+	}
+	if idx.minLength == 0 {
+		idx.minLength = DefaultMinLength
+	}
+	for _, action := range []string{idx.sameAuthor, idx.crossAuthor} {
+		switch action {
+		case ActionIgnore, ActionFlag, ActionReject:
+		default:
+			return nil, fmt.Errorf("неизвестное действие для дубликата %q", action)
+		}
+	}
+	return &idx, nil
+}
+
+// CheckAndAdd ищет в окне комментарий, совпадающий с отпечатком, и, если
+// комментарий не отклоняется как дубликат, сразу добавляет отпечаток в индекс.
+// Поиск и добавление выполняются атомарно, поэтому из одновременных запросов
+// с одинаковым текстом дубликат обнаруживают все, кроме первого.
+// Если комментарий в итоге не опубликован, добавление нужно отменить
+// возвращенной функцией release. Тексты короче min_length не проверяются
+// и в индекс не добавляются.
+func (idx *Index) CheckAndAdd(fp Fingerprint, author string, newsID int64, now time.Time) (res Result, release func()) {
+	release = func() {}
+	if fp.Length < idx.minLength {
+		return Result{}, release
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.prune(now)
+	res = idx.find(fp, author)
+	if res.Duplicate && res.Action == ActionReject {
+		return res, release
+	}
+
+	idx.seq++
+	seq := idx.seq
+	idx.entries = append(idx.entries, entry{seq: seq, fp: fp, author: author, newsID: newsID, at: now})
+	return res, func() { idx.remove(seq) }
+}
+
+// find ищет в индексе комментарий, совпадающий с отпечатком.
+// Совпадения от того же автора имеют приоритет над совпадениями от других авторов.
+func (idx *Index) find(fp Fingerprint, author string) Result {
+	var best Result
+	for _, e := range idx.entries {
+		distance := bits.OnesCount64(e.fp.SimHash ^ fp.SimHash)
+		exact := e.fp.Hash == fp.Hash
+		if !exact && distance > idx.maxDistance {
+			continue
+		}
+		res := Result{
+			Duplicate:  true,
+			Exact:      exact,
+			SameAuthor: author != "" && e.author == author, // анонимные повторы считаются чужими
+			NewsID:     e.newsID,
+			Distance:   distance,
+		}
+		if !best.Duplicate || (res.SameAuthor && !best.SameAuthor) {
+			best = res
+		}
+	}
+	if !best.Duplicate {
+		return best
+	}
+	best.Action = idx.crossAuthor
+	if best.SameAuthor {
+		best.Action = idx.sameAuthor
+	}
+	return best
+}
+
+// remove удаляет запись с номером seq, если она еще не вышла за пределы окна.
+func (idx *Index) remove(seq uint64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for i, e := range idx.entries {
+		if e.seq == seq {
+			idx.entries = append(idx.entries[:i], idx.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// prune удаляет записи, вышедшие за пределы окна.
+func (idx *Index) prune(now time.Time) {
+	cut := 0
+	for cut < len(idx.entries) && now.Sub(idx.entries[cut].at) > idx.window {
+		cut++
+	}
+	if cut > 0 {
+		idx.entries = append(idx.entries[:0], idx.entries[cut:]...)
+	}
+}
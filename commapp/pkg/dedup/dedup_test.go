@@ -0,0 +1,207 @@
+package dedup
+
+import (
+	"math/bits"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	text    = "Отличная статья, спасибо автору за подробный разбор темы"
+	similar = "Отличная статья, спасибо автору за подробный разбор этой темы"
+	other   = "Совершенно не согласен с выводами, данные устарели лет на пять"
+)
+
+func newIndex(t *testing.T, cfg Config) *Index {
+	t.Helper()
+	if cfg.Window == "" {
+		cfg.Window = "10m"
+	}
+	idx, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Ошибка создания индекса: %v", err)
+	}
+	return idx
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "Отличная статья!", want: "отличная статья"},
+		{text: "  ОТЛИЧНАЯ,   статья...  ", want: "отличная статья"},
+		{text: "Спасибо 2 раза", want: "спасибо 2 раза"},
+		{text: "!!!", want: ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.text); got != tt.want {
+			t.Errorf("Normalize(%q): ожидается %q, получено %q", tt.text, tt.want, got)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "Значения по умолчанию", cfg: Config{Window: "10m"}},
+		{name: "Все действия", cfg: Config{Window: "1h", SameAuthor: ActionFlag, CrossAuthor: ActionIgnore}},
+		{name: "Неверное окно", cfg: Config{Window: "десять минут"}, wantErr: true},
+		{name: "Нулевое окно", cfg: Config{Window: "0s"}, wantErr: true},
+		{name: "Неизвестное действие для автора", cfg: Config{Window: "10m", SameAuthor: "block"}, wantErr: true},
+		{name: "Неизвестное действие для других авторов", cfg: Config{Window: "10m", CrossAuthor: "Reject"}, wantErr: true},
+		{name: "Отрицательное расстояние", cfg: Config{Window: "10m", MaxDistance: -1}, wantErr: true},
+		{name: "Отрицательная длина", cfg: Config{Window: "10m", MinLength: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Ошибка: ожидается %v, получено %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckAndAdd(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		cfg         Config
+		first       string // текст первого комментария, по умолчанию text
+		firstAuthor string // автор первого комментария, по умолчанию ivan
+		author      string
+		text        string
+		after       time.Duration // время после первого комментария
+		wantDup     bool
+		wantAction  string
+	}{
+		{name: "Тот же автор", author: "ivan", text: text, after: time.Minute, wantDup: true, wantAction: ActionReject},
+		{name: "Другой автор", author: "petr", text: text, after: time.Minute, wantDup: true, wantAction: ActionFlag},
+		{name: "Правка регистра и знаков", author: "ivan", text: "ОТЛИЧНАЯ статья!!! Спасибо автору за подробный разбор темы", wantDup: true, wantAction: ActionReject},
+		{name: "Другой текст", author: "ivan", text: other, wantDup: false},
+		{name: "Окно истекло", author: "ivan", text: text, after: 11 * time.Minute, wantDup: false},
+		{name: "Граница окна", author: "ivan", text: text, after: 10 * time.Minute, wantDup: true, wantAction: ActionReject},
+		{name: "Игнорирование других авторов", cfg: Config{CrossAuthor: ActionIgnore}, author: "petr", text: text, wantDup: true, wantAction: ActionIgnore},
+		{name: "Короткий текст", first: "Спасибо", author: "ivan", text: "Спасибо", wantDup: false},
+		{name: "Короткий текст с настройкой", cfg: Config{MinLength: 5}, first: "Спасибо", author: "ivan", text: "Спасибо", wantDup: true, wantAction: ActionReject},
+		{name: "Анонимные авторы", firstAuthor: "-", author: "", text: text, wantDup: true, wantAction: ActionFlag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx := newIndex(t, tt.cfg)
+			first, firstAuthor := tt.first, tt.firstAuthor
+			if first == "" {
+				first = text
+			}
+			switch firstAuthor {
+			case "":
+				firstAuthor = "ivan"
+			case "-":
+				firstAuthor = ""
+			}
+			idx.CheckAndAdd(NewFingerprint(first), firstAuthor, 1, now)
+			res, _ := idx.CheckAndAdd(NewFingerprint(tt.text), tt.author, 2, now.Add(tt.after))
+			if res.Duplicate != tt.wantDup || res.Action != tt.wantAction {
+				t.Errorf("Результат %+v, ожидается дубликат %v с действием %q", res, tt.wantDup, tt.wantAction)
+			}
+			if res.Duplicate && res.NewsID != 1 {
+				t.Errorf("Неверная новость дубликата: %d", res.NewsID)
+			}
+		})
+	}
+}
+
+func TestCheckAndAdd_distance(t *testing.T) {
+	now := time.Now()
+	fp, near := NewFingerprint(text), NewFingerprint(similar)
+	distance := bits.OnesCount64(fp.SimHash ^ near.SimHash)
+	if distance == 0 || fp.Hash == near.Hash {
+		t.Fatalf("Тексты должны различаться, расстояние %d", distance)
+	}
+
+	tests := []struct {
+		name        string
+		maxDistance int
+		want        bool
+	}{
+		{"Расстояние на пороге", distance, true},
+		{"Расстояние больше порога", distance - 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx := newIndex(t, Config{MaxDistance: tt.maxDistance})
+			idx.CheckAndAdd(fp, "ivan", 1, now)
+			res, _ := idx.CheckAndAdd(near, "petr", 1, now)
+			if res.Duplicate != tt.want {
+				t.Errorf("Дубликат: ожидается %v, получено %v (расстояние %d)", tt.want, res.Duplicate, res.Distance)
+			}
+			if res.Duplicate && (res.Exact || res.Distance != distance) {
+				t.Errorf("Неверное описание дубликата: %+v", res)
+			}
+		})
+	}
+}
+
+func TestCheckAndAdd_sameAuthorPriority(t *testing.T) {
+	now := time.Now()
+	idx := newIndex(t, Config{})
+	idx.CheckAndAdd(NewFingerprint(text), "petr", 1, now)
+	idx.CheckAndAdd(NewFingerprint(text), "ivan", 2, now) // дубликат другого автора, добавляется с пометкой
+
+	res, _ := idx.CheckAndAdd(NewFingerprint(text), "ivan", 3, now)
+	if !res.SameAuthor || res.Action != ActionReject || res.NewsID != 2 {
+		t.Errorf("Совпадение того же автора должно иметь приоритет: %+v", res)
+	}
+}
+
+func TestCheckAndAdd_release(t *testing.T) {
+	now := time.Now()
+	idx := newIndex(t, Config{})
+	_, release := idx.CheckAndAdd(NewFingerprint(text), "ivan", 1, now)
+	// комментарий не сохранен, отпечаток удаляется
+	release()
+
+	if res, _ := idx.CheckAndAdd(NewFingerprint(text), "ivan", 1, now); res.Duplicate {
+		t.Errorf("Отмененный комментарий не должен считаться дубликатом: %+v", res)
+	}
+	if res, _ := idx.CheckAndAdd(NewFingerprint(text), "ivan", 1, now); !res.Duplicate {
+		t.Errorf("Сохраненный комментарий должен считаться дубликатом")
+	}
+}
+
+func TestCheckAndAdd_concurrent(t *testing.T) {
+	const n = 50
+	now := time.Now()
+	idx := newIndex(t, Config{})
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		accepted int
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, _ := idx.CheckAndAdd(NewFingerprint(text), "ivan", 1, now)
+			if !res.Duplicate {
+				mu.Lock()
+				accepted++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if accepted != 1 {
+		t.Errorf("Из %d одновременных одинаковых комментариев принято %d, ожидается 1", n, accepted)
+	}
+	if len(idx.entries) != 1 {
+		t.Errorf("В индексе %d записей, ожидается 1", len(idx.entries))
+	}
+}
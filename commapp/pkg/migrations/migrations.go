@@ -1,25 +1,112 @@
+// Package migrations выполняет миграции схемы базы данных комментариев.
+// Миграции встроены в исполняемый файл (storage.Migrations), поэтому
+// не зависят от рабочего каталога; для каждого драйвера свой набор миграций.
+// Одновременный запуск нескольких реплик безопасен: на время миграции
+// берется рекомендательная блокировка Postgres (SQLite блокирует файл базы сам).
 package migrations
 
 import (
-	"log"
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+
+	"APIGetaway/pkg/storage"
 
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 
-	"github.com/pressly/goose"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
 )
 
-// RunMigrations выполняет миграции базы данных
-func RunMigrations(dbInfo string) {
-	db, err := goose.OpenDBWithDriver("postgres", dbInfo)
+// Migrator выполняет миграции для одной базы данных.
+type Migrator struct {
+	db       *sql.DB
+	provider *goose.Provider
+}
+
+// New подключается к базе данных драйвера storage.DriverPostgres или
+// storage.DriverSQLite и готовит встроенные миграции этого драйвера.
+func New(driver, dsn string) (*Migrator, error) {
+	var (
+		dialect goose.Dialect
+		opts    []goose.ProviderOption
+	)
+	switch driver {
+	case storage.DriverPostgres:
+		locker, err := lock.NewPostgresSessionLocker()
+		if err != nil {
+			return nil, err
+		}
+		dialect = goose.DialectPostgres
+		opts = append(opts, goose.WithSessionLocker(locker))
+	case storage.DriverSQLite:
+		dialect = goose.DialectSQLite3
+	default:
+		return nil, fmt.Errorf("миграции не поддерживаются драйвером %q", driver)
+	}
+
+	fsys, err := fs.Sub(storage.Migrations, "migrations/"+driver)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open(driver, dsn)
 	if err != nil {
-		log.Fatalf("Не удалось подключиться к базе данных: %v", err)
+		return nil, fmt.Errorf("не удалось подключиться к базе данных: %w", err)
 	}
-	defer db.Close() // Закрываем соединение после выполнения миграций
+	provider, err := goose.NewProvider(dialect, db, fsys, opts...)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Migrator{db: db, provider: provider}, nil
+}
+
+// Close закрывает соединение с базой данных.
+func (m *Migrator) Close() error {
+	return m.provider.Close()
+}
+
+// Up применяет все невыполненные миграции.
+func (m *Migrator) Up(ctx context.Context) ([]*goose.MigrationResult, error) {
+	return m.provider.Up(ctx)
+}
+
+// Down откатывает последнюю примененную миграцию.
+func (m *Migrator) Down(ctx context.Context) (*goose.MigrationResult, error) {
+	return m.provider.Down(ctx)
+}
 
-	log.Println("Запуск миграций...")
-	if err := goose.Up(db, "../pkg/storage/migrations"); err != nil {
-		log.Fatalf("Ошибка выполнения миграций: %v", err)
+// Redo откатывает и заново применяет последнюю миграцию.
+func (m *Migrator) Redo(ctx context.Context) ([]*goose.MigrationResult, error) {
+	down, err := m.provider.Down(ctx)
+	if err != nil {
+		return nil, err
 	}
+	up, err := m.provider.ApplyVersion(ctx, down.Source.Version, true)
+	if err != nil {
+		return []*goose.MigrationResult{down}, err
+	}
+	return []*goose.MigrationResult{down, up}, nil
+}
 
-	log.Println("Миграции выполнены успешно.")
+// Status возвращает состояние всех миграций.
+func (m *Migrator) Status(ctx context.Context) ([]*goose.MigrationStatus, error) {
+	return m.provider.Status(ctx)
+}
+
+// Create создает в каталоге dir файл новой SQL-миграции с текущим временем в имени.
+func Create(dir, name string) error {
+	return goose.Create(nil, dir, name, "sql")
+}
+
+// RunMigrations применяет невыполненные миграции при запуске сервиса.
+func RunMigrations(ctx context.Context, driver, dsn string) ([]*goose.MigrationResult, error) {
+	m, err := New(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer m.Close()
+	return m.Up(ctx)
 }
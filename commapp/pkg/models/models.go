@@ -2,10 +2,118 @@ package models
 
 import "time"
 
+// Статусы комментария
+const (
+	StatusPublished = "published" // комментарий опубликован
+	StatusReview    = "review"    // комментарий ожидает ручной проверки
+	StatusHidden    = "hidden"    // комментарий скрыт при повторной модерации
+)
+
 type Comment struct {
 	ID        int64     `json:"id"`
 	NewsID    int64     `json:"news_id"`
 	ParentID  *int64    `json:"parent_id,omitempty"`
+	Author    string    `json:"author,omitempty"`
 	Text      string    `json:"text"`
+	Status    string    `json:"status,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// CommentMatch - комментарий, найденный полнотекстовым поиском.
+type CommentMatch struct {
+	Comment
+	Rank    float64 `json:"rank"`    // релевантность, больше - лучше
+	Snippet string  `json:"snippet"` // фрагмент текста, найденные слова выделены <mark>
+}
+
+// Решения сервиса цензуры
+const (
+	DecisionAllow  = "allow"
+	DecisionDeny   = "deny"
+	DecisionReview = "review"
+)
+
+// Действия сервиса цензуры
+const (
+	ActionReject = "reject" // комментарий с нарушениями отклоняется
+	ActionMask   = "mask"   // нарушения маскируются, сохраняется очищенный текст
+)
+
+// Verdict - решение сервиса цензуры по тексту комментария.
+type Verdict struct {
+	Policy     string   `json:"policy"`
+	Version    string   `json:"dictionary_version"`
+	Language   string   `json:"language"` // преобладающий язык текста
+	Decision   string   `json:"decision"`
+	Score      float64  `json:"score"`
+	Rules      []string `json:"rules"`
+	Categories []string `json:"categories"`
+	Matches    []Match  `json:"matches"`
+	Signals    []Signal `json:"signals"`
+	Action     string   `json:"action"`
+	// SanitizedText - текст с замаскированными нарушениями (для действия mask).
+	SanitizedText string `json:"sanitized_text,omitempty"`
+}
+
+// MaskedText возвращает text с замаскированными нарушениями и true, если политика
+// вердикта маскирует нарушения и маска меняет текст. Маска применяется при любом
+// решении, в том числе allow: слово ниже порога проверки тоже скрывается.
+func (v Verdict) MaskedText(text string) (string, bool) {
+	if v.Action != ActionMask || v.SanitizedText == "" || v.SanitizedText == text {
+		return text, false
+	}
+	return v.SanitizedText, true
+}
+
+// Match - найденный сервисом цензуры недопустимый фрагмент текста.
+type Match struct {
+	RuleID   string `json:"rule_id"`
+	Category string `json:"category"`
+	Fragment string `json:"fragment"`
+	Start    int    `json:"start"` // смещение начала в символах
+	End      int    `json:"end"`   // смещение конца в символах (не включая)
+}
+
+// Signal - сработавшая эвристика сервиса цензуры (ссылки, повторы, капс и т.п.).
+type Signal struct {
+	Detector string  `json:"detector"`
+	Score    float64 `json:"score"`
+	Weight   float64 `json:"weight"`
+	Reason   string  `json:"reason,omitempty"`
+}
+
+// Статусы задания повторной модерации
+const (
+	JobRunning = "running" // задание выполняется
+	JobPaused  = "paused"  // задание приостановлено и может быть продолжено
+	JobDone    = "done"    // все комментарии проверены
+	JobFailed  = "failed"  // задание остановлено из-за ошибки и может быть продолжено
+)
+
+// ModerationJob - задание повторной модерации опубликованных комментариев.
+type ModerationJob struct {
+	ID         int64      `json:"id"`
+	Policy     string     `json:"policy,omitempty"` // политика цензуры, пустая - по умолчанию
+	DryRun     bool       `json:"dry_run"`          // только отчет, без изменения комментариев
+	BatchSize  int        `json:"batch_size"`
+	Status     string     `json:"status"`
+	LastID     int64      `json:"last_id"` // ID последнего проверенного комментария
+	Total      int64      `json:"total"`   // число опубликованных комментариев на момент запуска
+	Scanned    int64      `json:"scanned"` // проверено комментариев
+	Changed    int64      `json:"changed"` // изменено (или было бы изменено) комментариев
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// ModerationChange - изменение комментария по итогам повторной модерации.
+type ModerationChange struct {
+	JobID     int64    `json:"job_id"`
+	CommentID int64    `json:"comment_id"`
+	Decision  string   `json:"decision"`
+	Rules     []string `json:"rules"`
+	OldStatus string   `json:"old_status"`
+	NewStatus string   `json:"new_status"`
+	NewText   *string  `json:"new_text,omitempty"` // замаскированный текст для политики mask
+}
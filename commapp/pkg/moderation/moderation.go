@@ -0,0 +1,300 @@
+// Package moderation выполняет повторную модерацию опубликованных комментариев,
+// например после добавления новых слов в словарь сервиса цензуры.
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"APIGetaway/pkg/censor"
+	"APIGetaway/pkg/models"
+	"APIGetaway/pkg/storage"
+	"APIGetaway/shared/logging"
+)
+
+// Размер пакета комментариев
+const (
+	DefaultBatchSize = 100  // размер пакета по умолчанию
+	MaxBatchSize     = 1000 // не больше batch.max_items сервиса цензуры по умолчанию
+)
+
+// ErrJobActive возвращается при попытке продолжить уже выполняющееся задание.
+var ErrJobActive = errors.New("задание уже выполняется")
+
+// ErrJobFinished возвращается при попытке продолжить завершенное задание.
+var ErrJobFinished = errors.New("задание уже завершено")
+
+// Censor - пакетная проверка текстов сервисом цензуры, ее выполняет censor.Client.
+type Censor interface {
+	CheckBatch(ctx context.Context, items []censor.BatchItem, policy, requestID string) (map[string]models.Verdict, error)
+}
+
+// Runner запускает задания повторной модерации в фоне.
+// Состояние заданий хранится в БД, поэтому прерванное задание
+// продолжается с последней контрольной точки.
+type Runner struct {
+	db     storage.DBInterface
+	censor Censor
+
+	mu      sync.Mutex
+	running map[int64]context.CancelFunc
+}
+
+// NewRunner создает исполнитель заданий.
+func NewRunner(db storage.DBInterface, cc Censor) *Runner {
+	return &Runner{db: db, censor: cc, running: map[int64]context.CancelFunc{}}
+}
+
+// Start создает и запускает новое задание.
+func (r *Runner) Start(ctx context.Context, policy string, dryRun bool, batchSize int) (models.ModerationJob, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	total, err := r.db.CountComments(ctx)
+	if err != nil {
+		return models.ModerationJob{}, err
+	}
+	now := time.Now()
+	job := models.ModerationJob{
+		Policy:    policy,
+		DryRun:    dryRun,
+		BatchSize: batchSize,
+		Status:    models.JobRunning,
+		Total:     total,
+		StartedAt: now,
+		UpdatedAt: now,
+	}
+	job.ID, err = r.db.CreateModerationJob(ctx, job)
+	if err != nil {
+		return models.ModerationJob{}, err
+	}
+	run, _ := r.register(job.ID)
+	r.launch(run, job)
+	return job, nil
+}
+
+// Resume продолжает приостановленное или прерванное ошибкой задание.
+func (r *Runner) Resume(ctx context.Context, id int64) (models.ModerationJob, error) {
+	job, err := r.db.GetModerationJob(ctx, id)
+	if err != nil {
+		return models.ModerationJob{}, err
+	}
+	if job.Status == models.JobDone {
+		return job, ErrJobFinished
+	}
+	// Проверка и регистрация выполняются под одной блокировкой,
+	// поэтому одновременный повтор запроса получает ErrJobActive
+	run, ok := r.register(id)
+	if !ok {
+		return job, ErrJobActive
+	}
+	prev := job
+	job.Status = models.JobRunning
+	job.Error = ""
+	job.UpdatedAt = time.Now()
+	claimed, err := r.db.ClaimModerationJob(ctx, job, prev)
+	if err != nil || !claimed {
+		run.done()
+		if err != nil {
+			return models.ModerationJob{}, err
+		}
+		return prev, ErrJobActive
+	}
+	r.launch(run, job)
+	return job, nil
+}
+
+// ResumeInterrupted продолжает задания, которые выполнялись при остановке сервиса.
+func (r *Runner) ResumeInterrupted(ctx context.Context) error {
+	jobs, err := r.db.ModerationJobsByStatus(ctx, models.JobRunning)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		run, ok := r.register(job.ID)
+		if !ok {
+			continue
+		}
+		// Задание продолжает только тот экземпляр сервиса, чье условное
+		// обновление прошло первым, остальные видят новое время обновления
+		prev := job
+		job.UpdatedAt = time.Now()
+		claimed, err := r.db.ClaimModerationJob(ctx, job, prev)
+		if err != nil || !claimed {
+			run.done()
+			if err != nil {
+				return err
+			}
+			slog.Info("Задание модерации продолжает другой экземпляр", "job_id", job.ID)
+			continue
+		}
+		slog.Info("Продолжение задания модерации", "job_id", job.ID, "last_id", job.LastID)
+		r.launch(run, job)
+	}
+	return nil
+}
+
+// Pause приостанавливает выполняющееся задание после обработки текущего пакета.
+// Возвращает false, если задание не выполняется.
+func (r *Runner) Pause(id int64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cancel, ok := r.running[id]
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+func (r *Runner) isRunning(id int64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.running[id]
+	return ok
+}
+
+// execution - зарегистрированное выполнение задания.
+type execution struct {
+	ctx  context.Context
+	done func() // снимает регистрацию и отменяет ctx
+}
+
+// register регистрирует выполнение задания id. Возвращает false,
+// если задание уже выполняется.
+func (r *Runner) register(id int64) (execution, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.running[id]; ok {
+		return execution{}, false
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.running[id] = cancel
+	return execution{ctx: ctx, done: func() {
+		r.mu.Lock()
+		delete(r.running, id)
+		r.mu.Unlock()
+		cancel()
+	}}, true
+}
+
+// launch запускает обработку зарегистрированного задания в отдельной горутине.
+func (r *Runner) launch(run execution, job models.ModerationJob) {
+	go func() {
+		defer run.done()
+		r.run(run.ctx, job)
+	}()
+}
+
+// run обходит комментарии по возрастанию ID начиная с контрольной точки задания.
+func (r *Runner) run(ctx context.Context, job models.ModerationJob) {
+	// Сохранение состояния не должно прерываться при отмене задания
+	store := context.WithoutCancel(ctx)
+	requestID := "moderation-" + strconv.FormatInt(job.ID, 10)
+	logger := slog.Default().With(logging.KeyRequestID, requestID, "job_id", job.ID)
+
+	for {
+		if ctx.Err() != nil {
+			job.Status = models.JobPaused
+			r.save(store, job)
+			logger.Info("Задание модерации приостановлено", "last_id", job.LastID)
+			return
+		}
+
+		comments, err := r.db.CommentsAfterID(ctx, job.LastID, job.BatchSize)
+		if err == nil && len(comments) == 0 {
+			now := time.Now()
+			job.Status = models.JobDone
+			job.FinishedAt = &now
+			r.save(store, job)
+			logger.Info("Задание модерации завершено", "scanned", job.Scanned, "changed", job.Changed)
+			return
+		}
+
+		var changes []models.ModerationChange
+		if err == nil {
+			changes, err = r.moderate(ctx, job, comments, requestID)
+		}
+		if err == nil {
+			job.LastID = comments[len(comments)-1].ID
+			job.Scanned += int64(len(comments))
+			job.Changed += int64(len(changes))
+			job.UpdatedAt = time.Now()
+			err = r.db.CommitModerationBatch(store, job, changes)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				continue
+			}
+			job.Status = models.JobFailed
+			job.Error = err.Error()
+			r.save(store, job)
+			logger.Error("Задание модерации остановлено из-за ошибки", logging.Err(err))
+			return
+		}
+		logger.Info("Пакет задания модерации обработан",
+			"scanned", job.Scanned, "total", job.Total, "changed", job.Changed)
+	}
+}
+
+// moderate отправляет пакет комментариев в сервис цензуры и возвращает
+// изменения для тех, что больше не проходят проверку.
+func (r *Runner) moderate(ctx context.Context, job models.ModerationJob, comments []models.Comment, requestID string) ([]models.ModerationChange, error) {
+	items := make([]censor.BatchItem, 0, len(comments))
+	for _, c := range comments {
+		items = append(items, censor.BatchItem{ID: strconv.FormatInt(c.ID, 10), Text: c.Text})
+	}
+	verdicts, err := r.censor.CheckBatch(ctx, items, job.Policy, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []models.ModerationChange
+	for _, c := range comments {
+		v, ok := verdicts[strconv.FormatInt(c.ID, 10)]
+		if !ok {
+			return nil, fmt.Errorf("нет вердикта для комментария %d", c.ID)
+		}
+		ch := models.ModerationChange{
+			JobID:     job.ID,
+			CommentID: c.ID,
+			Decision:  v.Decision,
+			Rules:     v.Rules,
+			OldStatus: c.Status,
+		}
+		// Статус и текст выбираются так же, как при публикации комментария:
+		// пограничный случай отправляется на ручную проверку и по политике mask
+		switch {
+		case v.Decision == models.DecisionReview:
+			ch.NewStatus = models.StatusReview
+		case v.Decision == models.DecisionDeny && v.Action != models.ActionMask:
+			ch.NewStatus = models.StatusHidden
+		default:
+			// По политике mask комментарий остается опубликованным в замаскированном виде
+			ch.NewStatus = c.Status
+		}
+		if text, masked := v.MaskedText(c.Text); masked {
+			ch.NewText = &text
+		}
+		if ch.NewText == nil && ch.NewStatus == c.Status {
+			continue
+		}
+		if ch.Rules == nil {
+			ch.Rules = []string{}
+		}
+		changes = append(changes, ch)
+	}
+	return changes, nil
+}
+
+// save сохраняет состояние задания, ошибки только журналируются.
+func (r *Runner) save(ctx context.Context, job models.ModerationJob) {
+	job.UpdatedAt = time.Now()
+	if err := r.db.UpdateModerationJob(ctx, job); err != nil {
+		slog.Error("Не удалось сохранить состояние задания модерации", "job_id", job.ID, logging.Err(err))
+	}
+}
@@ -0,0 +1,362 @@
+package moderation
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"APIGetaway/pkg/censor"
+	"APIGetaway/pkg/models"
+	"APIGetaway/pkg/storage"
+	"APIGetaway/pkg/storage/memdb"
+)
+
+// fakeCensor - сервис цензуры для тестов. Текст со словом qwerty запрещен,
+// со словом maybe - на ручную проверку, остальные тексты допустимы.
+// Слово дурак ниже порога проверки, но по политике mask маскируется.
+type fakeCensor struct {
+	mask bool // политика mask: нарушения заменяются звездочками
+
+	mu      sync.Mutex
+	calls   [][]string    // ID комментариев в каждом вызове
+	block   int           // номер вызова, который ждет отмены задания, 0 - не ждать
+	blocked chan struct{} // закрывается, когда вызов block начался
+}
+
+func (f *fakeCensor) CheckBatch(ctx context.Context, items []censor.BatchItem, policy, requestID string) (map[string]models.Verdict, error) {
+	f.mu.Lock()
+	var ids []string
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	f.calls = append(f.calls, ids)
+	wait := len(f.calls) == f.block
+	f.mu.Unlock()
+
+	if wait {
+		close(f.blocked)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	verdicts := make(map[string]models.Verdict, len(items))
+	for _, item := range items {
+		v := models.Verdict{Decision: models.DecisionAllow, Action: models.ActionReject}
+		switch {
+		case strings.Contains(item.Text, "qwerty"):
+			v.Decision, v.Rules = models.DecisionDeny, []string{"word-qwerty"}
+		case strings.Contains(item.Text, "maybe"):
+			v.Decision = models.DecisionReview
+		}
+		if f.mask {
+			v.Action = models.ActionMask
+			v.SanitizedText = strings.NewReplacer("qwerty", "******", "maybe", "*****", "дурак", "*****").Replace(item.Text)
+		}
+		verdicts[item.ID] = v
+	}
+	return verdicts, nil
+}
+
+// calledIDs возвращает ID комментариев всех вызовов по порядку.
+func (f *fakeCensor) calledIDs() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var ids []string
+	for _, call := range f.calls {
+		ids = append(ids, call...)
+	}
+	return ids
+}
+
+// newDB создает хранилище с комментариями к новости 1.
+func newDB(t *testing.T, texts ...string) *memdb.DB {
+	t.Helper()
+	db := memdb.New()
+	for _, text := range texts {
+		c := models.Comment{NewsID: 1, Text: text, Status: models.StatusPublished, CreatedAt: time.Now()}
+		if _, err := db.AddComment(context.Background(), c); err != nil {
+			t.Fatalf("Ошибка добавления комментария: %v", err)
+		}
+	}
+	return db
+}
+
+// wait ждет завершения обработки задания и возвращает его состояние.
+func wait(t *testing.T, r *Runner, id int64) models.ModerationJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for r.isRunning(id) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Задание %d не завершилось", id)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	job, err := r.db.GetModerationJob(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Ошибка чтения задания: %v", err)
+	}
+	return job
+}
+
+// statuses возвращает тексты комментариев 1-5 по порядку ID, "-" - комментарий снят с публикации.
+func statuses(t *testing.T, db *memdb.DB) []string {
+	t.Helper()
+	comments, err := db.CommentsAfterID(context.Background(), 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	published := map[int64]string{}
+	for _, c := range comments {
+		published[c.ID] = c.Text
+	}
+	var res []string
+	for id := int64(1); id <= 5; id++ {
+		if text, ok := published[id]; ok {
+			res = append(res, text)
+		} else {
+			res = append(res, "-")
+		}
+	}
+	return res
+}
+
+func TestRunner(t *testing.T) {
+	texts := []string{"привет", "qwerty тут", "maybe так", "ок, дурак", "и qwerty"}
+
+	tests := []struct {
+		name        string
+		mask        bool
+		dryRun      bool
+		wantChanged int64
+		wantTexts   []string // опубликованные тексты, "-" - комментарий снят с публикации
+		wantStatus  map[int64]string
+	}{
+		{
+			name:        "Скрытие и ручная проверка",
+			wantChanged: 3,
+			wantTexts:   []string{"привет", "-", "-", "ок, дурак", "-"},
+			wantStatus:  map[int64]string{2: models.StatusHidden, 3: models.StatusReview, 5: models.StatusHidden},
+		},
+		{
+			name:        "Пробный запуск ничего не меняет",
+			dryRun:      true,
+			wantChanged: 3,
+			wantTexts:   texts,
+			wantStatus:  map[int64]string{2: models.StatusHidden, 3: models.StatusReview, 5: models.StatusHidden},
+		},
+		{
+			name:        "Маскирование",
+			mask:        true,
+			wantChanged: 4,
+			wantTexts:   []string{"привет", "****** тут", "-", "ок, *****", "и ******"},
+			// вердикт review по политике mask отправляет комментарий на проверку, как при публикации,
+			// а допустимый комментарий тоже маскируется
+			wantStatus: map[int64]string{2: models.StatusPublished, 3: models.StatusReview, 4: models.StatusPublished, 5: models.StatusPublished},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDB(t, texts...)
+			r := NewRunner(db, &fakeCensor{mask: tt.mask})
+			job, err := r.Start(context.Background(), "", tt.dryRun, 2)
+			if err != nil {
+				t.Fatalf("Ошибка запуска: %v", err)
+			}
+			job = wait(t, r, job.ID)
+
+			if job.Status != models.JobDone || job.Scanned != 5 || job.LastID != 5 || job.Changed != tt.wantChanged {
+				t.Errorf("Неверное состояние задания: %+v", job)
+			}
+			if got := statuses(t, db); strings.Join(got, "|") != strings.Join(tt.wantTexts, "|") {
+				t.Errorf("Комментарии: ожидается %q, получено %q", tt.wantTexts, got)
+			}
+			changes, err := db.GetModerationChanges(context.Background(), job.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(changes) != len(tt.wantStatus) {
+				t.Fatalf("Ожидается %d изменений, получено %+v", len(tt.wantStatus), changes)
+			}
+			for _, ch := range changes {
+				if ch.NewStatus != tt.wantStatus[ch.CommentID] {
+					t.Errorf("Комментарий %d: ожидается статус %q, получен %q", ch.CommentID, tt.wantStatus[ch.CommentID], ch.NewStatus)
+				}
+				if tt.mask && ch.NewText == nil {
+					t.Errorf("Комментарий %d: нет замаскированного текста", ch.CommentID)
+				}
+			}
+		})
+	}
+}
+
+func TestRunner_resumeFromLastID(t *testing.T) {
+	db := newDB(t, "один", "два", "три qwerty", "четыре", "пять")
+	// Задание прервано остановкой сервиса после второго комментария
+	job := models.ModerationJob{BatchSize: 2, Status: models.JobRunning, Total: 5, StartedAt: time.Now()}
+	id, err := db.CreateModerationJob(context.Background(), job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job.ID, job.LastID, job.Scanned = id, 2, 2
+	if err := db.UpdateModerationJob(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+
+	fc := &fakeCensor{}
+	r := NewRunner(db, fc)
+	if err := r.ResumeInterrupted(context.Background()); err != nil {
+		t.Fatalf("Ошибка продолжения: %v", err)
+	}
+	job = wait(t, r, id)
+
+	if got := strings.Join(fc.calledIDs(), ","); got != "3,4,5" {
+		t.Errorf("Проверены комментарии %s, ожидается 3,4,5", got)
+	}
+	if job.Status != models.JobDone || job.Scanned != 5 || job.LastID != 5 || job.Changed != 1 {
+		t.Errorf("Неверное состояние задания: %+v", job)
+	}
+}
+
+func TestRunner_pause(t *testing.T) {
+	db := newDB(t, "qwerty", "два", "три qwerty", "четыре", "пять")
+	fc := &fakeCensor{block: 2, blocked: make(chan struct{})}
+	r := NewRunner(db, fc)
+	job, err := r.Start(context.Background(), "", false, 2)
+	if err != nil {
+		t.Fatalf("Ошибка запуска: %v", err)
+	}
+
+	// Второй пакет ждет ответа сервиса цензуры, задание приостанавливается
+	<-fc.blocked
+	if !r.Pause(job.ID) {
+		t.Fatalf("Задание должно выполняться")
+	}
+	job = wait(t, r, job.ID)
+	if job.Status != models.JobPaused || job.LastID != 2 || job.Scanned != 2 || job.Changed != 1 {
+		t.Errorf("Неверное состояние приостановленного задания: %+v", job)
+	}
+	if got := statuses(t, db); got[2] != "три qwerty" {
+		t.Errorf("Комментарий прерванного пакета изменен: %q", got)
+	}
+	if r.Pause(job.ID) {
+		t.Errorf("Приостановленное задание не выполняется")
+	}
+
+	// Продолжение начинается с прерванного пакета
+	if _, err := r.Resume(context.Background(), job.ID); err != nil {
+		t.Fatalf("Ошибка продолжения: %v", err)
+	}
+	job = wait(t, r, job.ID)
+	if got := strings.Join(fc.calledIDs(), ","); got != "1,2,3,4,3,4,5" {
+		t.Errorf("Проверены комментарии %s, ожидается 1,2,3,4,3,4,5", got)
+	}
+	if job.Status != models.JobDone || job.Scanned != 5 || job.Changed != 2 {
+		t.Errorf("Неверное состояние задания: %+v", job)
+	}
+	if got := strings.Join(statuses(t, db), "|"); got != "-|два|-|четыре|пять" {
+		t.Errorf("Неверные комментарии после продолжения: %s", got)
+	}
+	if _, err := r.Resume(context.Background(), job.ID); err != ErrJobFinished {
+		t.Errorf("Ожидается ErrJobFinished, получено %v", err)
+	}
+}
+
+func TestRunner_Resume_unknown(t *testing.T) {
+	r := NewRunner(newDB(t), &fakeCensor{})
+	if _, err := r.Resume(context.Background(), 100); err != storage.ErrNotFound {
+		t.Errorf("Ожидается ErrNotFound, получено %v", err)
+	}
+}
+
+func TestRunner_Resume_concurrent(t *testing.T) {
+	db := newDB(t, "один", "два", "три")
+	id, err := db.CreateModerationJob(context.Background(), models.ModerationJob{
+		BatchSize: 1, Status: models.JobPaused, Total: 3, StartedAt: time.Now(), UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fc := &fakeCensor{}
+	r := NewRunner(db, fc)
+
+	// Из одновременных запросов задание продолжает только один
+	const n = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := r.Resume(context.Background(), id)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	resumed := 0
+	for err := range errs {
+		switch err {
+		case nil:
+			resumed++
+		case ErrJobActive, ErrJobFinished:
+		default:
+			t.Errorf("Ошибка продолжения: %v", err)
+		}
+	}
+	if resumed != 1 {
+		t.Errorf("Задание продолжено %d раз, ожидается 1", resumed)
+	}
+	wait(t, r, id)
+	if got := strings.Join(fc.calledIDs(), ","); got != "1,2,3" {
+		t.Errorf("Проверены комментарии %s, ожидается 1,2,3", got)
+	}
+}
+
+// barrierDB - хранилище, в котором ModerationJobsByStatus возвращает результат,
+// только когда его прочитали все исполнители: так они видят одно и то же состояние.
+type barrierDB struct {
+	*memdb.DB
+	read *sync.WaitGroup
+}
+
+func (db barrierDB) ModerationJobsByStatus(ctx context.Context, status string) ([]models.ModerationJob, error) {
+	jobs, err := db.DB.ModerationJobsByStatus(ctx, status)
+	db.read.Done()
+	db.read.Wait()
+	return jobs, err
+}
+
+func TestRunner_ResumeInterrupted_claim(t *testing.T) {
+	db := newDB(t, "один", "два", "три qwerty")
+	id, err := db.CreateModerationJob(context.Background(), models.ModerationJob{
+		BatchSize: 1, Status: models.JobRunning, Total: 3, StartedAt: time.Now(), UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Два экземпляра сервиса запускаются одновременно с общей БД
+	var read sync.WaitGroup
+	read.Add(2)
+	fc := &fakeCensor{}
+	runners := []*Runner{NewRunner(barrierDB{db, &read}, fc), NewRunner(barrierDB{db, &read}, fc)}
+	var wg sync.WaitGroup
+	for _, r := range runners {
+		wg.Add(1)
+		go func(r *Runner) {
+			defer wg.Done()
+			if err := r.ResumeInterrupted(context.Background()); err != nil {
+				t.Errorf("Ошибка продолжения: %v", err)
+			}
+		}(r)
+	}
+	wg.Wait()
+	for _, r := range runners {
+		wait(t, r, id)
+	}
+
+	if got := strings.Join(fc.calledIDs(), ","); got != "1,2,3" {
+		t.Errorf("Проверены комментарии %s, ожидается 1,2,3 одним экземпляром", got)
+	}
+}
@@ -0,0 +1,9 @@
+package storage
+
+import "embed"
+
+// Migrations - SQL-миграции схемы базы данных, встроенные в исполняемый файл.
+// Миграции каждого драйвера лежат в каталоге migrations/<драйвер>.
+//
+//go:embed migrations/postgres/*.sql migrations/sqlite/*.sql
+var Migrations embed.FS
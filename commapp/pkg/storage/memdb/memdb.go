@@ -0,0 +1,303 @@
+// Package memdb - хранилище комментариев в памяти для тестов и локальной разработки.
+// Поведение совпадает с хранилищем Postgres: автоинкрементные ID, порядок выдачи,
+// значения по умолчанию и точность времени, что проверяет пакет storagetest.
+package memdb
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"APIGetaway/pkg/models"
+	"APIGetaway/pkg/storage"
+)
+
+// DB - хранилище в памяти.
+type DB struct {
+	mu       sync.Mutex
+	comments []models.Comment // в порядке возрастания ID
+	jobs     map[int64]models.ModerationJob
+	changes  map[int64][]models.ModerationChange // изменения по ID задания
+	lastID   struct{ comment, job int64 }
+}
+
+var _ storage.DBInterface = (*DB)(nil)
+
+// New создает пустое хранилище.
+func New() *DB {
+	return &DB{
+		jobs:    make(map[int64]models.ModerationJob),
+		changes: make(map[int64][]models.ModerationChange),
+	}
+}
+
+// AddComment добавляет комментарий и возвращает его ID.
+func (db *DB) AddComment(ctx context.Context, comment models.Comment) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if comment.Status == "" {
+		comment.Status = models.StatusPublished
+	}
+	db.lastID.comment++
+	comment.ID = db.lastID.comment
+	comment.ParentID = copyID(comment.ParentID)
+	comment.CreatedAt = storage.Timestamp(comment.CreatedAt)
+	db.comments = append(db.comments, comment)
+	return comment.ID, nil
+}
+
+// GetCommentsByNewsID возвращает опубликованные комментарии к новости в порядке возрастания ID.
+func (db *DB) GetCommentsByNewsID(ctx context.Context, newsID int64) ([]models.Comment, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var comments []models.Comment
+	for _, c := range db.comments {
+		if c.NewsID == newsID && c.Status == models.StatusPublished {
+			comments = append(comments, cloneComment(c))
+		}
+	}
+	return comments, nil
+}
+
+// CommentsAfterID возвращает до limit опубликованных комментариев с ID больше afterID.
+func (db *DB) CommentsAfterID(ctx context.Context, afterID int64, limit int) ([]models.Comment, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var comments []models.Comment
+	for _, c := range db.comments {
+		if len(comments) == limit {
+			break
+		}
+		if c.ID > afterID && c.Status == models.StatusPublished {
+			comments = append(comments, cloneComment(c))
+		}
+	}
+	return comments, nil
+}
+
+// CountComments возвращает число опубликованных комментариев.
+func (db *DB) CountComments(ctx context.Context) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var n int64
+	for _, c := range db.comments {
+		if c.Status == models.StatusPublished {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// SearchComments ищет опубликованные комментарии, в которых каждое слово запроса
+// является началом какого-либо слова текста. Релевантность - доля найденных слов в тексте.
+func (db *DB) SearchComments(ctx context.Context, q storage.SearchQuery) ([]models.CommentMatch, int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	terms := storage.SearchTerms(q.Text)
+	var found []models.CommentMatch
+	for _, c := range db.comments {
+		if c.Status != models.StatusPublished || (q.NewsID != 0 && c.NewsID != q.NewsID) || !storage.MatchesAll(c.Text, terms) {
+			continue
+		}
+		snippet, hits := storage.Highlight(c.Text, terms)
+		found = append(found, models.CommentMatch{
+			Comment: cloneComment(c),
+			Rank:    float64(hits) / float64(len(storage.SearchTerms(c.Text))),
+			Snippet: snippet,
+		})
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Rank != found[j].Rank {
+			return found[i].Rank > found[j].Rank
+		}
+		return found[i].ID > found[j].ID
+	})
+
+	total := int64(len(found))
+	if q.Offset >= len(found) {
+		return nil, total, nil
+	}
+	return found[q.Offset:min(q.Offset+q.Limit, len(found))], total, nil
+}
+
+// CreateModerationJob создает задание повторной модерации.
+// Как и в Postgres, сохраняются только параметры запуска, счетчики начинаются с нуля.
+func (db *DB) CreateModerationJob(ctx context.Context, job models.ModerationJob) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.lastID.job++
+	db.jobs[db.lastID.job] = models.ModerationJob{
+		ID:        db.lastID.job,
+		Policy:    job.Policy,
+		DryRun:    job.DryRun,
+		BatchSize: job.BatchSize,
+		Status:    job.Status,
+		Total:     job.Total,
+		StartedAt: storage.Timestamp(job.StartedAt),
+		UpdatedAt: storage.Timestamp(job.UpdatedAt),
+	}
+	return db.lastID.job, nil
+}
+
+// GetModerationJob возвращает задание повторной модерации.
+func (db *DB) GetModerationJob(ctx context.Context, id int64) (models.ModerationJob, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	job, ok := db.jobs[id]
+	if !ok {
+		return models.ModerationJob{}, storage.ErrNotFound
+	}
+	return cloneJob(job), nil
+}
+
+// ModerationJobsByStatus возвращает задания с указанным статусом в порядке возрастания ID.
+func (db *DB) ModerationJobsByStatus(ctx context.Context, status string) ([]models.ModerationJob, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var jobs []models.ModerationJob
+	for _, job := range db.jobs {
+		if job.Status == status {
+			jobs = append(jobs, cloneJob(job))
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+	return jobs, nil
+}
+
+// UpdateModerationJob сохраняет состояние задания повторной модерации.
+func (db *DB) UpdateModerationJob(ctx context.Context, job models.ModerationJob) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.updateJob(job)
+	return nil
+}
+
+// ClaimModerationJob сохраняет состояние задания, если его статус и время
+// обновления не изменились с момента чтения prev.
+func (db *DB) ClaimModerationJob(ctx context.Context, job, prev models.ModerationJob) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, ok := db.jobs[job.ID]
+	if !ok || stored.Status != prev.Status || !stored.UpdatedAt.Equal(storage.Timestamp(prev.UpdatedAt)) {
+		return false, nil
+	}
+	db.updateJob(job)
+	return true, nil
+}
+
+// CommitModerationBatch сохраняет изменения пакета и контрольную точку задания.
+// Повторная запись изменения того же комментария в отчет задания игнорируется.
+func (db *DB) CommitModerationBatch(ctx context.Context, job models.ModerationJob, changes []models.ModerationChange) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, ch := range changes {
+		ch.JobID = job.ID
+		ch.Rules = append([]string{}, ch.Rules...)
+		ch.NewText = copyText(ch.NewText)
+		if !db.hasChange(job.ID, ch.CommentID) {
+			db.changes[job.ID] = append(db.changes[job.ID], ch)
+		}
+		if job.DryRun {
+			continue
+		}
+		for i := range db.comments {
+			if db.comments[i].ID != ch.CommentID {
+				continue
+			}
+			db.comments[i].Status = ch.NewStatus
+			if ch.NewText != nil {
+				db.comments[i].Text = *ch.NewText
+			}
+		}
+	}
+	db.updateJob(job)
+	return nil
+}
+
+// GetModerationChanges возвращает изменения задания в порядке возрастания ID комментария.
+func (db *DB) GetModerationChanges(ctx context.Context, jobID int64) ([]models.ModerationChange, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var changes []models.ModerationChange
+	for _, ch := range db.changes[jobID] {
+		ch.Rules = append([]string{}, ch.Rules...)
+		ch.NewText = copyText(ch.NewText)
+		changes = append(changes, ch)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].CommentID < changes[j].CommentID })
+	return changes, nil
+}
+
+// Close ничего не делает: хранилищу в памяти не нужно освобождать ресурсы.
+func (db *DB) Close() {}
+
+// updateJob обновляет изменяемые поля задания, если оно существует.
+func (db *DB) updateJob(job models.ModerationJob) {
+	stored, ok := db.jobs[job.ID]
+	if !ok {
+		return
+	}
+	stored.Status = job.Status
+	stored.LastID = job.LastID
+	stored.Total = job.Total
+	stored.Scanned = job.Scanned
+	stored.Changed = job.Changed
+	stored.Error = job.Error
+	stored.UpdatedAt = storage.Timestamp(job.UpdatedAt)
+	stored.FinishedAt = nil
+	if job.FinishedAt != nil {
+		t := storage.Timestamp(*job.FinishedAt)
+		stored.FinishedAt = &t
+	}
+	db.jobs[job.ID] = stored
+}
+
+func (db *DB) hasChange(jobID, commentID int64) bool {
+	for _, ch := range db.changes[jobID] {
+		if ch.CommentID == commentID {
+			return true
+		}
+	}
+	return false
+}
+
+func cloneComment(c models.Comment) models.Comment {
+	c.ParentID = copyID(c.ParentID)
+	return c
+}
+
+func cloneJob(job models.ModerationJob) models.ModerationJob {
+	if job.FinishedAt != nil {
+		t := *job.FinishedAt
+		job.FinishedAt = &t
+	}
+	return job
+}
+
+func copyID(id *int64) *int64 {
+	if id == nil {
+		return nil
+	}
+	v := *id
+	return &v
+}
+
+func copyText(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := *s
+	return &v
+}
@@ -0,0 +1,14 @@
+package memdb
+
+import (
+	"testing"
+
+	"APIGetaway/pkg/storage"
+	"APIGetaway/pkg/storage/storagetest"
+)
+
+func TestDB(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.DBInterface {
+		return New()
+	})
+}
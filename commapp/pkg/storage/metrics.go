@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Описания метрик пула соединений Postgres
+var (
+	poolAcquiredDesc = prometheus.NewDesc("pgxpool_acquired_conns",
+		"Число соединений, занятых запросами.", nil, nil)
+	poolIdleDesc = prometheus.NewDesc("pgxpool_idle_conns",
+		"Число свободных соединений.", nil, nil)
+	poolConstructingDesc = prometheus.NewDesc("pgxpool_constructing_conns",
+		"Число устанавливаемых соединений.", nil, nil)
+	poolTotalDesc = prometheus.NewDesc("pgxpool_total_conns",
+		"Общее число соединений пула.", nil, nil)
+	poolMaxDesc = prometheus.NewDesc("pgxpool_max_conns",
+		"Наибольшее число соединений пула.", nil, nil)
+	poolAcquireDesc = prometheus.NewDesc("pgxpool_acquire_total",
+		"Число успешных получений соединения из пула.", nil, nil)
+	poolAcquireDurationDesc = prometheus.NewDesc("pgxpool_acquire_duration_seconds_total",
+		"Суммарное время ожидания соединения при успешных получениях.", nil, nil)
+	poolEmptyAcquireDesc = prometheus.NewDesc("pgxpool_empty_acquire_total",
+		"Число получений соединения, которым пришлось ждать, потому что свободных не было.", nil, nil)
+	poolCanceledAcquireDesc = prometheus.NewDesc("pgxpool_canceled_acquire_total",
+		"Число получений соединения, отмененных контекстом.", nil, nil)
+)
+
+// poolCollector отдает статистику пула соединений в момент чтения метрик.
+type poolCollector struct {
+	pool *pgxpool.Pool
+}
+
+// NewPoolCollector возвращает метрики пула соединений pool.
+func NewPoolCollector(pool *pgxpool.Pool) prometheus.Collector {
+	return &poolCollector{pool: pool}
+}
+
+// Collector возвращает метрики пула соединений БД.
+func (db *DB) Collector() prometheus.Collector {
+	return NewPoolCollector(db.pool)
+}
+
+// Describe реализует prometheus.Collector.
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolAcquiredDesc
+	ch <- poolIdleDesc
+	ch <- poolConstructingDesc
+	ch <- poolTotalDesc
+	ch <- poolMaxDesc
+	ch <- poolAcquireDesc
+	ch <- poolAcquireDurationDesc
+	ch <- poolEmptyAcquireDesc
+	ch <- poolCanceledAcquireDesc
+}
+
+// Collect реализует prometheus.Collector.
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(poolAcquiredDesc, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(poolIdleDesc, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(poolConstructingDesc, prometheus.GaugeValue, float64(s.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(poolTotalDesc, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(poolMaxDesc, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquireDesc, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolAcquireDurationDesc, prometheus.CounterValue, s.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(poolEmptyAcquireDesc, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolCanceledAcquireDesc, prometheus.CounterValue, float64(s.CanceledAcquireCount()))
+}
@@ -0,0 +1,42 @@
+package storage_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"APIGetaway/pkg/storage"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPoolCollector(t *testing.T) {
+	// Пул без подключения к БД: соединения устанавливаются при первом запросе
+	cfg, err := pgxpool.ParseConfig("host=localhost dbname=comments pool_max_conns=4")
+	if err != nil {
+		t.Fatalf("Ошибка разбора строки подключения: %v", err)
+	}
+	cfg.LazyConnect = true
+	pool, err := pgxpool.ConnectConfig(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Ошибка создания пула: %v", err)
+	}
+	defer pool.Close()
+
+	want := `
+# HELP pgxpool_max_conns Наибольшее число соединений пула.
+# TYPE pgxpool_max_conns gauge
+pgxpool_max_conns 4
+# HELP pgxpool_total_conns Общее число соединений пула.
+# TYPE pgxpool_total_conns gauge
+pgxpool_total_conns 0
+`
+	c := storage.NewPoolCollector(pool)
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "pgxpool_max_conns", "pgxpool_total_conns"); err != nil {
+		t.Errorf("Неверные метрики пула: %v", err)
+	}
+	if got := testutil.CollectAndCount(c); got != 9 {
+		t.Errorf("Число метрик пула: ожидается 9, получено %d", got)
+	}
+}
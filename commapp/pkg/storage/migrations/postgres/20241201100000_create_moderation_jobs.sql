@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS moderation_jobs (
+		id SERIAL PRIMARY KEY,
+		policy TEXT NOT NULL DEFAULT '',
+		dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+		batch_size INTEGER NOT NULL,
+		status TEXT NOT NULL,
+		last_id BIGINT NOT NULL DEFAULT 0,
+		total BIGINT NOT NULL DEFAULT 0,
+		scanned BIGINT NOT NULL DEFAULT 0,
+		changed BIGINT NOT NULL DEFAULT 0,
+		error TEXT NOT NULL DEFAULT '',
+		started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		finished_at TIMESTAMP
+	);
+
+CREATE TABLE IF NOT EXISTS moderation_job_changes (
+		job_id INTEGER NOT NULL REFERENCES moderation_jobs(id) ON DELETE CASCADE,
+		comment_id INTEGER NOT NULL,
+		decision TEXT NOT NULL,
+		rules TEXT[] NOT NULL DEFAULT '{}',
+		old_status TEXT NOT NULL,
+		new_status TEXT NOT NULL,
+		new_text TEXT,
+		PRIMARY KEY (job_id, comment_id)
+	);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS moderation_job_changes;
+DROP TABLE IF EXISTS moderation_jobs;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Словоформы текста в русской и английской конфигурациях для полнотекстового поиска
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS search_vector tsvector
+	GENERATED ALWAYS AS (to_tsvector('russian', text) || to_tsvector('english', text)) STORED;
+CREATE INDEX IF NOT EXISTS comments_search_vector_idx ON comments USING GIN (search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS comments_search_vector_idx;
+ALTER TABLE comments DROP COLUMN IF EXISTS search_vector;
+-- +goose StatementEnd
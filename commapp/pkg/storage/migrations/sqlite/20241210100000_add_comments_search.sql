@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Полнотекстовый индекс FTS5 над comments.text. Стемминг porter работает только
+-- для английского, поэтому слова запроса ищутся как начала слов текста.
+CREATE VIRTUAL TABLE IF NOT EXISTS comments_fts USING fts5(
+		text,
+		content = 'comments',
+		content_rowid = 'id',
+		tokenize = 'porter unicode61 remove_diacritics 2'
+	);
+
+CREATE TRIGGER IF NOT EXISTS comments_fts_insert AFTER INSERT ON comments BEGIN
+	INSERT INTO comments_fts (rowid, text) VALUES (new.id, new.text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS comments_fts_delete AFTER DELETE ON comments BEGIN
+	INSERT INTO comments_fts (comments_fts, rowid, text) VALUES ('delete', old.id, old.text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS comments_fts_update AFTER UPDATE OF text ON comments BEGIN
+	INSERT INTO comments_fts (comments_fts, rowid, text) VALUES ('delete', old.id, old.text);
+	INSERT INTO comments_fts (rowid, text) VALUES (new.id, new.text);
+END;
+
+INSERT INTO comments_fts (comments_fts) VALUES ('rebuild');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS comments_fts_update;
+DROP TRIGGER IF EXISTS comments_fts_delete;
+DROP TRIGGER IF EXISTS comments_fts_insert;
+DROP TABLE IF EXISTS comments_fts;
+-- +goose StatementEnd
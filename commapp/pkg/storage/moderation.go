@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"APIGetaway/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+const moderationJobColumns = `id, policy, dry_run, batch_size, status, last_id, total, scanned, changed,
+			  error, started_at, updated_at, finished_at`
+
+// Реализация метода для создания задания повторной модерации
+func (db *DB) CreateModerationJob(ctx context.Context, job models.ModerationJob) (int64, error) {
+	var id int64
+	query := `INSERT INTO moderation_jobs (policy, dry_run, batch_size, status, total, started_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	err := db.pool.QueryRow(ctx, query, job.Policy, job.DryRun, job.BatchSize, job.Status, job.Total,
+		job.StartedAt, job.UpdatedAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания задания модерации: %w", err)
+	}
+	return id, nil
+}
+
+// Реализация метода для получения задания повторной модерации
+func (db *DB) GetModerationJob(ctx context.Context, id int64) (models.ModerationJob, error) {
+	row := db.pool.QueryRow(ctx, `SELECT `+moderationJobColumns+` FROM moderation_jobs WHERE id = $1`, id)
+	job, err := scanModerationJob(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ModerationJob{}, ErrNotFound
+	}
+	if err != nil {
+		return models.ModerationJob{}, fmt.Errorf("ошибка получения задания модерации: %w", err)
+	}
+	return job, nil
+}
+
+// Реализация метода для получения заданий повторной модерации с указанным статусом
+func (db *DB) ModerationJobsByStatus(ctx context.Context, status string) ([]models.ModerationJob, error) {
+	rows, err := db.pool.Query(ctx, `SELECT `+moderationJobColumns+` FROM moderation_jobs WHERE status = $1 ORDER BY id`, status)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения заданий модерации: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []models.ModerationJob
+	for rows.Next() {
+		job, err := scanModerationJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка обработки задания модерации: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// Реализация метода для обновления состояния задания повторной модерации
+func (db *DB) UpdateModerationJob(ctx context.Context, job models.ModerationJob) error {
+	return updateModerationJob(ctx, db.pool, job)
+}
+
+// Реализация метода для условного обновления задания повторной модерации
+func (db *DB) ClaimModerationJob(ctx context.Context, job, prev models.ModerationJob) (bool, error) {
+	tag, err := db.pool.Exec(ctx, updateModerationJobSQL+` AND status = $10 AND updated_at = $11`,
+		job.ID, job.Status, job.LastID, job.Total, job.Scanned, job.Changed, job.Error, job.UpdatedAt, job.FinishedAt,
+		prev.Status, prev.UpdatedAt)
+	if err != nil {
+		return false, fmt.Errorf("ошибка обновления задания модерации: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Реализация метода для сохранения результатов пакета повторной модерации
+func (db *DB) CommitModerationBatch(ctx context.Context, job models.ModerationJob, changes []models.ModerationChange) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, ch := range changes {
+		_, err := tx.Exec(ctx, `INSERT INTO moderation_job_changes
+			  (job_id, comment_id, decision, rules, old_status, new_status, new_text)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (job_id, comment_id) DO NOTHING`,
+			job.ID, ch.CommentID, ch.Decision, ch.Rules, ch.OldStatus, ch.NewStatus, ch.NewText)
+		if err != nil {
+			return fmt.Errorf("ошибка сохранения изменения: %w", err)
+		}
+		if job.DryRun {
+			continue
+		}
+		_, err = tx.Exec(ctx, `UPDATE comments SET status = $2, text = COALESCE($3, text) WHERE id = $1`,
+			ch.CommentID, ch.NewStatus, ch.NewText)
+		if err != nil {
+			return fmt.Errorf("ошибка изменения комментария: %w", err)
+		}
+	}
+	if err := updateModerationJob(ctx, tx, job); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Реализация метода для получения изменений задания повторной модерации
+func (db *DB) GetModerationChanges(ctx context.Context, jobID int64) ([]models.ModerationChange, error) {
+	query := `SELECT job_id, comment_id, decision, rules, old_status, new_status, new_text
+			  FROM moderation_job_changes WHERE job_id = $1 ORDER BY comment_id`
+	rows, err := db.pool.Query(ctx, query, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения изменений: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []models.ModerationChange
+	for rows.Next() {
+		var ch models.ModerationChange
+		err := rows.Scan(&ch.JobID, &ch.CommentID, &ch.Decision, &ch.Rules, &ch.OldStatus, &ch.NewStatus, &ch.NewText)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка обработки изменения: %w", err)
+		}
+		changes = append(changes, ch)
+	}
+	return changes, rows.Err()
+}
+
+// execer - общий интерфейс пула соединений и транзакции.
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+}
+
+const updateModerationJobSQL = `UPDATE moderation_jobs SET status = $2, last_id = $3, total = $4, scanned = $5,
+			  changed = $6, error = $7, updated_at = $8, finished_at = $9 WHERE id = $1`
+
+func updateModerationJob(ctx context.Context, db execer, job models.ModerationJob) error {
+	_, err := db.Exec(ctx, updateModerationJobSQL,
+		job.ID, job.Status, job.LastID, job.Total, job.Scanned, job.Changed, job.Error, job.UpdatedAt, job.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления задания модерации: %w", err)
+	}
+	return nil
+}
+
+func scanModerationJob(row pgx.Row) (models.ModerationJob, error) {
+	var job models.ModerationJob
+	err := row.Scan(&job.ID, &job.Policy, &job.DryRun, &job.BatchSize, &job.Status, &job.LastID, &job.Total,
+		&job.Scanned, &job.Changed, &job.Error, &job.StartedAt, &job.UpdatedAt, &job.FinishedAt)
+	return job, err
+}
@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"APIGetaway/pkg/models"
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+)
+
+// SearchQuery - параметры полнотекстового поиска комментариев.
+type SearchQuery struct {
+	Text   string // поисковый запрос, все слова должны встретиться в комментарии
+	NewsID int64  // новость, 0 - по всем новостям
+	Limit  int
+	Offset int
+}
+
+// Метки начала и конца найденного слова во фрагменте, который возвращает база.
+// Управляющие символы не встречаются в тексте комментариев, поэтому после
+// экранирования HTML их можно однозначно заменить на <mark> и </mark>.
+const (
+	markStart = "\x01"
+	markEnd   = "\x02"
+)
+
+// Snippet экранирует фрагмент текста для HTML и заменяет метки найденных слов на <mark>.
+func Snippet(marked string) string {
+	return strings.NewReplacer(markStart, "<mark>", markEnd, "</mark>").Replace(html.EscapeString(marked))
+}
+
+// SearchTerms разбивает запрос на слова в нижнем регистре. Знаки препинания
+// и операторы поиска отбрасываются.
+func SearchTerms(q string) []string {
+	return strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// snippetWords - наибольшее число слов во фрагменте текста.
+const snippetWords = 30
+
+// Highlight выделяет в тексте слова, которые начинаются с одного из terms,
+// и возвращает фрагмент текста вокруг первого из них в формате Snippet
+// и число выделенных слов. Используется хранилищами без собственного поиска.
+func Highlight(text string, terms []string) (string, int) {
+	fields := strings.Fields(text)
+	hits, first := 0, -1
+	for i, f := range fields {
+		marked, n := markTerms(f, terms)
+		if n > 0 && first < 0 {
+			first = i
+		}
+		fields[i] = marked
+		hits += n
+	}
+
+	start := max(first-snippetWords/3, 0)
+	end := min(start+snippetWords, len(fields))
+	snippet := strings.Join(fields[start:end], " ")
+	if start > 0 {
+		snippet = "… " + snippet
+	}
+	if end < len(fields) {
+		snippet += " …"
+	}
+	return Snippet(snippet), hits
+}
+
+// markTerms выделяет метками слова s, которые начинаются с одного из terms.
+func markTerms(s string, terms []string) (string, int) {
+	var sb strings.Builder
+	hits := 0
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			sb.WriteRune(runes[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && isWordRune(runes[j]) {
+			j++
+		}
+		word := string(runes[i:j])
+		if hasTermPrefix(strings.ToLower(word), terms) {
+			sb.WriteString(markStart + word + markEnd)
+			hits++
+		} else {
+			sb.WriteString(word)
+		}
+		i = j
+	}
+	return sb.String(), hits
+}
+
+// MatchesAll сообщает, начинается ли с каждого из terms хотя бы одно слово текста.
+func MatchesAll(text string, terms []string) bool {
+	words := SearchTerms(text)
+	for _, term := range terms {
+		found := false
+		for _, w := range words {
+			if strings.HasPrefix(w, term) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return len(terms) > 0
+}
+
+func hasTermPrefix(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// searchHeadline - параметры фрагмента ts_headline с метками найденных слов.
+var searchHeadline = fmt.Sprintf("StartSel=%s, StopSel=%s, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=\" … \"",
+	markStart, markEnd)
+
+// searchTSQuery возвращает выражение tsquery для слов запроса с параметрами
+// начиная с $first. Столбец search_vector объединяет словоформы русской
+// и английской конфигураций, поэтому каждое слово разбирается обеими
+// и должно совпасть хотя бы с одной из них, а слова соединяются через И,
+// как в MatchesAll. Слова ищутся по префиксу.
+func searchTSQuery(terms []string, first int) (string, []any) {
+	parts := make([]string, len(terms))
+	args := make([]any, len(terms))
+	for i, term := range terms {
+		n := first + i
+		parts[i] = fmt.Sprintf("(to_tsquery('russian', $%d || ':*') || to_tsquery('english', $%d || ':*'))", n, n)
+		args[i] = term
+	}
+	return strings.Join(parts, " && "), args
+}
+
+// Реализация метода для полнотекстового поиска опубликованных комментариев.
+func (db *DB) SearchComments(ctx context.Context, q SearchQuery) ([]models.CommentMatch, int64, error) {
+	terms := SearchTerms(q.Text)
+	if len(terms) == 0 {
+		return nil, 0, nil
+	}
+	tsquery, termArgs := searchTSQuery(terms, 5)
+	query := `WITH q AS (SELECT ` + tsquery + ` AS query)
+			  SELECT c.id, c.news_id, c.parent_id, c.author, c.text, c.status, c.created_at,
+			         ts_rank(c.search_vector, q.query), ts_headline('russian', c.text, q.query, $2),
+			         count(*) OVER ()
+			  FROM comments c, q
+			  WHERE c.search_vector @@ q.query AND c.status = 'published' AND ($1 = 0 OR c.news_id = $1)
+			  ORDER BY 8 DESC, c.id DESC LIMIT $3 OFFSET $4`
+	args := append([]any{q.NewsID, searchHeadline, q.Limit, q.Offset}, termArgs...)
+	rows, err := db.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка поиска комментариев: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		matches []models.CommentMatch
+		total   int64
+	)
+	for rows.Next() {
+		var m models.CommentMatch
+		var rank float32
+		err := rows.Scan(&m.ID, &m.NewsID, &m.ParentID, &m.Author, &m.Text, &m.Status, &m.CreatedAt,
+			&rank, &m.Snippet, &total)
+		if err != nil {
+			return nil, 0, fmt.Errorf("ошибка обработки комментария: %w", err)
+		}
+		m.Rank = float64(rank)
+		m.Snippet = Snippet(m.Snippet)
+		matches = append(matches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("ошибка поиска комментариев: %w", err)
+	}
+	if len(matches) == 0 && q.Offset > 0 {
+		// Страница за концом выдачи: общее число считаем отдельно
+		tsquery, termArgs := searchTSQuery(terms, 2)
+		err := db.pool.QueryRow(ctx, `SELECT count(*) FROM comments
+			  WHERE search_vector @@ (`+tsquery+`)
+			  AND status = 'published' AND ($1 = 0 OR news_id = $1)`, append([]any{q.NewsID}, termArgs...)...).Scan(&total)
+		if err != nil {
+			return nil, 0, fmt.Errorf("ошибка подсчета комментариев: %w", err)
+		}
+	}
+	return matches, total, nil
+}
@@ -0,0 +1,316 @@
+// Package sqlite - хранилище комментариев в файле SQLite (драйвер на чистом Go).
+// Схема создается миграциями из storage.Migrations (каталог migrations/sqlite).
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"APIGetaway/pkg/models"
+	"APIGetaway/pkg/storage"
+
+	_ "modernc.org/sqlite"
+)
+
+const moderationJobColumns = `id, policy, dry_run, batch_size, status, last_id, total, scanned, changed,
+			  error, started_at, updated_at, finished_at`
+
+// DB - хранилище SQLite.
+type DB struct {
+	db *sql.DB
+}
+
+var _ storage.DBInterface = (*DB)(nil)
+
+// New открывает базу данных по строке подключения storage.DBConfig.DSN.
+func New(dsn string) (*DB, error) {
+	db, err := sql.Open(storage.DriverSQLite, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия БД: %w", err)
+	}
+	// SQLite допускает одного писателя, единственное соединение
+	// исключает ошибки блокировки при одновременных транзакциях
+	db.SetMaxOpenConns(1)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ошибка открытия БД: %w", err)
+	}
+	return &DB{db: db}, nil
+}
+
+// Реализация метода для добавления комментария
+func (db *DB) AddComment(ctx context.Context, comment models.Comment) (int64, error) {
+	if comment.Status == "" {
+		comment.Status = models.StatusPublished
+	}
+	res, err := db.db.ExecContext(ctx, `INSERT INTO comments (news_id, parent_id, author, text, status, created_at)
+			  VALUES (?, ?, ?, ?, ?, ?)`,
+		comment.NewsID, comment.ParentID, comment.Author, comment.Text, comment.Status, storage.Timestamp(comment.CreatedAt))
+	if err != nil {
+		return 0, fmt.Errorf("ошибка добавления комментария: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// Реализация метода для получения опубликованных комментариев по ID новости в порядке возрастания ID
+func (db *DB) GetCommentsByNewsID(ctx context.Context, newsID int64) ([]models.Comment, error) {
+	return db.queryComments(ctx, `SELECT id, news_id, parent_id, author, text, status, created_at FROM comments
+			  WHERE news_id = ? AND status = 'published' ORDER BY id`, newsID)
+}
+
+// Реализация метода для постраничного обхода опубликованных комментариев по ID
+func (db *DB) CommentsAfterID(ctx context.Context, afterID int64, limit int) ([]models.Comment, error) {
+	return db.queryComments(ctx, `SELECT id, news_id, parent_id, author, text, status, created_at FROM comments
+			  WHERE id > ? AND status = 'published' ORDER BY id LIMIT ?`, afterID, limit)
+}
+
+// Реализация метода для подсчета опубликованных комментариев
+func (db *DB) CountComments(ctx context.Context) (int64, error) {
+	var n int64
+	err := db.db.QueryRowContext(ctx, `SELECT count(*) FROM comments WHERE status = 'published'`).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка подсчета комментариев: %w", err)
+	}
+	return n, nil
+}
+
+// Реализация метода для полнотекстового поиска опубликованных комментариев.
+// Поиск идет по индексу FTS5: каждое слово запроса ищется как начало слова текста.
+func (db *DB) SearchComments(ctx context.Context, q storage.SearchQuery) ([]models.CommentMatch, int64, error) {
+	terms := storage.SearchTerms(q.Text)
+	if len(terms) == 0 {
+		return nil, 0, nil
+	}
+	match := make([]string, len(terms))
+	for i, term := range terms {
+		match[i] = `"` + term + `"*`
+	}
+	filter := `comments_fts MATCH ? AND c.status = 'published' AND (? = 0 OR c.news_id = ?)`
+	args := []interface{}{strings.Join(match, " "), q.NewsID, q.NewsID}
+
+	var total int64
+	err := db.db.QueryRowContext(ctx, `SELECT count(*) FROM comments_fts JOIN comments c ON c.id = comments_fts.rowid
+			  WHERE `+filter, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка поиска комментариев: %w", err)
+	}
+
+	rows, err := db.db.QueryContext(ctx, `SELECT c.id, c.news_id, c.parent_id, c.author, c.text, c.status, c.created_at,
+			  -bm25(comments_fts) AS rank, snippet(comments_fts, 0, char(1), char(2), '…', 30)
+			  FROM comments_fts JOIN comments c ON c.id = comments_fts.rowid
+			  WHERE `+filter+` ORDER BY rank DESC, c.id DESC LIMIT ? OFFSET ?`, append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка поиска комментариев: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []models.CommentMatch
+	for rows.Next() {
+		var m models.CommentMatch
+		err := rows.Scan(&m.ID, &m.NewsID, &m.ParentID, &m.Author, &m.Text, &m.Status, &m.CreatedAt, &m.Rank, &m.Snippet)
+		if err != nil {
+			return nil, 0, fmt.Errorf("ошибка обработки комментария: %w", err)
+		}
+		m.CreatedAt = m.CreatedAt.UTC()
+		m.Snippet = storage.Snippet(m.Snippet)
+		matches = append(matches, m)
+	}
+	return matches, total, rows.Err()
+}
+
+// Реализация метода для создания задания повторной модерации
+func (db *DB) CreateModerationJob(ctx context.Context, job models.ModerationJob) (int64, error) {
+	res, err := db.db.ExecContext(ctx, `INSERT INTO moderation_jobs (policy, dry_run, batch_size, status, total, started_at, updated_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?)`, job.Policy, job.DryRun, job.BatchSize, job.Status, job.Total,
+		storage.Timestamp(job.StartedAt), storage.Timestamp(job.UpdatedAt))
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания задания модерации: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// Реализация метода для получения задания повторной модерации
+func (db *DB) GetModerationJob(ctx context.Context, id int64) (models.ModerationJob, error) {
+	row := db.db.QueryRowContext(ctx, `SELECT `+moderationJobColumns+` FROM moderation_jobs WHERE id = ?`, id)
+	job, err := scanModerationJob(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ModerationJob{}, storage.ErrNotFound
+	}
+	if err != nil {
+		return models.ModerationJob{}, fmt.Errorf("ошибка получения задания модерации: %w", err)
+	}
+	return job, nil
+}
+
+// Реализация метода для получения заданий повторной модерации с указанным статусом
+func (db *DB) ModerationJobsByStatus(ctx context.Context, status string) ([]models.ModerationJob, error) {
+	rows, err := db.db.QueryContext(ctx, `SELECT `+moderationJobColumns+` FROM moderation_jobs WHERE status = ? ORDER BY id`, status)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения заданий модерации: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []models.ModerationJob
+	for rows.Next() {
+		job, err := scanModerationJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка обработки задания модерации: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// Реализация метода для обновления состояния задания повторной модерации
+func (db *DB) UpdateModerationJob(ctx context.Context, job models.ModerationJob) error {
+	return updateModerationJob(ctx, db.db, job)
+}
+
+// Реализация метода для условного обновления задания повторной модерации
+func (db *DB) ClaimModerationJob(ctx context.Context, job, prev models.ModerationJob) (bool, error) {
+	args := append(moderationJobArgs(job), prev.Status, storage.Timestamp(prev.UpdatedAt))
+	res, err := db.db.ExecContext(ctx, updateModerationJobSQL+` AND status = ? AND updated_at = ?`, args...)
+	if err != nil {
+		return false, fmt.Errorf("ошибка обновления задания модерации: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ошибка обновления задания модерации: %w", err)
+	}
+	return n == 1, nil
+}
+
+// Реализация метода для сохранения результатов пакета повторной модерации
+func (db *DB) CommitModerationBatch(ctx context.Context, job models.ModerationJob, changes []models.ModerationChange) error {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, ch := range changes {
+		rules, err := json.Marshal(ch.Rules)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO moderation_job_changes
+			  (job_id, comment_id, decision, rules, old_status, new_status, new_text)
+			  VALUES (?, ?, ?, ?, ?, ?, ?) ON CONFLICT (job_id, comment_id) DO NOTHING`,
+			job.ID, ch.CommentID, ch.Decision, string(rules), ch.OldStatus, ch.NewStatus, ch.NewText)
+		if err != nil {
+			return fmt.Errorf("ошибка сохранения изменения: %w", err)
+		}
+		if job.DryRun {
+			continue
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE comments SET status = ?, text = COALESCE(?, text) WHERE id = ?`,
+			ch.NewStatus, ch.NewText, ch.CommentID)
+		if err != nil {
+			return fmt.Errorf("ошибка изменения комментария: %w", err)
+		}
+	}
+	if err := updateModerationJob(ctx, tx, job); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Реализация метода для получения изменений задания повторной модерации
+func (db *DB) GetModerationChanges(ctx context.Context, jobID int64) ([]models.ModerationChange, error) {
+	rows, err := db.db.QueryContext(ctx, `SELECT job_id, comment_id, decision, rules, old_status, new_status, new_text
+			  FROM moderation_job_changes WHERE job_id = ? ORDER BY comment_id`, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения изменений: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []models.ModerationChange
+	for rows.Next() {
+		var ch models.ModerationChange
+		var rules string
+		err := rows.Scan(&ch.JobID, &ch.CommentID, &ch.Decision, &rules, &ch.OldStatus, &ch.NewStatus, &ch.NewText)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка обработки изменения: %w", err)
+		}
+		if err := json.Unmarshal([]byte(rules), &ch.Rules); err != nil {
+			return nil, fmt.Errorf("ошибка обработки изменения: %w", err)
+		}
+		changes = append(changes, ch)
+	}
+	return changes, rows.Err()
+}
+
+// Закрытие соединения с БД
+func (db *DB) Close() {
+	db.db.Close()
+}
+
+// queryComments выполняет запрос, возвращающий комментарии.
+func (db *DB) queryComments(ctx context.Context, query string, args ...interface{}) ([]models.Comment, error) {
+	rows, err := db.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения комментариев: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []models.Comment
+	for rows.Next() {
+		var comment models.Comment
+		err := rows.Scan(&comment.ID, &comment.NewsID, &comment.ParentID, &comment.Author, &comment.Text, &comment.Status, &comment.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка обработки комментария: %w", err)
+		}
+		comment.CreatedAt = comment.CreatedAt.UTC()
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
+}
+
+// execer - общий интерфейс соединения и транзакции.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+const updateModerationJobSQL = `UPDATE moderation_jobs SET status = ?, last_id = ?, total = ?, scanned = ?,
+			  changed = ?, error = ?, updated_at = ?, finished_at = ? WHERE id = ?`
+
+func updateModerationJob(ctx context.Context, db execer, job models.ModerationJob) error {
+	if _, err := db.ExecContext(ctx, updateModerationJobSQL, moderationJobArgs(job)...); err != nil {
+		return fmt.Errorf("ошибка обновления задания модерации: %w", err)
+	}
+	return nil
+}
+
+// moderationJobArgs возвращает аргументы запроса updateModerationJobSQL.
+func moderationJobArgs(job models.ModerationJob) []interface{} {
+	var finishedAt *time.Time
+	if job.FinishedAt != nil {
+		t := storage.Timestamp(*job.FinishedAt)
+		finishedAt = &t
+	}
+	return []interface{}{job.Status, job.LastID, job.Total, job.Scanned, job.Changed, job.Error,
+		storage.Timestamp(job.UpdatedAt), finishedAt, job.ID}
+}
+
+// scanner - общий интерфейс строки и набора строк результата.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanModerationJob(row scanner) (models.ModerationJob, error) {
+	var job models.ModerationJob
+	var finishedAt sql.NullTime
+	err := row.Scan(&job.ID, &job.Policy, &job.DryRun, &job.BatchSize, &job.Status, &job.LastID, &job.Total,
+		&job.Scanned, &job.Changed, &job.Error, &job.StartedAt, &job.UpdatedAt, &finishedAt)
+	job.StartedAt, job.UpdatedAt = job.StartedAt.UTC(), job.UpdatedAt.UTC()
+	if finishedAt.Valid {
+		t := finishedAt.Time.UTC()
+		job.FinishedAt = &t
+	}
+	return job, err
+}
@@ -0,0 +1,27 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"APIGetaway/pkg/migrations"
+	"APIGetaway/pkg/storage"
+	"APIGetaway/pkg/storage/sqlite"
+	"APIGetaway/pkg/storage/storagetest"
+)
+
+func TestDB(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.DBInterface {
+		cfg := storage.DBConfig{Driver: storage.DriverSQLite, Path: filepath.Join(t.TempDir(), "comments.db")}
+		if _, err := migrations.RunMigrations(context.Background(), cfg.Driver, cfg.DSN()); err != nil {
+			t.Fatalf("Ошибка выполнения миграций: %v", err)
+		}
+		db, err := sqlite.New(cfg.DSN())
+		if err != nil {
+			t.Fatalf("Ошибка открытия БД: %v", err)
+		}
+		t.Cleanup(db.Close)
+		return db
+	})
+}
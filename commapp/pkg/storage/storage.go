@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v4/pgxpool"
 )
@@ -12,18 +13,69 @@ import (
 // Интерфейс для работы с базой данных
 type DBInterface interface {
 	AddComment(ctx context.Context, comment models.Comment) (int64, error)
+	// GetCommentsByNewsID возвращает опубликованные комментарии к новости в порядке возрастания ID.
 	GetCommentsByNewsID(ctx context.Context, newsID int64) ([]models.Comment, error)
+	// CommentsAfterID возвращает до limit опубликованных комментариев с ID больше afterID в порядке возрастания ID.
+	CommentsAfterID(ctx context.Context, afterID int64, limit int) ([]models.Comment, error)
+	// CountComments возвращает число опубликованных комментариев.
+	CountComments(ctx context.Context) (int64, error)
+	// SearchComments ищет опубликованные комментарии, содержащие все слова запроса,
+	// в порядке убывания релевантности и возвращает страницу результатов и общее число найденных.
+	SearchComments(ctx context.Context, q SearchQuery) ([]models.CommentMatch, int64, error)
+
+	CreateModerationJob(ctx context.Context, job models.ModerationJob) (int64, error)
+	GetModerationJob(ctx context.Context, id int64) (models.ModerationJob, error)
+	ModerationJobsByStatus(ctx context.Context, status string) ([]models.ModerationJob, error)
+	UpdateModerationJob(ctx context.Context, job models.ModerationJob) error
+	// ClaimModerationJob сохраняет состояние задания job, только если задание в БД
+	// не менялось с момента чтения prev: у него тот же статус и время обновления.
+	// Возвращает false, если задание уже изменил другой исполнитель.
+	ClaimModerationJob(ctx context.Context, job, prev models.ModerationJob) (bool, error)
+	// CommitModerationBatch атомарно сохраняет изменения пакета комментариев
+	// (применяет их, если задание не пробное) и контрольную точку задания.
+	CommitModerationBatch(ctx context.Context, job models.ModerationJob, changes []models.ModerationChange) error
+	GetModerationChanges(ctx context.Context, jobID int64) ([]models.ModerationChange, error)
 	Close()
 }
 
+// ErrNotFound возвращается, если запрошенная запись не найдена.
+var ErrNotFound = errors.New("запись не найдена")
+
+// Драйверы хранилища
+const (
+	DriverPostgres = "postgres" // Postgres, используется по умолчанию
+	DriverSQLite   = "sqlite"   // SQLite, база в одном файле
+	DriverMemory   = "memory"   // хранилище в памяти, данные теряются при остановке
+)
+
 // Конфигурация БД
 type DBConfig struct {
+	Driver   string `json:"driver"` // драйвер хранилища, пустой - postgres
 	Host     string `json:"host"`
 	User     string `json:"user"`
 	Password string `json:"password"`
 	DBName   string `json:"dbname"`
 	Port     int    `json:"port"`
 	SSLMode  string `json:"sslmode"`
+	Path     string `json:"path"` // файл базы данных SQLite
+}
+
+// DSN возвращает строку подключения для драйвера из конфигурации.
+func (c DBConfig) DSN() string {
+	if c.Driver == DriverSQLite {
+		// Ожидание блокировки вместо ошибки SQLITE_BUSY и проверка внешних ключей
+		return "file:" + c.Path + "?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)"
+	}
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
+		c.Host, c.User, c.Password, c.DBName, c.Port, c.SSLMode)
+}
+
+// Timestamp приводит время к виду, в котором его возвращает столбец TIMESTAMP
+// в Postgres: время по часам без часового пояса с точностью до микросекунды.
+// Остальные реализации хранилища приводят время так же.
+func Timestamp(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC).
+		Truncate(time.Microsecond)
 }
 
 // База данных
@@ -36,7 +88,12 @@ func New(connstr string) (*DB, error) {
 	if connstr == "" {
 		return nil, errors.New("не указано подключение к БД")
 	}
-	pool, err := pgxpool.Connect(context.Background(), connstr)
+	cfg, err := pgxpool.ParseConfig(connstr)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подключения к БД: %w", err)
+	}
+	withQueryTracer(cfg.ConnConfig)
+	pool, err := pgxpool.ConnectConfig(context.Background(), cfg)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка подключения к БД: %w", err)
 	}
@@ -50,18 +107,22 @@ func New(connstr string) (*DB, error) {
 // Реализация метода для добавления комментария
 func (db *DB) AddComment(ctx context.Context, comment models.Comment) (int64, error) {
 	var id int64
-	query := `INSERT INTO comments (news_id, parent_id, text, created_at)
-			  VALUES ($1, $2, $3, $4) RETURNING id`
-	err := db.pool.QueryRow(ctx, query, comment.NewsID, comment.ParentID, comment.Text, comment.CreatedAt).Scan(&id)
+	if comment.Status == "" {
+		comment.Status = models.StatusPublished
+	}
+	query := `INSERT INTO comments (news_id, parent_id, author, text, status, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	err := db.pool.QueryRow(ctx, query, comment.NewsID, comment.ParentID, comment.Author, comment.Text, comment.Status, comment.CreatedAt).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("ошибка добавления комментария: %w", err)
 	}
 	return id, nil
 }
 
-// Реализация метода для получения комментариев по ID новости
+// Реализация метода для получения опубликованных комментариев по ID новости в порядке возрастания ID
 func (db *DB) GetCommentsByNewsID(ctx context.Context, newsID int64) ([]models.Comment, error) {
-	query := `SELECT id, news_id, parent_id, text, created_at FROM comments WHERE news_id = $1`
+	query := `SELECT id, news_id, parent_id, author, text, status, created_at FROM comments
+			  WHERE news_id = $1 AND status = 'published' ORDER BY id`
 	rows, err := db.pool.Query(ctx, query, newsID)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения комментариев: %w", err)
@@ -72,7 +133,7 @@ func (db *DB) GetCommentsByNewsID(ctx context.Context, newsID int64) ([]models.C
 	for rows.Next() {
 		var comment models.Comment
 		var parentID *int64
-		err := rows.Scan(&comment.ID, &comment.NewsID, &parentID, &comment.Text, &comment.CreatedAt)
+		err := rows.Scan(&comment.ID, &comment.NewsID, &parentID, &comment.Author, &comment.Text, &comment.Status, &comment.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("ошибка обработки комментария: %w", err)
 		}
@@ -82,6 +143,38 @@ func (db *DB) GetCommentsByNewsID(ctx context.Context, newsID int64) ([]models.C
 	return comments, nil
 }
 
+// Реализация метода для постраничного обхода опубликованных комментариев по ID
+func (db *DB) CommentsAfterID(ctx context.Context, afterID int64, limit int) ([]models.Comment, error) {
+	query := `SELECT id, news_id, parent_id, author, text, status, created_at FROM comments
+			  WHERE id > $1 AND status = 'published' ORDER BY id LIMIT $2`
+	rows, err := db.pool.Query(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения комментариев: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []models.Comment
+	for rows.Next() {
+		var comment models.Comment
+		err := rows.Scan(&comment.ID, &comment.NewsID, &comment.ParentID, &comment.Author, &comment.Text, &comment.Status, &comment.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка обработки комментария: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
+}
+
+// Реализация метода для подсчета опубликованных комментариев
+func (db *DB) CountComments(ctx context.Context) (int64, error) {
+	var n int64
+	err := db.pool.QueryRow(ctx, `SELECT count(*) FROM comments WHERE status = 'published'`).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка подсчета комментариев: %w", err)
+	}
+	return n, nil
+}
+
 // Закрытие соединения с БД
 func (db *DB) Close() {
 	db.pool.Close()
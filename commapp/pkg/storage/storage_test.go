@@ -0,0 +1,47 @@
+package storage_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"APIGetaway/pkg/migrations"
+	"APIGetaway/pkg/storage"
+	"APIGetaway/pkg/storage/storagetest"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// TestDB проверяет хранилище Postgres общим набором тестов.
+// Нужна отдельная тестовая база, ее адрес задается переменной окружения
+// COMMAPP_TEST_DSN, например "host=localhost user=postgres dbname=comments_test".
+// Все таблицы базы очищаются перед каждым подтестом.
+func TestDB(t *testing.T) {
+	dsn := os.Getenv("COMMAPP_TEST_DSN")
+	if dsn == "" {
+		t.Skip("COMMAPP_TEST_DSN не задана, тесты Postgres пропущены")
+	}
+	ctx := context.Background()
+	if _, err := migrations.RunMigrations(ctx, storage.DriverPostgres, dsn); err != nil {
+		t.Fatalf("Ошибка выполнения миграций: %v", err)
+	}
+
+	storagetest.Run(t, func(t *testing.T) storage.DBInterface {
+		pool, err := pgxpool.Connect(ctx, dsn)
+		if err != nil {
+			t.Fatalf("Ошибка подключения к БД: %v", err)
+		}
+		defer pool.Close()
+		_, err = pool.Exec(ctx, `TRUNCATE comments, moderation_jobs, moderation_job_changes RESTART IDENTITY`)
+		if err != nil {
+			t.Fatalf("Ошибка очистки таблиц: %v", err)
+		}
+
+		db, err := storage.New(dsn)
+		if err != nil {
+			t.Fatalf("Ошибка подключения к БД: %v", err)
+		}
+		t.Cleanup(db.Close)
+		return db
+	})
+}
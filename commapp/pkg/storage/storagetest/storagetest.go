@@ -0,0 +1,366 @@
+// Package storagetest содержит общий набор тестов для реализаций storage.DBInterface.
+// Каждая реализация запускает его в своих тестах, поэтому их поведение не расходится.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"APIGetaway/pkg/models"
+	"APIGetaway/pkg/storage"
+)
+
+// Run запускает набор тестов. newDB должна возвращать пустое хранилище
+// для каждого подтеста; закрывать его должна сама newDB через t.Cleanup.
+func Run(t *testing.T, newDB func(t *testing.T) storage.DBInterface) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, db storage.DBInterface)
+	}{
+		{"Комментарии", testComments},
+		{"Обход комментариев", testCommentsAfterID},
+		{"Поиск комментариев", testSearchComments},
+		{"Задания модерации", testModerationJobs},
+		{"Захват задания модерации", testClaimModerationJob},
+		{"Пакет модерации", testCommitModerationBatch},
+		{"Пробный пакет модерации", testCommitModerationBatchDryRun},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newDB(t))
+		})
+	}
+}
+
+// created - время создания комментариев в тестах, точность Postgres - микросекунды.
+var created = time.Date(2024, 12, 1, 10, 30, 0, 123456000, time.UTC)
+
+func testComments(t *testing.T, db storage.DBInterface) {
+	ctx := context.Background()
+
+	first := addComment(t, db, models.Comment{NewsID: 1, Author: "anna", Text: "Первый", CreatedAt: created})
+	parent := first
+	reply := addComment(t, db, models.Comment{NewsID: 1, ParentID: &parent, Text: "Ответ", CreatedAt: created})
+	addComment(t, db, models.Comment{NewsID: 1, Text: "На проверке", Status: models.StatusReview, CreatedAt: created})
+	other := addComment(t, db, models.Comment{NewsID: 2, Text: "Другая новость", CreatedAt: created})
+
+	if first <= 0 || reply <= first || other <= reply {
+		t.Fatalf("ID должны возрастать: %d, %d, %d", first, reply, other)
+	}
+
+	want := []models.Comment{
+		{ID: first, NewsID: 1, Author: "anna", Text: "Первый", Status: models.StatusPublished, CreatedAt: created},
+		{ID: reply, NewsID: 1, ParentID: &parent, Text: "Ответ", Status: models.StatusPublished, CreatedAt: created},
+	}
+	got, err := db.GetCommentsByNewsID(ctx, 1)
+	if err != nil {
+		t.Fatalf("Ошибка получения комментариев: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Неверные комментарии новости:\nожидается %+v\nполучено  %+v", want, got)
+	}
+
+	got, err = db.GetCommentsByNewsID(ctx, 3)
+	if err != nil || len(got) != 0 {
+		t.Errorf("Для новости без комментариев ожидается пустой список, получено %+v (%v)", got, err)
+	}
+
+	n, err := db.CountComments(ctx)
+	if err != nil || n != 3 {
+		t.Errorf("Неверное число опубликованных комментариев: ожидается 3, получено %d (%v)", n, err)
+	}
+}
+
+func testCommentsAfterID(t *testing.T, db storage.DBInterface) {
+	ctx := context.Background()
+
+	var ids []int64
+	for i := 0; i < 5; i++ {
+		status := models.StatusPublished
+		if i == 2 {
+			status = models.StatusHidden
+		}
+		ids = append(ids, addComment(t, db, models.Comment{NewsID: 1, Text: "текст", Status: status, CreatedAt: created}))
+	}
+
+	got, err := db.CommentsAfterID(ctx, 0, 2)
+	if err != nil {
+		t.Fatalf("Ошибка получения комментариев: %v", err)
+	}
+	if gotIDs := commentIDs(got); !reflect.DeepEqual(gotIDs, []int64{ids[0], ids[1]}) {
+		t.Errorf("Неверная первая страница: %v", gotIDs)
+	}
+
+	got, err = db.CommentsAfterID(ctx, ids[1], 10)
+	if err != nil {
+		t.Fatalf("Ошибка получения комментариев: %v", err)
+	}
+	if gotIDs := commentIDs(got); !reflect.DeepEqual(gotIDs, []int64{ids[3], ids[4]}) {
+		t.Errorf("Неверная вторая страница, скрытые комментарии пропускаются: %v", gotIDs)
+	}
+
+	got, err = db.CommentsAfterID(ctx, ids[4], 10)
+	if err != nil || len(got) != 0 {
+		t.Errorf("После последнего ID ожидается пустой список, получено %v (%v)", commentIDs(got), err)
+	}
+}
+
+func testSearchComments(t *testing.T, db storage.DBInterface) {
+	ctx := context.Background()
+
+	first := addComment(t, db, models.Comment{NewsID: 1, Text: "Отличная статья про Python и асинхронность", CreatedAt: created})
+	python := addComment(t, db, models.Comment{NewsID: 1, Text: "Python, Python, Python! Отличная", CreatedAt: created})
+	other := addComment(t, db, models.Comment{NewsID: 2, Text: "Отличная погода сегодня", CreatedAt: created})
+	addComment(t, db, models.Comment{NewsID: 1, Text: "Отличная статья", Status: models.StatusReview, CreatedAt: created})
+	markup := addComment(t, db, models.Comment{NewsID: 1, Text: "Скучная <script> статья", CreatedAt: created})
+
+	tests := []struct {
+		name      string
+		query     storage.SearchQuery
+		wantIDs   []int64
+		wantTotal int64
+	}{
+		{"Одно слово, более короткий текст выше", storage.SearchQuery{Text: "статья", Limit: 10}, []int64{markup, first}, 2},
+		{"Все слова запроса", storage.SearchQuery{Text: "отличная статья", Limit: 10}, []int64{first}, 1},
+		{"Частое слово выше", storage.SearchQuery{Text: "python", NewsID: 1, Limit: 10}, []int64{python, first}, 2},
+		{"Фильтр по новости", storage.SearchQuery{Text: "отличная", NewsID: 2, Limit: 10}, []int64{other}, 1},
+		{"Слова из разных комментариев", storage.SearchQuery{Text: "python погода", Limit: 10}, []int64{}, 0},
+		{"Слова на разных языках", storage.SearchQuery{Text: "python асинхронность", Limit: 10}, []int64{first}, 1},
+		{"Ничего не найдено", storage.SearchQuery{Text: "несуществующее", Limit: 10}, []int64{}, 0},
+	}
+	for _, tt := range tests {
+		got, total, err := db.SearchComments(ctx, tt.query)
+		if err != nil {
+			t.Fatalf("%s: ошибка поиска: %v", tt.name, err)
+		}
+		if gotIDs := matchIDs(got); !reflect.DeepEqual(gotIDs, tt.wantIDs) || total != tt.wantTotal {
+			t.Errorf("%s: ожидается %v из %d, получено %v из %d", tt.name, tt.wantIDs, tt.wantTotal, gotIDs, total)
+		}
+	}
+
+	got, total, err := db.SearchComments(ctx, storage.SearchQuery{Text: "отличная", Limit: 2, Offset: 2})
+	if err != nil || len(got) != 1 || total != 3 {
+		t.Errorf("Неверная последняя страница: получено %v из %d (%v)", matchIDs(got), total, err)
+	}
+	got, total, err = db.SearchComments(ctx, storage.SearchQuery{Text: "отличная", Limit: 2, Offset: 10})
+	if err != nil || len(got) != 0 || total != 3 {
+		t.Errorf("За концом выдачи ожидается пустая страница и общее число, получено %v из %d (%v)", matchIDs(got), total, err)
+	}
+
+	got, _, err = db.SearchComments(ctx, storage.SearchQuery{Text: "статья", Limit: 1})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("Ошибка поиска: %v", err)
+	}
+	if s := got[0].Snippet; !strings.Contains(s, "<mark>статья</mark>") || strings.Contains(s, "<script>") {
+		t.Errorf("Найденное слово должно быть выделено, а разметка текста экранирована: %q", s)
+	}
+	if got[0].Text != "Скучная <script> статья" || got[0].Rank <= 0 {
+		t.Errorf("Неверный найденный комментарий: %+v", got[0])
+	}
+}
+
+func testModerationJobs(t *testing.T, db storage.DBInterface) {
+	ctx := context.Background()
+
+	if _, err := db.GetModerationJob(ctx, 1000); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Для несуществующего задания ожидается ErrNotFound, получено %v", err)
+	}
+
+	// Счетчики при создании не сохраняются и начинаются с нуля
+	id, err := db.CreateModerationJob(ctx, models.ModerationJob{
+		Policy: "strict", DryRun: true, BatchSize: 50, Status: models.JobRunning, Total: 7,
+		LastID: 100, Scanned: 100, StartedAt: created, UpdatedAt: created,
+	})
+	if err != nil {
+		t.Fatalf("Ошибка создания задания: %v", err)
+	}
+	want := models.ModerationJob{
+		ID: id, Policy: "strict", DryRun: true, BatchSize: 50, Status: models.JobRunning, Total: 7,
+		StartedAt: created, UpdatedAt: created,
+	}
+	job, err := db.GetModerationJob(ctx, id)
+	if err != nil {
+		t.Fatalf("Ошибка получения задания: %v", err)
+	}
+	if !reflect.DeepEqual(job, want) {
+		t.Errorf("Неверное задание:\nожидается %+v\nполучено  %+v", want, job)
+	}
+
+	finished := created.Add(time.Minute)
+	want.Status, want.LastID, want.Scanned, want.Changed = models.JobDone, 42, 7, 2
+	want.Error, want.UpdatedAt, want.FinishedAt = "", finished, &finished
+	if err := db.UpdateModerationJob(ctx, want); err != nil {
+		t.Fatalf("Ошибка обновления задания: %v", err)
+	}
+	job, err = db.GetModerationJob(ctx, id)
+	if err != nil {
+		t.Fatalf("Ошибка получения задания: %v", err)
+	}
+	if !reflect.DeepEqual(job, want) {
+		t.Errorf("Неверное задание после обновления:\nожидается %+v\nполучено  %+v", want, job)
+	}
+
+	second, err := db.CreateModerationJob(ctx, models.ModerationJob{BatchSize: 10, Status: models.JobPaused, StartedAt: created, UpdatedAt: created})
+	if err != nil {
+		t.Fatalf("Ошибка создания задания: %v", err)
+	}
+	third, err := db.CreateModerationJob(ctx, models.ModerationJob{BatchSize: 10, Status: models.JobPaused, StartedAt: created, UpdatedAt: created})
+	if err != nil {
+		t.Fatalf("Ошибка создания задания: %v", err)
+	}
+	jobs, err := db.ModerationJobsByStatus(ctx, models.JobPaused)
+	if err != nil {
+		t.Fatalf("Ошибка получения заданий: %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].ID != second || jobs[1].ID != third {
+		t.Errorf("Неверные задания со статусом paused: %+v", jobs)
+	}
+}
+
+func testClaimModerationJob(t *testing.T, db storage.DBInterface) {
+	ctx := context.Background()
+	prev := createJob(t, db, false)
+
+	// Первый исполнитель захватывает задание, второй с тем же прочитанным состоянием - нет
+	claimed := prev
+	claimed.UpdatedAt = created.Add(time.Second)
+	for i, want := range []bool{true, false} {
+		ok, err := db.ClaimModerationJob(ctx, claimed, prev)
+		if err != nil {
+			t.Fatalf("Ошибка захвата задания: %v", err)
+		}
+		if ok != want {
+			t.Errorf("Захват %d: ожидается %v, получено %v", i+1, want, ok)
+		}
+	}
+
+	// Статус в БД отличается от прочитанного
+	stale := claimed
+	stale.Status = models.JobPaused
+	next := claimed
+	next.UpdatedAt = created.Add(time.Minute)
+	if ok, err := db.ClaimModerationJob(ctx, next, stale); err != nil || ok {
+		t.Errorf("Захват задания с другим статусом: ожидается false, получено %v, %v", ok, err)
+	}
+
+	job, err := db.GetModerationJob(ctx, prev.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения задания: %v", err)
+	}
+	if job.Status != models.JobRunning || !job.UpdatedAt.Equal(claimed.UpdatedAt) {
+		t.Errorf("Неверное задание после захвата: %+v", job)
+	}
+}
+
+func testCommitModerationBatch(t *testing.T, db storage.DBInterface) {
+	ctx := context.Background()
+
+	hidden := addComment(t, db, models.Comment{NewsID: 1, Text: "плохой", CreatedAt: created})
+	masked := addComment(t, db, models.Comment{NewsID: 1, Text: "почти плохой", CreatedAt: created})
+	job := createJob(t, db, false)
+
+	text := "почти ***"
+	changes := []models.ModerationChange{
+		{CommentID: masked, Decision: "deny", Rules: []string{"r1"}, OldStatus: models.StatusPublished, NewStatus: models.StatusPublished, NewText: &text},
+		{CommentID: hidden, Decision: "deny", Rules: []string{"r1", "r2"}, OldStatus: models.StatusPublished, NewStatus: models.StatusHidden},
+	}
+	job.LastID, job.Scanned, job.Changed = masked, 2, 2
+	if err := db.CommitModerationBatch(ctx, job, changes); err != nil {
+		t.Fatalf("Ошибка сохранения пакета: %v", err)
+	}
+	// Повторное сохранение того же пакета не дублирует изменения
+	if err := db.CommitModerationBatch(ctx, job, changes[:1]); err != nil {
+		t.Fatalf("Ошибка повторного сохранения пакета: %v", err)
+	}
+
+	got, err := db.GetModerationChanges(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения изменений: %v", err)
+	}
+	want := []models.ModerationChange{
+		{JobID: job.ID, CommentID: hidden, Decision: "deny", Rules: []string{"r1", "r2"}, OldStatus: models.StatusPublished, NewStatus: models.StatusHidden},
+		{JobID: job.ID, CommentID: masked, Decision: "deny", Rules: []string{"r1"}, OldStatus: models.StatusPublished, NewStatus: models.StatusPublished, NewText: &text},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Неверные изменения:\nожидается %+v\nполучено  %+v", want, got)
+	}
+
+	comments, err := db.GetCommentsByNewsID(ctx, 1)
+	if err != nil {
+		t.Fatalf("Ошибка получения комментариев: %v", err)
+	}
+	if len(comments) != 1 || comments[0].ID != masked || comments[0].Text != text {
+		t.Errorf("Изменения не применены к комментариям: %+v", comments)
+	}
+
+	stored, err := db.GetModerationJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения задания: %v", err)
+	}
+	if stored.LastID != masked || stored.Scanned != 2 || stored.Changed != 2 {
+		t.Errorf("Контрольная точка не сохранена: %+v", stored)
+	}
+}
+
+func testCommitModerationBatchDryRun(t *testing.T, db storage.DBInterface) {
+	ctx := context.Background()
+
+	id := addComment(t, db, models.Comment{NewsID: 1, Text: "плохой", CreatedAt: created})
+	job := createJob(t, db, true)
+
+	changes := []models.ModerationChange{
+		{CommentID: id, Decision: "deny", Rules: []string{"r1"}, OldStatus: models.StatusPublished, NewStatus: models.StatusHidden},
+	}
+	if err := db.CommitModerationBatch(ctx, job, changes); err != nil {
+		t.Fatalf("Ошибка сохранения пакета: %v", err)
+	}
+
+	got, err := db.GetModerationChanges(ctx, job.ID)
+	if err != nil || len(got) != 1 {
+		t.Errorf("Пробное задание должно сохранять отчет, получено %+v (%v)", got, err)
+	}
+	comments, err := db.GetCommentsByNewsID(ctx, 1)
+	if err != nil || len(comments) != 1 || comments[0].Status != models.StatusPublished {
+		t.Errorf("Пробное задание не должно менять комментарии, получено %+v (%v)", comments, err)
+	}
+}
+
+func addComment(t *testing.T, db storage.DBInterface, c models.Comment) int64 {
+	t.Helper()
+	id, err := db.AddComment(context.Background(), c)
+	if err != nil {
+		t.Fatalf("Ошибка добавления комментария: %v", err)
+	}
+	return id
+}
+
+func createJob(t *testing.T, db storage.DBInterface, dryRun bool) models.ModerationJob {
+	t.Helper()
+	job := models.ModerationJob{DryRun: dryRun, BatchSize: 10, Status: models.JobRunning, StartedAt: created, UpdatedAt: created}
+	id, err := db.CreateModerationJob(context.Background(), job)
+	if err != nil {
+		t.Fatalf("Ошибка создания задания: %v", err)
+	}
+	job.ID = id
+	return job
+}
+
+func matchIDs(matches []models.CommentMatch) []int64 {
+	ids := []int64{}
+	for _, m := range matches {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+func commentIDs(comments []models.Comment) []int64 {
+	ids := []int64{}
+	for _, c := range comments {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"APIGetaway/shared/tracing"
+
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queryTracer создает спаны запросов к Postgres. В pgx v4 нет отдельного
+// интерфейса трассировки, поэтому спаны строятся по записям журнала pgx,
+// которые он передает после выполнения запроса вместе с его длительностью.
+// Спаны создаются только внутри трассы запроса, фоновые запросы без
+// родительского спана не трассируются.
+type queryTracer struct{}
+
+// tracedMessages - записи журнала pgx о выполненных запросах.
+var tracedMessages = map[string]bool{
+	"Query":     true,
+	"Exec":      true,
+	"SendBatch": true,
+	"CopyFrom":  true,
+}
+
+// Log реализует pgx.Logger.
+func (queryTracer) Log(ctx context.Context, level pgx.LogLevel, msg string, data map[string]interface{}) {
+	if !tracedMessages[msg] || !trace.SpanFromContext(ctx).IsRecording() {
+		return
+	}
+	end := time.Now()
+	start := end
+	if d, ok := data["time"].(time.Duration); ok {
+		start = end.Add(-d)
+	}
+
+	sql, _ := data["sql"].(string)
+	attrs := []attribute.KeyValue{semconv.DBSystemPostgreSQL}
+	if sql != "" {
+		// Аргументы запроса в спан не попадают: в них могут быть данные пользователей
+		attrs = append(attrs, semconv.DBQueryText(sql))
+	}
+	_, span := tracing.Tracer().Start(ctx, spanName(msg, sql, data),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(attrs...),
+	)
+	if err, ok := data["err"].(error); ok {
+		span.RecordError(err, trace.WithTimestamp(end))
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(trace.WithTimestamp(end))
+}
+
+// spanName возвращает имя спана запроса: первое слово SQL, например SELECT.
+func spanName(msg, sql string, data map[string]interface{}) string {
+	switch msg {
+	case "SendBatch":
+		return "BATCH"
+	case "CopyFrom":
+		return fmt.Sprintf("COPY %v", data["tableName"])
+	}
+	if fields := strings.Fields(sql); len(fields) > 0 {
+		return strings.ToUpper(fields[0])
+	}
+	return msg
+}
+
+// withQueryTracer включает спаны запросов для пула с настройками cfg.
+func withQueryTracer(cfg *pgx.ConnConfig) {
+	cfg.Logger = queryTracer{}
+	cfg.LogLevel = pgx.LogLevelInfo
+}
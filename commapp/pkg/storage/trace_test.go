@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"APIGetaway/shared/tracing"
+
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestQueryTracer(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+
+	ctx, parent := tracing.Tracer().Start(context.Background(), "request")
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		msg      string
+		data     map[string]interface{}
+		wantName string // пустое - спан не создается
+		wantErr  bool
+	}{
+		{
+			name: "Запрос", ctx: ctx, msg: "Query",
+			data:     map[string]interface{}{"sql": "  select id from comments", "time": 20 * time.Millisecond},
+			wantName: "SELECT",
+		},
+		{
+			name: "Ошибка запроса", ctx: ctx, msg: "Exec",
+			data:     map[string]interface{}{"sql": "INSERT INTO comments", "time": time.Millisecond, "err": errors.New("нет соединения")},
+			wantName: "INSERT", wantErr: true,
+		},
+		{name: "Пакет запросов", ctx: ctx, msg: "SendBatch", data: map[string]interface{}{"time": time.Millisecond}, wantName: "BATCH"},
+		{name: "Служебная запись", ctx: ctx, msg: "Dialing PostgreSQL server", data: map[string]interface{}{}},
+		{name: "Вне трассы", ctx: context.Background(), msg: "Query", data: map[string]interface{}{"sql": "SELECT 1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(rec.Ended())
+			queryTracer{}.Log(tt.ctx, pgx.LogLevelInfo, tt.msg, tt.data)
+			spans := rec.Ended()[before:]
+			if tt.wantName == "" {
+				if len(spans) != 0 {
+					t.Errorf("Спан не ожидается, получено %d", len(spans))
+				}
+				return
+			}
+			if len(spans) != 1 {
+				t.Fatalf("Ожидается 1 спан, получено %d", len(spans))
+			}
+			s := spans[0]
+			if s.Name() != tt.wantName || s.Parent().SpanID() != parent.SpanContext().SpanID() {
+				t.Errorf("Неверный спан %s", s.Name())
+			}
+			if d, _ := tt.data["time"].(time.Duration); s.EndTime().Sub(s.StartTime()) != d {
+				t.Errorf("Длительность спана: ожидается %v, получено %v", d, s.EndTime().Sub(s.StartTime()))
+			}
+			if got := s.Status().Code == codes.Error; got != tt.wantErr {
+				t.Errorf("Статус ошибки: ожидается %v, получено %v", tt.wantErr, got)
+			}
+		})
+	}
+	parent.End()
+}
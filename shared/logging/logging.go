@@ -0,0 +1,190 @@
+// Package logging настраивает структурированный журнал log/slog сервисов.
+// Все сервисы используют одинаковые имена полей, поэтому записи шлюза,
+// сервиса комментариев и сервиса цензуры можно связать по request_id и trace_id.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Имена полей журнала, общие для всех сервисов.
+const (
+	KeyService    = "service"     // имя сервиса
+	KeyRequestID  = "request_id"  // идентификатор запроса
+	KeyTraceID    = "trace_id"    // идентификатор трассы OpenTelemetry
+	KeyMethod     = "method"      // метод HTTP или gRPC
+	KeyPath       = "path"        // путь запроса
+	KeyRoute      = "route"       // шаблон маршрута, например /news/{id}
+	KeyStatus     = "status"      // статус ответа
+	KeyDuration   = "duration_ms" // длительность обработки в миллисекундах
+	KeyRemoteAddr = "remote_addr" // адрес клиента
+	KeyUpstream   = "upstream"    // сервис, к которому обращается запрос
+	KeyUser       = "user"        // автор комментария
+	KeyError      = "error"       // текст ошибки
+)
+
+// Форматы журнала
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// AccessLogFile - файл журнала по умолчанию, в который записи дублируются из stdout.
+const AccessLogFile = "access.log"
+
+// NoFile - значение Config.Path, при котором журнал пишется только в stdout.
+const NoFile = "-"
+
+// Config - настройки журнала.
+type Config struct {
+	Level  string `json:"level"`  // debug, info, warn или error, по умолчанию info
+	Format string `json:"format"` // json или text, по умолчанию json
+
+	Path        string `json:"path"`         // файл журнала, по умолчанию access.log, "-" - только stdout
+	MaxSizeMB   int    `json:"max_size_mb"`  // размер файла в мегабайтах для ротации, 0 - без ограничения
+	RotateEvery string `json:"rotate_every"` // период ротации, например "24h", пустой - без ротации по времени
+	MaxBackups  int    `json:"max_backups"`  // число хранимых старых файлов, 0 - хранить все
+	Compress    bool   `json:"compress"`     // сжимать старые файлы gzip
+}
+
+// rotateConfig возвращает параметры ротации файла журнала.
+func (c Config) rotateConfig() (RotateConfig, error) {
+	rc := RotateConfig{
+		MaxSize:    int64(c.MaxSizeMB) << 20,
+		MaxBackups: c.MaxBackups,
+		Compress:   c.Compress,
+	}
+	if c.MaxSizeMB < 0 || c.MaxBackups < 0 {
+		return rc, fmt.Errorf("неверные параметры ротации журнала")
+	}
+	if c.RotateEvery != "" {
+		d, err := time.ParseDuration(c.RotateEvery)
+		if err != nil || d <= 0 {
+			return rc, fmt.Errorf("неверный период ротации журнала %q", c.RotateEvery)
+		}
+		rc.Interval = d
+	}
+	return rc, nil
+}
+
+// NewHandler создает обработчик записей в w по настройкам cfg.
+func NewHandler(w io.Writer, cfg Config) (slog.Handler, error) {
+	var level slog.Level
+	if cfg.Level != "" {
+		if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+			return nil, fmt.Errorf("неизвестный уровень журнала %q", cfg.Level)
+		}
+	}
+	opts := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(cfg.Format) {
+	case "", FormatJSON:
+		return slog.NewJSONHandler(w, opts), nil
+	case FormatText:
+		return slog.NewTextHandler(w, opts), nil
+	default:
+		return nil, fmt.Errorf("неизвестный формат журнала %q", cfg.Format)
+	}
+}
+
+// Open создает журнал сервиса service, который пишет в stdout и в файл
+// cfg.Path с ротацией. По сигналу SIGHUP файл открывается заново.
+// Если файл открыть не удалось, журнал пишется только в stdout.
+// Ошибка возвращается только при неверных настройках.
+// Журнал нужно закрыть после остановки сервиса.
+func Open(service string, cfg Config) (*slog.Logger, io.Closer, error) {
+	rc, err := cfg.rotateConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := NewHandler(io.Discard, cfg); err != nil {
+		return nil, nil, err
+	}
+
+	path := cfg.Path
+	if path == "" {
+		path = AccessLogFile
+	}
+	var (
+		w       io.Writer = os.Stdout
+		closer  io.Closer = nopCloser{}
+		openErr error
+	)
+	if path != NoFile {
+		var f *File
+		if f, openErr = OpenFile(path, rc); openErr == nil {
+			w, closer = io.MultiWriter(os.Stdout, f), reopenOnSignal(f)
+		}
+	}
+
+	h, _ := NewHandler(w, cfg)
+	l := slog.New(h).With(KeyService, service)
+	if openErr != nil {
+		l.Warn("Не удалось открыть файл журнала, записи выводятся только в stdout",
+			"path", path, Err(openErr))
+	}
+	return l, closer, nil
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// Err возвращает поле журнала с текстом ошибки.
+func Err(err error) slog.Attr {
+	return slog.String(KeyError, err.Error())
+}
+
+type loggerKey struct{}
+
+type fieldsKey struct{}
+
+// fields - поля, которые обработчики добавляют к записям запроса.
+type fields struct {
+	mu    sync.Mutex
+	attrs []interface{}
+}
+
+// NewContext возвращает контекст с журналом запроса l.
+func NewContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// WithFields возвращает контекст, в котором обработчики запроса могут
+// добавлять поля функцией AddFields.
+func WithFields(ctx context.Context) context.Context {
+	return context.WithValue(ctx, fieldsKey{}, &fields{})
+}
+
+// AddFields добавляет поля ко всем последующим записям запроса, включая
+// итоговую запись о запросе. Вне WithFields вызов ничего не делает.
+func AddFields(ctx context.Context, args ...interface{}) {
+	if f, ok := ctx.Value(fieldsKey{}).(*fields); ok {
+		f.mu.Lock()
+		f.attrs = append(f.attrs, args...)
+		f.mu.Unlock()
+	}
+}
+
+// FromContext возвращает журнал запроса с добавленными полями,
+// а без журнала в контексте - журнал по умолчанию.
+func FromContext(ctx context.Context) *slog.Logger {
+	l, ok := ctx.Value(loggerKey{}).(*slog.Logger)
+	if !ok {
+		l = slog.Default()
+	}
+	if f, ok := ctx.Value(fieldsKey{}).(*fields); ok {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if len(f.attrs) > 0 {
+			l = l.With(f.attrs...)
+		}
+	}
+	return l
+}
@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNewHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+		debug   bool   // пишутся ли записи уровня debug
+		prefix  string // начало записи
+	}{
+		{name: "По умолчанию", cfg: Config{}, prefix: "{"},
+		{name: "Текст с уровнем debug", cfg: Config{Level: "debug", Format: "text"}, debug: true, prefix: "time="},
+		{name: "Уровень warn", cfg: Config{Level: "WARN", Format: "json"}, prefix: "{"},
+		{name: "Неизвестный уровень", cfg: Config{Level: "verbose"}, wantErr: true},
+		{name: "Неизвестный формат", cfg: Config{Format: "xml"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			h, err := NewHandler(&buf, tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Ошибка: ожидается %v, получено %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			l := slog.New(h)
+			l.Debug("отладка")
+			if got := buf.Len() > 0; got != tt.debug {
+				t.Errorf("Запись debug: ожидается %v, получено %v", tt.debug, got)
+			}
+			buf.Reset()
+			l.Error("ошибка")
+			if !strings.HasPrefix(buf.String(), tt.prefix) {
+				t.Errorf("Неверный формат записи: %s", buf.String())
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	h, _ := NewHandler(&buf, Config{})
+	base := slog.New(h).With(KeyRequestID, "abc")
+
+	ctx := WithFields(NewContext(context.Background(), base))
+	AddFields(ctx, KeyUser, "Иван")
+	FromContext(ctx).Info("запрос", KeyStatus, 200)
+
+	var rec map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("Ошибка при распаковке JSON: %v", err)
+	}
+	if rec[KeyRequestID] != "abc" || rec[KeyUser] != "Иван" || rec[KeyStatus] != float64(200) {
+		t.Errorf("Неверные поля записи: %v", rec)
+	}
+
+	// Без журнала в контексте используется журнал по умолчанию
+	if FromContext(context.Background()) != slog.Default() {
+		t.Errorf("Ожидается журнал по умолчанию")
+	}
+}
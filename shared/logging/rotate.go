@@ -0,0 +1,276 @@
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat - формат времени в имени старого файла журнала,
+// например access.log.20241109T153000.000. Если за одну миллисекунду файл
+// ротируется несколько раз, к имени добавляется номер: access.log.20241109T153000.000-1.
+const backupTimeFormat = "20060102T150405.000"
+
+// RotateConfig - параметры ротации файла журнала.
+type RotateConfig struct {
+	MaxSize    int64         // размер файла в байтах, после которого он ротируется, 0 - без ограничения
+	Interval   time.Duration // период ротации, 0 - без ротации по времени
+	MaxBackups int           // число хранимых старых файлов, 0 - хранить все
+	Compress   bool          // сжимать старые файлы gzip
+}
+
+// File - файл журнала с ротацией по размеру и времени. Старые файлы
+// переименовываются с отметкой времени, при необходимости сжимаются,
+// лишние удаляются. Методы безопасны для одновременного вызова.
+type File struct {
+	path string
+	cfg  RotateConfig
+	now  func() time.Time
+
+	mu       sync.Mutex
+	f        *os.File
+	size     int64
+	openedAt time.Time
+
+	wg sync.WaitGroup // сжатие и удаление старых файлов
+	bg sync.Mutex     // сжатие и удаление выполняются по очереди
+}
+
+// OpenFile открывает файл журнала path для дозаписи, создавая каталог при необходимости.
+func OpenFile(path string, cfg RotateConfig) (*File, error) {
+	f := &File{path: path, cfg: cfg, now: time.Now}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open открывает файл журнала. Вызывается с захваченным mu.
+func (f *File) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	// Возраст непустого файла отсчитывается от последней записи в него,
+	// иначе после каждого перезапуска период ротации начинался бы заново
+	openedAt := f.now()
+	if info.Size() > 0 && info.ModTime().Before(openedAt) {
+		openedAt = info.ModTime()
+	}
+	f.f, f.size, f.openedAt = file, info.Size(), openedAt
+	return nil
+}
+
+// Write дописывает p в файл, предварительно ротируя его, если превышен
+// размер или прошел период ротации.
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.f == nil {
+		return 0, os.ErrClosed
+	}
+	if f.due(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			if f.f == nil {
+				return 0, err
+			}
+		}
+	}
+	n, err := f.f.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// due сообщает, нужно ли ротировать файл перед записью n байт.
+func (f *File) due(n int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.cfg.MaxSize > 0 && f.size+n > f.cfg.MaxSize {
+		return true
+	}
+	return f.cfg.Interval > 0 && f.now().Sub(f.openedAt) >= f.cfg.Interval
+}
+
+// Rotate переименовывает текущий файл в старый и открывает новый.
+func (f *File) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.f == nil {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+
+// rotate выполняет ротацию. Вызывается с захваченным mu.
+func (f *File) rotate() error {
+	if err := f.f.Close(); err != nil {
+		return err
+	}
+	f.f = nil
+	backup := f.backupName(f.now())
+	if err := os.Rename(f.path, backup); err != nil {
+		// Продолжаем писать в прежний файл
+		if openErr := f.open(); openErr != nil {
+			return openErr
+		}
+		return fmt.Errorf("ошибка ротации журнала: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		f.bg.Lock()
+		defer f.bg.Unlock()
+		if f.cfg.Compress {
+			if err := compressFile(backup); err != nil {
+				fmt.Fprintf(os.Stderr, "Ошибка сжатия журнала %s: %v\n", backup, err)
+			}
+		}
+		f.removeOld()
+	}()
+	return nil
+}
+
+// Reopen закрывает и заново открывает файл журнала по прежнему пути.
+// Используется после внешней ротации, например logrotate.
+func (f *File) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.f == nil {
+		return os.ErrClosed
+	}
+	if err := f.f.Close(); err != nil {
+		return err
+	}
+	f.f = nil
+	return f.open()
+}
+
+// Close закрывает файл и дожидается сжатия старых файлов.
+func (f *File) Close() error {
+	f.mu.Lock()
+	var err error
+	if f.f != nil {
+		err = f.f.Close()
+		f.f = nil
+	}
+	f.mu.Unlock()
+	f.wg.Wait()
+	return err
+}
+
+// backupName возвращает свободное имя старого файла с отметкой времени t,
+// в том числе с учетом еще не сжатых и уже сжатых файлов.
+func (f *File) backupName(t time.Time) string {
+	base := f.path + "." + t.Format(backupTimeFormat)
+	name := base
+	for n := 1; exists(name) || exists(name+".gz"); n++ {
+		name = base + "-" + strconv.Itoa(n)
+	}
+	return name
+}
+
+func exists(name string) bool {
+	_, err := os.Lstat(name)
+	return err == nil
+}
+
+// backups возвращает старые файлы журнала от старых к новым.
+func (f *File) backups() ([]string, error) {
+	matches, err := filepath.Glob(f.path + ".*")
+	if err != nil {
+		return nil, err
+	}
+	type backup struct {
+		name string
+		t    time.Time
+		n    int
+	}
+	var found []backup
+	for _, m := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(m, f.path+"."), ".gz")
+		n := 0
+		if i := strings.LastIndexByte(stamp, '-'); i >= 0 {
+			if n, err = strconv.Atoi(stamp[i+1:]); err != nil || n <= 0 {
+				continue
+			}
+			stamp = stamp[:i]
+		}
+		if t, err := time.Parse(backupTimeFormat, stamp); err == nil {
+			found = append(found, backup{name: m, t: t, n: n})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if !found[i].t.Equal(found[j].t) {
+			return found[i].t.Before(found[j].t)
+		}
+		return found[i].n < found[j].n
+	})
+	names := make([]string, len(found))
+	for i, b := range found {
+		names[i] = b.name
+	}
+	return names, nil
+}
+
+// removeOld удаляет старые файлы сверх MaxBackups.
+func (f *File) removeOld() {
+	if f.cfg.MaxBackups <= 0 {
+		return
+	}
+	names, err := f.backups()
+	if err != nil {
+		return
+	}
+	for len(names) > f.cfg.MaxBackups {
+		os.Remove(names[0])
+		names = names[1:]
+	}
+}
+
+// compressFile сжимает файл name в name.gz и удаляет исходный файл.
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(name + ".gz")
+		return err
+	}
+	return os.Remove(name)
+}
@@ -0,0 +1,208 @@
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testClock - управляемые часы для проверки ротации по времени.
+type testClock struct{ t time.Time }
+
+func (c *testClock) now() time.Time { return c.t }
+
+func (c *testClock) add(d time.Duration) { c.t = c.t.Add(d) }
+
+func openTestFile(t *testing.T, cfg RotateConfig) (*File, string, *testClock) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "logs", "access.log")
+	f, err := OpenFile(path, cfg)
+	if err != nil {
+		t.Fatalf("Ошибка открытия журнала: %v", err)
+	}
+	clock := &testClock{t: time.Date(2024, 11, 9, 15, 30, 0, 0, time.UTC)}
+	f.now, f.openedAt = clock.now, clock.t
+	return f, path, clock
+}
+
+func writeLine(t *testing.T, f *File, s string) {
+	t.Helper()
+	if _, err := f.Write([]byte(s + "\n")); err != nil {
+		t.Fatalf("Ошибка записи: %v", err)
+	}
+}
+
+func TestFileRotate(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         RotateConfig
+		step        time.Duration // сдвиг часов перед каждой записью
+		lines       int
+		wantBackups int
+	}{
+		{name: "Без ротации", cfg: RotateConfig{}, lines: 5, wantBackups: 0},
+		{name: "По размеру", cfg: RotateConfig{MaxSize: 20}, lines: 5, wantBackups: 4},
+		{name: "По размеру с хранением двух файлов", cfg: RotateConfig{MaxSize: 20, MaxBackups: 2}, lines: 5, wantBackups: 2},
+		{name: "По времени", cfg: RotateConfig{Interval: time.Hour}, step: 40 * time.Minute, lines: 4, wantBackups: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, path, clock := openTestFile(t, tt.cfg)
+			for i := 0; i < tt.lines; i++ {
+				clock.add(tt.step + time.Second)
+				writeLine(t, f, "запись журнала")
+			}
+			if err := f.Close(); err != nil {
+				t.Fatalf("Ошибка закрытия: %v", err)
+			}
+			backups, _ := f.backups()
+			if len(backups) != tt.wantBackups {
+				t.Errorf("Ожидается старых файлов: %d, получено %d (%v)", tt.wantBackups, len(backups), backups)
+			}
+			if _, err := os.Stat(path); err != nil {
+				t.Errorf("Текущий файл журнала отсутствует: %v", err)
+			}
+		})
+	}
+}
+
+func TestFileRotate_sameTime(t *testing.T) {
+	f, _, _ := openTestFile(t, RotateConfig{Compress: true})
+	// Часы стоят: все старые файлы получают одну отметку времени
+	for i := 0; i < 12; i++ {
+		writeLine(t, f, fmt.Sprintf("запись %d", i))
+		if err := f.Rotate(); err != nil {
+			t.Fatalf("Ошибка ротации: %v", err)
+		}
+	}
+	f.Close()
+
+	backups, _ := f.backups()
+	if len(backups) != 12 {
+		t.Fatalf("Ожидается старых файлов: 12, получено %d (%v)", len(backups), backups)
+	}
+	for i, name := range backups {
+		gz, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr, err := gzip.NewReader(gz)
+		if err != nil {
+			t.Fatalf("Ошибка чтения gzip %s: %v", name, err)
+		}
+		b, _ := io.ReadAll(zr)
+		gz.Close()
+		if want := fmt.Sprintf("запись %d\n", i); string(b) != want {
+			t.Errorf("%s: ожидается %q, получено %q", filepath.Base(name), want, b)
+		}
+	}
+}
+
+func TestFileRotate_existingFileAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	if err := os.WriteFile(path, []byte("до перезапуска\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := OpenFile(path, RotateConfig{Interval: time.Hour})
+	if err != nil {
+		t.Fatalf("Ошибка открытия журнала: %v", err)
+	}
+	writeLine(t, f, "после перезапуска")
+	f.Close()
+
+	backups, _ := f.backups()
+	if len(backups) != 1 {
+		t.Fatalf("Файл старше периода ротации должен ротироваться при первой записи, старых файлов: %v", backups)
+	}
+	if b, _ := os.ReadFile(backups[0]); string(b) != "до перезапуска\n" {
+		t.Errorf("Неверное содержимое старого файла: %q", b)
+	}
+}
+
+func TestFileCompress(t *testing.T) {
+	f, path, clock := openTestFile(t, RotateConfig{Compress: true})
+	writeLine(t, f, "первая запись")
+	clock.add(time.Second)
+	if err := f.Rotate(); err != nil {
+		t.Fatalf("Ошибка ротации: %v", err)
+	}
+	writeLine(t, f, "вторая запись")
+	f.Close()
+
+	backups, _ := f.backups()
+	if len(backups) != 1 || !strings.HasSuffix(backups[0], ".gz") {
+		t.Fatalf("Ожидается один сжатый файл, получено %v", backups)
+	}
+	gz, err := os.Open(backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gz.Close()
+	zr, err := gzip.NewReader(gz)
+	if err != nil {
+		t.Fatalf("Ошибка чтения gzip: %v", err)
+	}
+	b, _ := io.ReadAll(zr)
+	if string(b) != "первая запись\n" {
+		t.Errorf("Неверное содержимое старого файла: %q", b)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "вторая запись\n" {
+		t.Errorf("Неверное содержимое текущего файла: %q", b)
+	}
+}
+
+func TestFileReopen(t *testing.T) {
+	f, path, _ := openTestFile(t, RotateConfig{})
+	defer f.Close()
+	writeLine(t, f, "до переименования")
+
+	// Внешняя ротация: файл переименован, журнал должен открыть новый
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Reopen(); err != nil {
+		t.Fatalf("Ошибка повторного открытия: %v", err)
+	}
+	writeLine(t, f, "после переименования")
+
+	if b, _ := os.ReadFile(path); string(b) != "после переименования\n" {
+		t.Errorf("Неверное содержимое нового файла: %q", b)
+	}
+	if b, _ := os.ReadFile(path + ".1"); string(b) != "до переименования\n" {
+		t.Errorf("Неверное содержимое старого файла: %q", b)
+	}
+}
+
+func TestConfigRotate(t *testing.T) {
+	tests := []struct {
+		cfg     Config
+		want    RotateConfig
+		wantErr bool
+	}{
+		{cfg: Config{}, want: RotateConfig{}},
+		{cfg: Config{MaxSizeMB: 10, RotateEvery: "24h", MaxBackups: 7, Compress: true},
+			want: RotateConfig{MaxSize: 10 << 20, Interval: 24 * time.Hour, MaxBackups: 7, Compress: true}},
+		{cfg: Config{RotateEvery: "сутки"}, wantErr: true},
+		{cfg: Config{MaxBackups: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := tt.cfg.rotateConfig()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%+v: ошибка %v", tt.cfg, err)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("%+v: ожидается %+v, получено %+v", tt.cfg, tt.want, got)
+		}
+	}
+}
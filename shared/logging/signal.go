@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// signalFile - файл журнала, который открывается заново по сигналу SIGHUP.
+type signalFile struct {
+	*File
+	sig  chan os.Signal
+	done chan struct{}
+}
+
+// reopenOnSignal открывает файл f заново при каждом сигнале SIGHUP,
+// пока файл не будет закрыт.
+func reopenOnSignal(f *File) *signalFile {
+	s := &signalFile{File: f, sig: make(chan os.Signal, 1), done: make(chan struct{})}
+	signal.Notify(s.sig, syscall.SIGHUP)
+	go func() {
+		defer close(s.done)
+		for range s.sig {
+			if err := f.Reopen(); err != nil {
+				fmt.Fprintf(os.Stderr, "Ошибка повторного открытия журнала: %v\n", err)
+			}
+		}
+	}()
+	return s
+}
+
+// Close прекращает обработку сигналов и закрывает файл.
+func (s *signalFile) Close() error {
+	signal.Stop(s.sig)
+	close(s.sig)
+	<-s.done
+	return s.File.Close()
+}
@@ -0,0 +1,110 @@
+// Package metrics собирает метрики сервиса в формате Prometheus.
+// Каждый сервис создает собственный реестр, поэтому метрики можно проверять
+// в тестах чтением реестра или запросом к /metrics без запущенного Prometheus.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Path - путь, по которому сервисы отдают метрики.
+const Path = "/metrics"
+
+// UnknownRoute - значение метки route для запросов без найденного маршрута.
+// Путь запроса в метку не попадает, чтобы число рядов не росло от случайных адресов.
+const UnknownRoute = "unknown"
+
+// Метки метрик запросов
+const (
+	LabelMethod = "method"
+	LabelRoute  = "route"
+	LabelStatus = "status"
+)
+
+// Metrics - реестр метрик сервиса с метриками входящих запросов.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+}
+
+// New создает реестр с метриками входящих запросов, среды выполнения Go и процесса.
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Число обработанных запросов по маршруту и статусу ответа.",
+		}, []string{LabelMethod, LabelRoute, LabelStatus}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Длительность обработки запросов по маршруту и статусу ответа.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{LabelMethod, LabelRoute, LabelStatus}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "Число запросов, обрабатываемых в данный момент.",
+		}),
+	}
+	m.Registry.MustRegister(
+		m.requests, m.duration, m.inFlight,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// MustRegister регистрирует метрики сервиса в реестре.
+func (m *Metrics) MustRegister(cs ...prometheus.Collector) {
+	m.Registry.MustRegister(cs...)
+}
+
+// Handler возвращает обработчик, отдающий метрики реестра.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{Registry: m.Registry})
+}
+
+// Observe учитывает обработанный запрос. Используется и для вызовов gRPC,
+// тогда method - "gRPC", route - полное имя метода, status - код gRPC.
+func (m *Metrics) Observe(method, route, status string, d time.Duration) {
+	m.requests.WithLabelValues(method, route, status).Inc()
+	m.duration.WithLabelValues(method, route, status).Observe(d.Seconds())
+}
+
+// InFlight возвращает счетчик запросов, обрабатываемых в данный момент.
+func (m *Metrics) InFlight() prometheus.Gauge {
+	return m.inFlight
+}
+
+// Middleware учитывает запросы к маршрутизатору chi. Метка route - шаблон
+// маршрута, например /news/{id}, а не путь запроса.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		m.inFlight.Inc()
+		defer m.inFlight.Dec()
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := UnknownRoute
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		m.Observe(r.Method, route, strconv.Itoa(status), time.Since(start))
+	})
+}
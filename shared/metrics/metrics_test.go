@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMiddleware(t *testing.T) {
+	m := New()
+	r := chi.NewRouter()
+	r.Use(m.Middleware)
+	r.Get("/news/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if got := testutil.ToFloat64(m.InFlight()); got != 1 {
+			t.Errorf("Запросов в обработке: ожидается 1, получено %v", got)
+		}
+		if chi.URLParam(r, "id") == "0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("{}"))
+	})
+	r.Method(http.MethodGet, Path, m.Handler())
+
+	for _, path := range []string{"/news/1", "/news/2", "/news/0", "/nope"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	tests := []struct {
+		route  string
+		status string
+		want   float64
+	}{
+		{route: "/news/{id}", status: "200", want: 2},
+		{route: "/news/{id}", status: "404", want: 1},
+		{route: UnknownRoute, status: "404", want: 1},
+	}
+	for _, tt := range tests {
+		got := testutil.ToFloat64(m.requests.WithLabelValues(http.MethodGet, tt.route, tt.status))
+		if got != tt.want {
+			t.Errorf("Запросов %s со статусом %s: ожидается %v, получено %v", tt.route, tt.status, tt.want, got)
+		}
+	}
+	if got := testutil.ToFloat64(m.InFlight()); got != 0 {
+		t.Errorf("Запросов в обработке: ожидается 0, получено %v", got)
+	}
+
+	// Ответ /metrics в текстовом формате Prometheus
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	body, _ := io.ReadAll(rec.Body)
+	for _, want := range []string{
+		`http_requests_total{method="GET",route="/news/{id}",status="200"} 2`,
+		`http_request_duration_seconds_count{method="GET",route="/news/{id}",status="404"} 1`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("В ответе %s нет %s", Path, want)
+		}
+	}
+}
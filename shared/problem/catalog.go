@@ -0,0 +1,200 @@
+package problem
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Поддерживаемые языки сообщений
+const (
+	LangRU = "ru"
+	LangEN = "en"
+)
+
+// DefaultLang - язык сообщений, если клиент не указал поддерживаемый язык.
+const DefaultLang = LangRU
+
+// catalogs - каталоги сообщений по языкам. Ключ заголовка (title) - код ошибки,
+// ключ описания (detail) - код ошибки и имя сообщения через точку.
+// Аргументы описаний подставляются по правилам fmt.
+var catalogs = map[string]map[string]string{
+	LangRU: {
+		CodeInvalidRequest:      "Неверный формат запроса",
+		CodeInvalidParameter:    "Неверный параметр запроса",
+		CodeNotFound:            "Ресурс не найден",
+		CodeMethodNotAllowed:    "Метод не поддерживается",
+		CodeConflict:            "Конфликт состояния",
+		CodeDuplicateComment:    "Повторный комментарий",
+		CodeCommentRejected:     "Комментарий отклонен",
+		CodeUnknownPolicy:       "Неизвестная политика цензуры",
+		CodeTooManyItems:        "Слишком много элементов",
+		CodeInternal:            "Внутренняя ошибка",
+		CodeUpstreamUnavailable: "Сервис недоступен",
+		CodeUpstreamError:       "Ошибка сервиса",
+
+		"invalid_request.item":          "Неверный формат элемента",
+		"invalid_request.line_too_long": "Строка %d длиннее %d байт, чтение запроса прервано",
+		"invalid_request.body":          "Ошибка чтения запроса после строки %d",
+
+		"invalid_parameter.required": "Параметр %s обязателен",
+		"invalid_parameter.format":   "Неверный формат %s",
+		"invalid_parameter.value":    "Неверное значение параметра %s",
+		"invalid_parameter.min":      "Неверное значение параметра %s: ожидается число от %d",
+		"invalid_parameter.range":    "Неверное значение параметра %s: ожидается число от %d до %d",
+		"invalid_parameter.text":     "Текст комментария не может быть пустым",
+
+		"not_found.news":             "Новость не найдена",
+		"not_found.job":              "Задание не найдено",
+		"not_found.audit":            "Журнал решений отключен",
+		"not_found.verdicts":         "Решения для запроса не найдены",
+		"not_found.verdicts_expired": "Решения не найдены: поиск охватывает решения с %s, более ранние есть только в файлах журнала",
+
+		"conflict.job_not_running": "Задание не выполняется",
+		"conflict.job_active":      "Задание уже выполняется",
+		"conflict.job_finished":    "Задание уже завершено",
+
+		"duplicate_comment.published": "Такой комментарий уже был опубликован",
+		"comment_rejected.words":      "Комментарий содержит недопустимые слова",
+		"unknown_policy.name":         "Неизвестная политика цензуры %q",
+		"too_many_items.max":          "Слишком много элементов: максимум %d",
+
+		"internal_error.news":              "Не удалось получить новости",
+		"internal_error.post":              "Не удалось получить новость",
+		"internal_error.add_comment":       "Не удалось добавить комментарий",
+		"internal_error.comments":          "Не удалось получить комментарии",
+		"internal_error.search":            "Не удалось выполнить поиск комментариев",
+		"internal_error.start_job":         "Не удалось запустить задание модерации",
+		"internal_error.job":               "Не удалось получить задание",
+		"internal_error.changes":           "Не удалось получить изменения",
+		"internal_error.resume_job":        "Не удалось продолжить задание",
+		"upstream_unavailable.news":        "Сервис новостей недоступен",
+		"upstream_unavailable.comments":    "Сервис комментариев недоступен",
+		"upstream_unavailable.censor":      "Сервис цензуры недоступен",
+		"upstream_error.news":              "Сервис новостей не смог обработать запрос",
+		"upstream_error.comments":          "Сервис комментариев не смог обработать запрос",
+		"upstream_error.news_response":     "Сервис новостей вернул некорректный ответ",
+		"upstream_error.comments_response": "Сервис комментариев вернул некорректный ответ",
+	},
+	LangEN: {
+		CodeInvalidRequest:      "Malformed request",
+		CodeInvalidParameter:    "Invalid request parameter",
+		CodeNotFound:            "Resource not found",
+		CodeMethodNotAllowed:    "Method not allowed",
+		CodeConflict:            "State conflict",
+		CodeDuplicateComment:    "Duplicate comment",
+		CodeCommentRejected:     "Comment rejected",
+		CodeUnknownPolicy:       "Unknown censorship policy",
+		CodeTooManyItems:        "Too many items",
+		CodeInternal:            "Internal error",
+		CodeUpstreamUnavailable: "Service unavailable",
+		CodeUpstreamError:       "Service error",
+
+		"invalid_request.item":          "Malformed item",
+		"invalid_request.line_too_long": "Line %d is longer than %d bytes, reading the request stopped",
+		"invalid_request.body":          "Failed to read the request after line %d",
+
+		"invalid_parameter.required": "Parameter %s is required",
+		"invalid_parameter.format":   "Invalid %s format",
+		"invalid_parameter.value":    "Invalid value of parameter %s",
+		"invalid_parameter.min":      "Invalid value of parameter %s: expected a number from %d",
+		"invalid_parameter.range":    "Invalid value of parameter %s: expected a number from %d to %d",
+		"invalid_parameter.text":     "Comment text must not be empty",
+
+		"not_found.news":             "News item not found",
+		"not_found.job":              "Job not found",
+		"not_found.audit":            "Verdict log is disabled",
+		"not_found.verdicts":         "No verdicts found for the request",
+		"not_found.verdicts_expired": "No verdicts found: search covers verdicts since %s, older ones are only in the log files",
+
+		"conflict.job_not_running": "Job is not running",
+		"conflict.job_active":      "Job is already running",
+		"conflict.job_finished":    "Job is already finished",
+
+		"duplicate_comment.published": "This comment has already been published",
+		"comment_rejected.words":      "The comment contains prohibited words",
+		"unknown_policy.name":         "Unknown censorship policy %q",
+		"too_many_items.max":          "Too many items: at most %d",
+
+		"internal_error.news":              "Failed to get news",
+		"internal_error.post":              "Failed to get the news item",
+		"internal_error.add_comment":       "Failed to add the comment",
+		"internal_error.comments":          "Failed to get comments",
+		"internal_error.search":            "Failed to search comments",
+		"internal_error.start_job":         "Failed to start the moderation job",
+		"internal_error.job":               "Failed to get the job",
+		"internal_error.changes":           "Failed to get changes",
+		"internal_error.resume_job":        "Failed to resume the job",
+		"upstream_unavailable.news":        "News service is unavailable",
+		"upstream_unavailable.comments":    "Comments service is unavailable",
+		"upstream_unavailable.censor":      "Censorship service is unavailable",
+		"upstream_error.news":              "News service failed to process the request",
+		"upstream_error.comments":          "Comments service failed to process the request",
+		"upstream_error.news_response":     "News service returned an invalid response",
+		"upstream_error.comments_response": "Comments service returned an invalid response",
+	},
+}
+
+// Title возвращает краткое описание кода ошибки на языке lang.
+func Title(lang, code string) string {
+	if _, ok := catalogs[DefaultLang][code]; !ok {
+		code = CodeInternal
+	}
+	return Message(lang, code)
+}
+
+// Message возвращает сообщение key на языке lang с подставленными аргументами.
+// Если в каталоге языка сообщения нет, используется язык по умолчанию,
+// если нет и там - сам ключ.
+func Message(lang, key string, args ...interface{}) string {
+	text, ok := catalogs[lang][key]
+	if !ok {
+		if text, ok = catalogs[DefaultLang][key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// Lang выбирает язык сообщений по заголовку Accept-Language запроса.
+func Lang(r *http.Request) string {
+	return ParseLang(r.Header.Get("Accept-Language"))
+}
+
+// ParseLang выбирает язык сообщений по значению Accept-Language: поддерживаемый
+// язык с наибольшим весом q, при равных весах - первый в списке.
+// Региональные варианты (en-US) сводятся к основному языку.
+func ParseLang(header string) string {
+	type option struct {
+		lang string
+		q    float64
+	}
+	var options []option
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if _, ok := catalogs[lang]; !ok {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			options = append(options, option{lang, q})
+		}
+	}
+	if len(options) == 0 {
+		return DefaultLang
+	}
+	sort.SliceStable(options, func(i, j int) bool { return options[i].q > options[j].q })
+	return options[0].lang
+}
@@ -0,0 +1,75 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLang(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: LangRU},
+		{header: "en", want: LangEN},
+		{header: "en-US,en;q=0.9", want: LangEN},
+		{header: "de-DE,de;q=0.9,en;q=0.8,ru;q=0.7", want: LangEN},
+		{header: "ru;q=0.5,en;q=0.9", want: LangEN},
+		{header: "en;q=0,ru", want: LangRU},
+		{header: "fr, *;q=0.5", want: LangRU},
+		{header: "EN-gb", want: LangEN},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Language", tt.header)
+		if got := Lang(r); got != tt.want {
+			t.Errorf("%q: ожидается язык %s, получено %s", tt.header, tt.want, got)
+		}
+	}
+}
+
+// Каталоги всех языков должны содержать одинаковый набор сообщений.
+func TestCatalogsComplete(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for key := range catalogs[DefaultLang] {
+			if _, ok := catalog[key]; !ok {
+				t.Errorf("В каталоге %s нет сообщения %s", lang, key)
+			}
+		}
+		for key := range catalog {
+			if _, ok := catalogs[DefaultLang][key]; !ok {
+				t.Errorf("Сообщение %s каталога %s отсутствует в каталоге %s", key, lang, DefaultLang)
+			}
+		}
+	}
+}
+
+func TestWriteLocalized(t *testing.T) {
+	tests := []struct {
+		lang       string
+		wantTitle  string
+		wantDetail string
+	}{
+		{lang: "", wantTitle: "Слишком много элементов", wantDetail: "Слишком много элементов: максимум 5"},
+		{lang: "en-US", wantTitle: "Too many items", wantDetail: "Too many items: at most 5"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/check/batch", nil)
+		r.Header.Set("Accept-Language", tt.lang)
+		rr := httptest.NewRecorder()
+		Error(rr, r, http.StatusRequestEntityTooLarge, CodeTooManyItems, "max", 5)
+
+		var p Problem
+		if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+			t.Fatalf("Ошибка при распаковке JSON: %v", err)
+		}
+		if p.Title != tt.wantTitle || p.Detail != tt.wantDetail {
+			t.Errorf("%q: ожидается %q / %q, получено %q / %q", tt.lang, tt.wantTitle, tt.wantDetail, p.Title, p.Detail)
+		}
+		if got := rr.Header().Get("Content-Language"); got != Lang(r) {
+			t.Errorf("%q: неверный Content-Language %q", tt.lang, got)
+		}
+	}
+}
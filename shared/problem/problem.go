@@ -0,0 +1,142 @@
+// Package problem формирует ответы об ошибках в формате RFC 7807
+// (application/problem+json). Каждая ошибка имеет постоянный код: клиенты
+// могут полагаться на него, тексты title и detail предназначены для людей
+// и берутся из каталога сообщений на языке из заголовка Accept-Language.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ContentType - тип содержимого ответа об ошибке.
+const ContentType = "application/problem+json"
+
+// TypePrefix - префикс URI типа ошибки, к нему добавляется код.
+const TypePrefix = "urn:apigetaway:problem:"
+
+// Коды ошибок. Коды не меняются между версиями сервисов.
+const (
+	CodeInvalidRequest      = "invalid_request"      // тело запроса не удалось разобрать
+	CodeInvalidParameter    = "invalid_parameter"    // неверный или отсутствующий параметр
+	CodeNotFound            = "not_found"            // ресурс не найден
+	CodeMethodNotAllowed    = "method_not_allowed"   // метод не поддерживается ресурсом
+	CodeConflict            = "conflict"             // состояние ресурса не допускает операцию
+	CodeDuplicateComment    = "duplicate_comment"    // такой комментарий уже был опубликован
+	CodeCommentRejected     = "comment_rejected"     // комментарий отклонен цензурой, вердикт в поле verdict
+	CodeUnknownPolicy       = "unknown_policy"       // неизвестная политика цензуры
+	CodeTooManyItems        = "too_many_items"       // слишком много элементов в пакете
+	CodeInternal            = "internal_error"       // внутренняя ошибка сервиса
+	CodeUpstreamUnavailable = "upstream_unavailable" // сервис, от которого зависит запрос, недоступен
+	CodeUpstreamError       = "upstream_error"       // сервис, от которого зависит запрос, вернул ошибку
+)
+
+// Problem - описание ошибки по RFC 7807.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"` // путь запроса
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+	// Extensions - дополнительные поля ответа, например verdict.
+	Extensions map[string]interface{} `json:"-"`
+
+	msg  string        // ключ описания в каталоге сообщений
+	args []interface{} // аргументы описания
+}
+
+// New создает описание ошибки с кодом code. msg - ключ описания в каталоге
+// сообщений кода code (пустой - без описания), args - аргументы описания.
+// Тексты title и detail выбираются на языке запроса при отправке.
+func New(status int, code, msg string, args ...interface{}) *Problem {
+	return &Problem{Type: TypePrefix + code, Status: status, Code: code, msg: msg, args: args}
+}
+
+// With добавляет дополнительное поле ответа.
+func (p *Problem) With(key string, value interface{}) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]interface{})
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+// problemFields - стандартные поля без методов Problem, чтобы избежать рекурсии.
+type problemFields Problem
+
+// MarshalJSON выводит дополнительные поля на одном уровне со стандартными.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal((*problemFields)(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return b, err
+	}
+	fields := make(map[string]interface{}, len(p.Extensions))
+	for k, v := range p.Extensions {
+		fields[k] = v
+	}
+	var std map[string]interface{}
+	if err := json.Unmarshal(b, &std); err != nil {
+		return nil, err
+	}
+	// Стандартные поля нельзя переопределить дополнительными
+	for k, v := range std {
+		fields[k] = v
+	}
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON разбирает стандартные поля, остальные попадают в Extensions.
+func (p *Problem) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, (*problemFields)(p)); err != nil {
+		return err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(b, &all); err != nil {
+		return err
+	}
+	for _, k := range []string{"type", "title", "status", "detail", "instance", "code", "request_id"} {
+		delete(all, k)
+	}
+	p.Extensions = nil
+	for k, v := range all {
+		p.With(k, v)
+	}
+	return nil
+}
+
+// Write отправляет описание ошибки на языке запроса. Путь запроса и request_id
+// из заголовка ответа (его устанавливает RequestIDMiddleware) добавляются автоматически.
+// Описание, заданное в поле Detail напрямую, не переводится.
+func Write(w http.ResponseWriter, r *http.Request, p *Problem) {
+	lang := Lang(r)
+	p.Title = Title(lang, p.Code)
+	if p.msg != "" {
+		p.Detail = Message(lang, p.Code+"."+p.msg, p.args...)
+	}
+	p.Instance = r.URL.Path
+	if p.RequestID == "" {
+		p.RequestID = w.Header().Get("request_id")
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("Content-Language", lang)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// Error отправляет описание ошибки с кодом code, аналог http.Error.
+func Error(w http.ResponseWriter, r *http.Request, status int, code, msg string, args ...interface{}) {
+	Write(w, r, New(status, code, msg, args...))
+}
+
+// NotFound - обработчик для неизвестных путей.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	Error(w, r, http.StatusNotFound, CodeNotFound, "")
+}
+
+// MethodNotAllowed - обработчик для неподдерживаемых методов.
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	Error(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "")
+}
@@ -0,0 +1,168 @@
+// Package tracing настраивает распределенную трассировку OpenTelemetry.
+// Контекст трассировки передается между сервисами в заголовке traceparent
+// (W3C Trace Context), поэтому спаны шлюза, сервиса комментариев и сервиса
+// цензуры одного запроса собираются в одну трассу.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Экспортеры спанов
+const (
+	ExporterNone   = "none"   // спаны не экспортируются, traceparent передается дальше
+	ExporterOTLP   = "otlp"   // OTLP по HTTP, адрес задается переменными OTEL_EXPORTER_OTLP_*
+	ExporterStdout = "stdout" // спаны в stdout, для локальной отладки
+	ExporterFile   = "file"   // спаны в файл Config.Path, для локальной отладки
+)
+
+// EnvExporter - переменная окружения, заменяющая экспортер из конфигурации.
+// Кроме имен экспортеров принимается console - как stdout.
+const EnvExporter = "OTEL_TRACES_EXPORTER"
+
+// TraceFile - файл спанов экспортера file по умолчанию.
+const TraceFile = "trace.log"
+
+// shutdownTimeout - время на отправку накопленных спанов при остановке сервиса.
+const shutdownTimeout = 5 * time.Second
+
+// instrumentationName - имя библиотеки трассировки в спанах сервисов.
+const instrumentationName = "APIGetaway/shared/tracing"
+
+// Config - настройки трассировки.
+type Config struct {
+	Exporter string `json:"exporter"` // none, otlp, stdout или file, по умолчанию none
+	Path     string `json:"path"`     // файл экспортера file, по умолчанию trace.log
+}
+
+// exporter возвращает имя экспортера с учетом переменной окружения.
+func (c Config) exporter() (string, error) {
+	name := c.Exporter
+	if env := os.Getenv(EnvExporter); env != "" {
+		name = env
+	}
+	switch name = strings.ToLower(name); name {
+	case "":
+		return ExporterNone, nil
+	case "console":
+		return ExporterStdout, nil
+	case ExporterNone, ExporterOTLP, ExporterStdout, ExporterFile:
+		return name, nil
+	default:
+		return "", fmt.Errorf("неизвестный экспортер трассировки %q", name)
+	}
+}
+
+// Open настраивает трассировку сервиса service: устанавливает передачу
+// контекста в заголовке traceparent и, если экспортер задан, глобальный
+// поставщик спанов. Имя сервиса и атрибуты ресурса можно заменить переменными
+// OTEL_SERVICE_NAME и OTEL_RESOURCE_ATTRIBUTES, выборку спанов - переменными
+// OTEL_TRACES_SAMPLER и OTEL_TRACES_SAMPLER_ARG.
+// Трассировку нужно закрыть после остановки сервиса, чтобы отправить накопленные спаны.
+func Open(service string, cfg Config) (io.Closer, error) {
+	name, err := cfg.exporter()
+	if err != nil {
+		return nil, err
+	}
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+	if name == ExporterNone {
+		return nopCloser{}, nil
+	}
+
+	ctx := context.Background()
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(service)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка описания ресурса трассировки: %w", err)
+	}
+
+	var (
+		opt  sdktrace.TracerProviderOption
+		file *os.File
+	)
+	switch name {
+	case ExporterOTLP:
+		exp, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка создания экспортера OTLP: %w", err)
+		}
+		opt = sdktrace.WithBatcher(exp)
+	case ExporterStdout:
+		exp, _ := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		opt = sdktrace.WithSyncer(exp)
+	case ExporterFile:
+		path := cfg.Path
+		if path == "" {
+			path = TraceFile
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+		if file, err = os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644); err != nil {
+			return nil, err
+		}
+		exp, _ := stdouttrace.New(stdouttrace.WithWriter(file))
+		opt = sdktrace.WithSyncer(exp)
+	}
+
+	tp := sdktrace.NewTracerProvider(opt, sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	return &provider{tp: tp, file: file}, nil
+}
+
+// provider закрывает поставщик спанов и файл экспортера.
+type provider struct {
+	tp   *sdktrace.TracerProvider
+	file *os.File
+}
+
+// Close отправляет накопленные спаны и останавливает экспорт.
+func (p *provider) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := p.tp.Shutdown(ctx)
+	if p.file != nil {
+		if cerr := p.file.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// Tracer возвращает трассировщик сервисов из глобального поставщика спанов.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// TraceID возвращает идентификатор трассы из контекста
+// или пустую строку, если контекст не относится к трассе.
+func TraceID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
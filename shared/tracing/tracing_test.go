@@ -0,0 +1,159 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newRecorder устанавливает глобальный поставщик спанов, который запоминает
+// завершенные спаны, и восстанавливает прежний после теста.
+func newRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	prevTP, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevProp)
+	})
+	return rec
+}
+
+func TestMiddleware(t *testing.T) {
+	rec := newRecorder(t)
+
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	r.Get("/news/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if TraceID(r.Context()) != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Errorf("Обработчик получил неверную трассу %q", TraceID(r.Context()))
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/news/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Ожидается 1 спан, получено %d", len(spans))
+	}
+	s := spans[0]
+	if s.Name() != "GET /news/{id}" || s.SpanKind() != trace.SpanKindServer {
+		t.Errorf("Неверный спан: %s (%v)", s.Name(), s.SpanKind())
+	}
+	if got := s.Parent().SpanID().String(); got != "00f067aa0ba902b7" || !s.Parent().IsRemote() {
+		t.Errorf("Неверный родительский спан: %s", got)
+	}
+	if s.Status().Code != codes.Error {
+		t.Errorf("Ожидается статус ошибки спана, получено %v", s.Status().Code)
+	}
+	attrs := map[string]string{}
+	for _, kv := range s.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if attrs[string(semconv.HTTPRouteKey)] != "/news/{id}" || attrs[string(semconv.HTTPResponseStatusCodeKey)] != "502" {
+		t.Errorf("Неверные атрибуты спана: %v", attrs)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	rec := newRecorder(t)
+
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer srv.Close()
+
+	ctx, parent := Tracer().Start(context.Background(), "parent")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/comments", nil)
+	resp, err := (&http.Client{Transport: Transport(nil)}).Do(req)
+	if err != nil {
+		t.Fatalf("Ошибка запроса: %v", err)
+	}
+	resp.Body.Close()
+	parent.End()
+
+	if req.Header.Get("traceparent") != "" {
+		t.Errorf("Исходный запрос не должен изменяться")
+	}
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Ожидается 2 спана, получено %d", len(spans))
+	}
+	client := spans[0]
+	if client.SpanKind() != trace.SpanKindClient || client.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("Неверный спан клиента: %s (%v)", client.Name(), client.SpanKind())
+	}
+	want := "00-" + client.SpanContext().TraceID().String() + "-" + client.SpanContext().SpanID().String() + "-01"
+	if traceparent != want {
+		t.Errorf("Заголовок traceparent: ожидается %s, получено %s", want, traceparent)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	prevTP, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevProp)
+	})
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		env     string
+		wantErr bool
+		file    bool // спаны записываются в файл
+	}{
+		{name: "По умолчанию без экспорта", cfg: Config{}},
+		{name: "Экспорт в файл", cfg: Config{Exporter: "file"}, file: true},
+		{name: "Переменная окружения заменяет экспортер", cfg: Config{Exporter: "file"}, env: "none"},
+		{name: "Консоль из переменной окружения", env: "console"},
+		{name: "Неизвестный экспортер", cfg: Config{Exporter: "jaeger"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvExporter, tt.env)
+			path := filepath.Join(t.TempDir(), "trace", "spans.log")
+			tt.cfg.Path = path
+
+			c, err := Open("test", tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Ошибка: ожидается %v, получено %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			_, span := Tracer().Start(context.Background(), "check")
+			span.End()
+			if err := c.Close(); err != nil {
+				t.Fatalf("Ошибка закрытия: %v", err)
+			}
+
+			b, _ := os.ReadFile(path)
+			if got := strings.Contains(string(b), `"Name":"check"`); got != tt.file {
+				t.Errorf("Спан в файле: ожидается %v, получено %v", tt.file, got)
+			}
+			if tt.file && !strings.Contains(string(b), `"Value":"test"`) {
+				t.Errorf("В спане нет имени сервиса: %s", b)
+			}
+		})
+	}
+}